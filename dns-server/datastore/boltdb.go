@@ -24,8 +24,8 @@ import (
 	"strings"
 	"time"
 
-	log "github.com/sirupsen/logrus"
 	"github.com/miekg/dns"
+	log "github.com/sirupsen/logrus"
 	bolt "go.etcd.io/bbolt"
 )
 
@@ -95,7 +95,7 @@ func (b *BoltDB) Close() error {
 	if b.db != nil {
 		err := b.db.Close()
 		if err != nil {
-			log.Errorf( "Failed to close the bolt db(%s).", b.FileName)
+			log.Errorf("Failed to close the bolt db(%s).", b.FileName)
 			return err
 		}
 	}
@@ -138,6 +138,13 @@ func (b *BoltDB) setOrCreateDBEntryGeneration(confValueBytes []byte, rr *Resourc
 }
 
 func (b *BoltDB) SetResourceRecord(zone string, rr *ResourceRecord) error {
+	// Add new entry to the db
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return b.setResourceRecordInTx(tx, zone, rr)
+	})
+}
+
+func (b *BoltDB) setResourceRecordInTx(tx *bolt.Tx, zone string, rr *ResourceRecord) error {
 	rrType, ok := rrTypeMap[rr.Type]
 	if !ok {
 		return fmt.Errorf("unsupported rrtype(%s) entry", rr.Type)
@@ -155,23 +162,20 @@ func (b *BoltDB) SetResourceRecord(zone string, rr *ResourceRecord) error {
 		return fmt.Errorf("internal error, could not parse dns config json")
 	}
 
-	// Add new entry to the db
-	return b.db.Update(func(tx *bolt.Tx) error {
-		zoneBkt, err := tx.Bucket([]byte(ZoneConfig)).CreateBucketIfNotExists([]byte(zone))
-		if err != nil {
-			return fmt.Errorf("zone(%s) retrieval failed", zone)
-		}
-		confValueBytes := zoneBkt.Get(confKeyBytes)
-		updatedConfValueBytes, err := b.setOrCreateDBEntryGeneration(confValueBytes, rr)
-		if err != nil {
-			return err
-		}
-		if err = zoneBkt.Put(confKeyBytes, updatedConfValueBytes); err != nil {
-			return fmt.Errorf("saving dns entry to data store failed")
-		}
+	zoneBkt, err := tx.Bucket([]byte(ZoneConfig)).CreateBucketIfNotExists([]byte(zone))
+	if err != nil {
+		return fmt.Errorf("zone(%s) retrieval failed", zone)
+	}
+	confValueBytes := zoneBkt.Get(confKeyBytes)
+	updatedConfValueBytes, err := b.setOrCreateDBEntryGeneration(confValueBytes, rr)
+	if err != nil {
+		return err
+	}
+	if err = zoneBkt.Put(confKeyBytes, updatedConfValueBytes); err != nil {
+		return fmt.Errorf("saving dns entry to data store failed")
+	}
 
-		return nil
-	})
+	return nil
 }
 
 func (b *BoltDB) getRRFromZoneBucket(zoneBkt *bolt.Bucket, dnsCfgKeyBytes []byte, question *dns.Question) []dns.RR {
@@ -252,43 +256,78 @@ func (b *BoltDB) GetResourceRecord(question *dns.Question) (*[]dns.RR, error) {
 }
 
 func (b *BoltDB) DelResourceRecord(host string, rrtypestr string) error {
-	// panic("implement me")
+	var found bool
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		var err error
+		found, err = b.delResourceRecordInTx(tx, host, rrtypestr)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("not found")
+	}
+	return nil
+}
+
+func (b *BoltDB) delResourceRecordInTx(tx *bolt.Tx, host string, rrtypestr string) (bool, error) {
 	var found bool
 	rrType, ok := rrTypeMap[rrtypestr]
 	if !ok {
-		return fmt.Errorf("unsupported rrtype(%s) entry", rrtypestr)
+		return false, fmt.Errorf("unsupported rrtype(%s) entry", rrtypestr)
 	}
 
 	dnsCfgKey := &DNSConfigRRKey{Host: strings.ToLower(host), RRType: rrType}
 	dnsCfgKeyBytes, err := json.Marshal(dnsCfgKey)
 	if err != nil {
-		return fmt.Errorf("failed to parse input request")
+		return false, fmt.Errorf("failed to parse input request")
 	}
 
-	err = b.db.Update(func(tx *bolt.Tx) error {
-		var zoneBkt *bolt.Bucket
-		err := tx.Bucket([]byte(ZoneConfig)).ForEach(func(zone, _ []byte) error {
-			if found {
-				return nil
-			}
-			zoneBkt = tx.Bucket([]byte(ZoneConfig)).Bucket(zone)
-			if zoneBkt == nil {
-				// Zone not available in the db
-				return fmt.Errorf("failed to read the zone entry")
-			}
-			if zoneBkt.Get(dnsCfgKeyBytes) != nil {
-				found = true
-				return zoneBkt.Delete(dnsCfgKeyBytes)
-			}
+	var zoneBkt *bolt.Bucket
+	err = tx.Bucket([]byte(ZoneConfig)).ForEach(func(zone, _ []byte) error {
+		if found {
 			return nil
-		})
-		return err
+		}
+		zoneBkt = tx.Bucket([]byte(ZoneConfig)).Bucket(zone)
+		if zoneBkt == nil {
+			// Zone not available in the db
+			return fmt.Errorf("failed to read the zone entry")
+		}
+		if zoneBkt.Get(dnsCfgKeyBytes) != nil {
+			found = true
+			return zoneBkt.Delete(dnsCfgKeyBytes)
+		}
+		return nil
 	})
 	if err != nil {
-		return fmt.Errorf("failed to delete dns entry")
+		return false, fmt.Errorf("failed to delete dns entry")
 	}
-	if !found {
-		return fmt.Errorf("not found")
-	}
-	return nil
+	return found, nil
+}
+
+func (b *BoltDB) ApplyResourceRecords(batch *RecordBatch) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		// Deleting a non existing record is not an error in batch, to allow re-applying the same batch
+		for _, rr := range batch.Delete {
+			if _, err := b.delResourceRecordInTx(tx, rr.Name, rr.Type); err != nil {
+				return err
+			}
+		}
+		for _, zr := range batch.Set {
+			zone := zr.Zone
+			if len(zone) == 0 {
+				zone = DefaultZone
+			}
+			if zr.RR == nil {
+				continue
+			}
+			for i := range *zr.RR {
+				if err := b.setResourceRecordInTx(tx, zone, &(*zr.RR)[i]); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
 }
@@ -30,6 +30,13 @@ type ZoneEntry struct {
 	RR   *[]ResourceRecord `json:"rr"`
 }
 
+// Batch of resource record modifications, deletes are applied before the sets. Only name and type are required
+// for a delete entry.
+type RecordBatch struct {
+	Set    []ZoneEntry      `json:"set"`
+	Delete []ResourceRecord `json:"delete"`
+}
+
 type DataStore interface {
 	// Initialize the DB by creating the database
 	Open() error
@@ -45,4 +52,7 @@ type DataStore interface {
 
 	// Delete A type record
 	DelResourceRecord(host string, rrtype string) error
+
+	// Apply all the record modifications in a single transaction
+	ApplyResourceRecords(batch *RecordBatch) error
 }
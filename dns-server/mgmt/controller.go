@@ -45,6 +45,7 @@ func (e *Controller) StartController(store *datastore.DataStore, ipAddr net.IP,
 	// Routes
 	e.echo.PUT("/mep/dns_server_mgmt/v1/rrecord", e.handleSetResourceRecords)
 	e.echo.DELETE("/mep/dns_server_mgmt/v1/rrecord/:fqdn/:rrtype", e.handleDeleteResourceRecord)
+	e.echo.POST("/mep/dns_server_mgmt/v1/rrecord/batch", e.handleBatchResourceRecords)
 	e.echo.GET("/health", e.handleHealthResult)
 
 	e.dataStore = *store
@@ -170,6 +171,71 @@ func (e *Controller) handleDeleteResourceRecord(c echo.Context) error {
 	return c.String(http.StatusOK, "Success")
 }
 
+func (e *Controller) handleBatchResourceRecords(c echo.Context) error {
+	// Input Example:
+	// {
+	// 	"set": [
+	// 	{
+	// 		"zone": ".",
+	// 		"rr": [
+	// 		{
+	// 			"name": "www.example.com.",
+	// 			"type": "A",
+	// 			"class": "IN",
+	// 			"ttl": 30,
+	// 			"rData": [
+	// 				"172.168.15.101"
+	// 			]
+	// 		}
+	// 		]
+	// 	}
+	// 	],
+	// 	"delete": [
+	// 	{
+	// 		"name": "www.example1.com.",
+	// 		"type": "A"
+	// 	}
+	// 	]
+	// }
+
+	batch := new(datastore.RecordBatch)
+	if err := c.Bind(batch); err != nil {
+		log.Error("Error in parsing the rr batch request body.", nil)
+		return c.String(http.StatusBadRequest, "invalid input!")
+	}
+	if err := e.validateBatchInput(batch); err != nil {
+		log.Error("Error in validating the rr batch request body.", err)
+		return c.String(http.StatusBadRequest, "invalid input!")
+	}
+
+	// All the modifications are applied in a single transaction, either all or none gets updated
+	if err := e.dataStore.ApplyResourceRecords(batch); err != nil {
+		log.Error("Failed to apply the rr batch.", nil)
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	log.Debugf("Resource record batch applied(set zones: %d, delete records: %d).", len(batch.Set),
+		len(batch.Delete))
+
+	return c.String(http.StatusOK, "success in applying rr batch.")
+}
+
+func (e *Controller) validateBatchInput(batch *datastore.RecordBatch) error {
+	for _, zr := range batch.Set {
+		if zr.RR == nil {
+			return fmt.Errorf("missing resource records")
+		}
+	}
+	if err := e.validateSetRecordInput(&batch.Set); err != nil {
+		return err
+	}
+	for _, rr := range batch.Delete {
+		if len(rr.Name) == 0 || len(rr.Name) > util.MaxDnsFQDNLength || len(rr.Type) == 0 {
+			return fmt.Errorf("invalid resource record value")
+		}
+	}
+	return nil
+}
+
 func (e *Controller) handleHealthResult(c echo.Context) error {
 	return c.String(http.StatusOK, "OK")
 }
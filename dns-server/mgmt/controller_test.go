@@ -369,6 +369,64 @@ func TestRestControllerOperations(t *testing.T) {
 
 	})
 
+	t.Run("BatchSetAndDeleteRecords", func(t *testing.T) {
+		err := store.SetResourceRecord(".", &datastore.ResourceRecord{Name: eg1, Type: "A", Class: "IN",
+			TTL: 30, RData: []string{"172.168.15.49"}})
+		assert.Equal(t, nil, err, "Error")
+
+		batchEntry := "{\"set\":" + egE1 + "\"ttl\":30,\"rData\":[\"172.168.15.100\"]}]}]," +
+			"\"delete\":[{\"name\":\"www.example1.com.\",\"type\":\"A\"}]}"
+		e := echo.New()
+		newRequest, err := http.NewRequest(http.MethodPost, url+"/batch", strings.NewReader(batchEntry))
+		assert.Equal(t, nil, err, "Error")
+		newRequest.Header.Set(cont, appj)
+		recorder := httptest.NewRecorder()
+		c := e.NewContext(newRequest, recorder)
+		err = mgmtCtl.handleBatchResourceRecords(c)
+		assert.Equal(t, nil, err, "Error")
+		assert.Equal(t, http.StatusOK, c.Response().Status, "Error")
+
+		rrResponse, _ := store.GetResourceRecord(&dns.Question{Name: eg,
+			Qtype: dns.TypeA, Qclass: dns.ClassINET})
+		assert.Equal(t, eg172, (*rrResponse)[0].String(), "Error")
+		err = store.DelResourceRecord(eg1, "A")
+		assert.NotEqual(t, nil, err, errRecord)
+		err = store.DelResourceRecord(eg, "A")
+		assert.Equal(t, nil, err, errRecord)
+	})
+
+	t.Run("BatchFailureIsAtomic", func(t *testing.T) {
+		// Second record has an unsupported type, hence the first one must not be stored either
+		batchEntry := "{\"set\":[{\"zone\":\".\",\"rr\":[{\"name\":\"www.example.com.\",\"type\":\"A\"," +
+			"\"class\":\"IN\",\"ttl\":30,\"rData\":[\"172.168.15.100\"]},{\"name\":\"www.example1.com.\"," +
+			"\"type\":\"MX\",\"class\":\"IN\",\"ttl\":30,\"rData\":[\"172.168.15.49\"]}]}]}"
+		e := echo.New()
+		newRequest, err := http.NewRequest(http.MethodPost, url+"/batch", strings.NewReader(batchEntry))
+		assert.Equal(t, nil, err, "Error")
+		newRequest.Header.Set(cont, appj)
+		recorder := httptest.NewRecorder()
+		c := e.NewContext(newRequest, recorder)
+		err = mgmtCtl.handleBatchResourceRecords(c)
+		assert.Equal(t, nil, err, "Error")
+		assert.Equal(t, http.StatusInternalServerError, c.Response().Status, "Error")
+
+		err = store.DelResourceRecord(eg, "A")
+		assert.NotEqual(t, nil, err, errRecord)
+	})
+
+	t.Run("BatchInvalidDeleteInput", func(t *testing.T) {
+		batchEntry := "{\"delete\":[{\"name\":\"\",\"type\":\"A\"}]}"
+		e := echo.New()
+		newRequest, err := http.NewRequest(http.MethodPost, url+"/batch", strings.NewReader(batchEntry))
+		assert.Equal(t, nil, err, "Error")
+		newRequest.Header.Set(cont, appj)
+		recorder := httptest.NewRecorder()
+		c := e.NewContext(newRequest, recorder)
+		err = mgmtCtl.handleBatchResourceRecords(c)
+		assert.Equal(t, nil, err, "Error")
+		assert.Equal(t, http.StatusBadRequest, c.Response().Status, "Error")
+	})
+
 	_ = os.RemoveAll(datastore.DBPath)
 
 }
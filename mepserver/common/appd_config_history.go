@@ -0,0 +1,90 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/apache/servicecomb-service-center/pkg/log"
+	"mepserver/common/extif/backend"
+	"mepserver/common/models"
+	"mepserver/common/util"
+	"sort"
+	"time"
+)
+
+// Version is zero padded in the key, so that the prefix based data-store operations match a single version
+func appDConfigVersionKey(appInstanceId string, version int) string {
+	return fmt.Sprintf("%s%s/%010d", util.AppDConfigHistoryPath, appInstanceId, version)
+}
+
+// Get the applied AppD configuration versions of the app instance in the ascending version order
+func GetAppDConfigVersions(appInstanceId string) ([]models.AppDConfigVersion, int) {
+	records, errCode := backend.GetRecordsWithCompleteKeyPath(util.AppDConfigHistoryPath + appInstanceId + "/")
+	if errCode != 0 {
+		log.Errorf(nil, "retrieve appd config history from data-store failed")
+		return nil, errCode
+	}
+
+	versions := make([]models.AppDConfigVersion, 0, len(records))
+	for key, record := range records {
+		version := models.AppDConfigVersion{}
+		if err := json.Unmarshal(record, &version); err != nil {
+			log.Warnf("failed to parse the appd config version(%s) from data-store", key)
+			continue
+		}
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+	return versions, 0
+}
+
+// Add the applied AppD configuration as the latest version and drop the oldest versions above the limit. Task id is
+// empty for the rule changes over mp1.
+func SaveAppDConfigVersion(appInstanceId, taskId string, appDConfig *models.AppDConfig) {
+	versions, errCode := GetAppDConfigVersions(appInstanceId)
+	if errCode != 0 {
+		return
+	}
+
+	version := models.AppDConfigVersion{Version: 1, TaskId: taskId, CreateTime: time.Now().Unix(),
+		AppDConfig: *appDConfig}
+	if len(versions) != 0 {
+		version.Version = versions[len(versions)-1].Version + 1
+	}
+	versionBytes, err := json.Marshal(&version)
+	if err != nil {
+		log.Errorf(nil, "can not marshal appd config version")
+		return
+	}
+	if errCode = backend.PutRecord(appDConfigVersionKey(appInstanceId, version.Version), versionBytes); errCode != 0 {
+		log.Errorf(nil, "appd config version(app-id: %s, version: %d) write failed", appInstanceId,
+			version.Version)
+		return
+	}
+
+	expiredPaths := make([]string, 0)
+	for i := 0; i < len(versions)+1-util.MaxAppDConfigVersions; i++ {
+		expiredPaths = append(expiredPaths, appDConfigVersionKey(appInstanceId, versions[i].Version))
+	}
+	_ = backend.DeletePaths(expiredPaths, true)
+}
+
+// Remove the version history of a deleted app instance
+func DeleteAppDConfigVersions(appInstanceId string) {
+	_ = backend.DeletePaths([]string{util.AppDConfigHistoryPath + appInstanceId + "/"}, true)
+}
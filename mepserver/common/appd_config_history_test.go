@@ -0,0 +1,79 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/stretchr/testify/assert"
+	"mepserver/common/extif/backend"
+	"mepserver/common/models"
+	"mepserver/common/util"
+	"testing"
+)
+
+const defaultAppInstanceId = "5abe4782-2c70-4e47-9a4e-0ee3a1a0fd1f"
+
+var historyStore map[string][]byte
+
+// Records of the in-memory data-store under the path, keyed by the complete path
+func getHistoryRecords(path string) (map[string][]byte, int) {
+	records := make(map[string][]byte)
+	for key, value := range historyStore {
+		if strings.HasPrefix(key, path) {
+			records[key] = value
+		}
+	}
+	return records, 0
+}
+
+func TestSaveAppDConfigVersion(t *testing.T) {
+	historyStore = make(map[string][]byte)
+	for version := 1; version <= util.MaxAppDConfigVersions; version++ {
+		historyStore[appDConfigVersionKey(defaultAppInstanceId, version)], _ = json.Marshal(&models.AppDConfigVersion{
+			Version: version, AppDConfig: models.AppDConfig{AppName: "App"}})
+	}
+
+	patch1 := gomonkey.ApplyFunc(backend.GetRecordsWithCompleteKeyPath, getHistoryRecords)
+	patch2 := gomonkey.ApplyFunc(backend.PutRecord, func(path string, value []byte) int {
+		historyStore[path] = value
+		return 0
+	})
+	patch3 := gomonkey.ApplyFunc(backend.DeletePaths, func(paths []string, continueOnFailure bool) int {
+		for _, path := range paths {
+			delete(historyStore, path)
+		}
+		return 0
+	})
+	defer patch1.Reset()
+	defer patch2.Reset()
+	defer patch3.Reset()
+
+	SaveAppDConfigVersion(defaultAppInstanceId, "newTask", &models.AppDConfig{AppName: "App",
+		AppSupportMp1: true})
+
+	versions, errCode := GetAppDConfigVersions(defaultAppInstanceId)
+	assert.Equal(t, 0, errCode)
+	assert.Equal(t, util.MaxAppDConfigVersions, len(versions))
+	assert.Equal(t, 2, versions[0].Version, "oldest version must be dropped")
+	latest := versions[len(versions)-1]
+	assert.Equal(t, util.MaxAppDConfigVersions+1, latest.Version)
+	assert.Equal(t, "newTask", latest.TaskId)
+	assert.True(t, latest.AppDConfig.AppSupportMp1)
+}
@@ -17,6 +17,7 @@
 package config
 
 import (
+	"fmt"
 	"github.com/apache/servicecomb-service-center/pkg/log"
 	"github.com/ghodss/yaml"
 	"github.com/go-playground/validator/v10"
@@ -26,8 +27,13 @@ import (
 )
 
 type MepServerConfig struct {
-	DNSAgent  DNSAgent  `yaml:"dnsAgent"`
-	DataPlane DataPlane `yaml:"dataplane"`
+	DNSAgent       DNSAgent       `yaml:"dnsAgent"`
+	DataPlane      DataPlane      `yaml:"dataplane"`
+	TaskRetention  TaskRetention  `yaml:"taskRetention"`
+	AppTermination AppTermination `yaml:"appTermination"`
+	WorkerPool     WorkerPool     `yaml:"workerPool"`
+	Transports     []Transport    `yaml:"transports" validate:"omitempty,dive"`
+	Timing         Timing         `yaml:"timing"`
 }
 type Address struct {
 	Host string `yaml:"host" validate:"omitempty,min=1,max=253"`
@@ -37,8 +43,87 @@ type EndPoint struct {
 	Address Address `yaml:"address"`
 }
 type DNSAgent struct {
-	Type     string   `yaml:"type" validate:"oneof=local dataplane all"`
+	Type     string   `yaml:"type" validate:"oneof=local dataplane all rfc2136"`
 	Endpoint EndPoint `yaml:"endPoint" validate:"required_unless=type dataplane"`
+	// Validated only when the rfc2136 agent is selected
+	RFC2136 RFC2136 `yaml:"rfc2136" validate:"-"`
+}
+
+// RFC 2136 dynamic update settings, server address is taken from the end point
+type RFC2136 struct {
+	Zone          string `yaml:"zone" validate:"required,max=253"`
+	Transport     string `yaml:"transport" validate:"omitempty,oneof=udp tcp"`
+	Timeout       int    `yaml:"timeout" validate:"omitempty,min=1,max=60"`
+	TsigKeyName   string `yaml:"tsigKeyName" validate:"required_with=TsigSecret,omitempty,max=253"`
+	TsigSecret    string `yaml:"tsigSecret" validate:"required_with=TsigKeyName,omitempty,base64"`
+	TsigAlgorithm string `yaml:"tsigAlgorithm" validate:"omitempty,oneof=hmac-sha1 hmac-sha256 hmac-sha512"`
+}
+
+// Retention of the finished appd configuration task status, purge is disabled if the period is not set
+type TaskRetention struct {
+	Period   int `yaml:"period" validate:"omitempty,min=1,max=8760"`   // in hours
+	Interval int `yaml:"interval" validate:"omitempty,min=1,max=1440"` // in minutes
+}
+
+// App termination settings, default graceful timeout is used if it is not set
+type AppTermination struct {
+	GracefulTimeout int `yaml:"gracefulTimeout" validate:"omitempty,min=1,max=600"` // in seconds
+}
+
+// Workers processing the appd configuration tasks, default pool size is used if it is not set
+type WorkerPool struct {
+	Size int `yaml:"size" validate:"omitempty,min=1,max=256"`
+}
+
+// Transport offered by the platform to the services, the registered transport id must be one of these. The default
+// transport is offered when none is set.
+type Transport struct {
+	ID          string            `yaml:"id" validate:"required,max=64"`
+	Name        string            `yaml:"name" validate:"required,max=128"`
+	Description string            `yaml:"description" validate:"omitempty,max=128"`
+	Type        string            `yaml:"type" validate:"required,oneof=REST_HTTP MB_TOPIC_BASED MB_ROUTING MB_PUBSUB RPC RPC_STREAMING WEBSOCKET"`
+	Protocol    string            `yaml:"protocol" validate:"required,max=32"`
+	Version     string            `yaml:"version" validate:"required,max=32"`
+	Security    TransportSecurity `yaml:"security"`
+}
+
+type TransportSecurity struct {
+	GrantTypes    []string `yaml:"grantTypes" validate:"omitempty,max=4,dive,oneof=OAUTH2_AUTHORIZATION_CODE OAUTH2_IMPLICIT_GRANT OAUTH2_RESOURCE_OWNER OAUTH2_CLIENT_CREDENTIALS"`
+	TokenEndpoint string   `yaml:"tokenEndpoint" validate:"omitempty,uri"`
+}
+
+// Platform clock reported by the timing api, the clock is non traceable if no time source is set
+type Timing struct {
+	Source       string       `yaml:"source" validate:"omitempty,oneof=ntp ptp"`
+	Status       TimingStatus `yaml:"status"`
+	MaxOffset    int          `yaml:"maxOffset" validate:"omitempty,min=1,max=1000000"` // in microseconds
+	PollInterval int          `yaml:"pollInterval" validate:"omitempty,min=1,max=3600"` // in seconds
+	NtpServers   []NtpServer  `yaml:"ntpServers" validate:"omitempty,dive"`
+	PtpMasters   []PtpMaster  `yaml:"ptpMasters" validate:"omitempty,dive"`
+}
+
+// Local source of the clock status, the command output is read if no file is set
+type TimingStatus struct {
+	File    string   `yaml:"file" validate:"omitempty,max=256"`
+	Command []string `yaml:"command" validate:"omitempty,max=16"`
+}
+
+// NTP server of the platform clock, the polling intervals are in seconds as a power of two
+type NtpServer struct {
+	AddrType             string `yaml:"addrType" validate:"required,oneof=IP_ADDRESS DNS_NAME"`
+	Addr                 string `yaml:"addr" validate:"required,max=253"`
+	MinPollingInterval   int    `yaml:"minPollingInterval" validate:"omitempty,min=3,max=17"`
+	MaxPollingInterval   int    `yaml:"maxPollingInterval" validate:"omitempty,min=3,max=17"`
+	LocalPriority        int    `yaml:"localPriority" validate:"omitempty,min=1,max=256"`
+	AuthenticationOption string `yaml:"authenticationOption" validate:"omitempty,oneof=NONE SYMMETRIC_KEY NTS"`
+	AuthenticationKeyNum int    `yaml:"authenticationKeyNum" validate:"omitempty,min=1"`
+}
+
+// PTP master of the platform clock
+type PtpMaster struct {
+	IpAddress       string `yaml:"ipAddress" validate:"required,ip"`
+	LocalPriority   int    `yaml:"localPriority" validate:"omitempty,min=1,max=256"`
+	DelayReqMaxRate int    `yaml:"delayReqMaxRate" validate:"omitempty,min=1"` // in packets per second
 }
 
 type DataPlane struct {
@@ -73,5 +158,34 @@ func validateConfig(config *MepServerConfig) error {
 	if err != nil {
 		return err
 	}
+	if err = validateTransports(config.Transports); err != nil {
+		return err
+	}
+	return validateDNSAgentConfig(validate, &config.DNSAgent)
+}
+
+// Transport ids are the keys of the catalogue, so they must be unique
+func validateTransports(transports []Transport) error {
+	ids := make(map[string]bool, len(transports))
+	for _, transport := range transports {
+		if ids[transport.ID] {
+			return fmt.Errorf("duplicate transport id %s", transport.ID)
+		}
+		ids[transport.ID] = true
+	}
+	return nil
+}
+
+// Validate the configurations specific to the selected dns agent
+func validateDNSAgentConfig(validate *validator.Validate, dnsAgent *DNSAgent) error {
+	switch dnsAgent.Type {
+	case util.DnsAgentTypeLocal, util.DnsAgentTypeAll:
+		return validate.Struct(&dnsAgent.Endpoint)
+	case util.DnsAgentTypeRFC2136:
+		if len(dnsAgent.Endpoint.Address.Host) == 0 {
+			return fmt.Errorf("dns server host is mandatory for rfc2136 dns agent")
+		}
+		return validate.Struct(&dnsAgent.RFC2136)
+	}
 	return nil
 }
@@ -207,3 +207,181 @@ dataplane:
 	assert.EqualError(t, err, "Key: 'MepServerConfig.DNSAgent.Type' Error:Field validation for 'Type' failed on the 'oneof' tag", responseNilError)
 	assert.Equal(t, (*MepServerConfig)(nil), config)
 }
+
+func TestDnsAgentRFC2136Config(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	patch1 := gomonkey.ApplyFunc(ioutil.ReadFile, func(filename string) ([]byte, error) {
+		mepConfigYaml := `
+# dns agent configuration
+dnsAgent:
+  type: rfc2136
+  endPoint:
+    address:
+      host: 10.0.0.1
+      port: 53
+  rfc2136:
+    zone: example.com
+    tsigKeyName: mep-key
+    tsigSecret: c2VjcmV0LWtleS1mb3ItbWVw
+    tsigAlgorithm: hmac-sha256
+
+# data plane option to use in Mp2 interface
+dataplane:
+  # values: none
+  type: none
+`
+		return []byte(mepConfigYaml), nil
+	})
+	defer patch1.Reset()
+
+	config, err := LoadMepServerConfig()
+	if err != nil {
+		assert.Fail(t, err.Error())
+		return
+	}
+	assert.Equal(t, "rfc2136", config.DNSAgent.Type, responseNilError)
+	assert.Equal(t, "example.com", config.DNSAgent.RFC2136.Zone, responseNilError)
+	assert.Equal(t, "hmac-sha256", config.DNSAgent.RFC2136.TsigAlgorithm, responseNilError)
+}
+
+func TestDnsAgentRFC2136WrongConfig(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	patch1 := gomonkey.ApplyFunc(ioutil.ReadFile, func(filename string) ([]byte, error) {
+		mepConfigYaml := `
+# dns agent configuration
+dnsAgent:
+  type: rfc2136
+  endPoint:
+    address:
+      host: 10.0.0.1
+  rfc2136:
+    zone: example.com
+    tsigKeyName: mep-key
+
+# data plane option to use in Mp2 interface
+dataplane:
+  # values: none
+  type: none
+`
+		return []byte(mepConfigYaml), nil
+	})
+	defer patch1.Reset()
+
+	config, err := LoadMepServerConfig()
+	assert.EqualError(t, err, "Key: 'RFC2136.TsigSecret' Error:Field validation for 'TsigSecret' failed on the 'required_with' tag", responseNilError)
+	assert.Equal(t, (*MepServerConfig)(nil), config)
+}
+
+const transportsConfigYaml = `
+dnsAgent:
+  type: dataplane
+
+dataplane:
+  type: none
+
+transports:
+  - id: rest-http
+    name: REST
+    type: REST_HTTP
+    protocol: HTTP
+    version: "2.0"
+    security:
+      grantTypes:
+        - OAUTH2_CLIENT_CREDENTIALS
+      tokenEndpoint: https://mep.example.com/token
+`
+
+func TestTransportsConfig(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	patch1 := gomonkey.ApplyFunc(ioutil.ReadFile, func(filename string) ([]byte, error) {
+		return []byte(transportsConfigYaml), nil
+	})
+	defer patch1.Reset()
+
+	config, err := LoadMepServerConfig()
+	if err != nil {
+		assert.Fail(t, err.Error())
+		return
+	}
+	assert.Equal(t, 1, len(config.Transports), responseNilError)
+	assert.Equal(t, "rest-http", config.Transports[0].ID, responseNilError)
+	assert.Equal(t, "REST_HTTP", config.Transports[0].Type, responseNilError)
+	assert.Equal(t, []string{"OAUTH2_CLIENT_CREDENTIALS"}, config.Transports[0].Security.GrantTypes, responseNilError)
+}
+
+func TestTransportsDuplicateIdConfig(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	patch1 := gomonkey.ApplyFunc(ioutil.ReadFile, func(filename string) ([]byte, error) {
+		return []byte(transportsConfigYaml + `
+  - id: rest-http
+    name: REST2
+    type: REST_HTTP
+    protocol: HTTP
+    version: "1.1"
+`), nil
+	})
+	defer patch1.Reset()
+
+	_, err := LoadMepServerConfig()
+	assert.NotNil(t, err, "Duplicate transport id must fail")
+}
+
+func TestTimingConfig(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	patch1 := gomonkey.ApplyFunc(ioutil.ReadFile, func(filename string) ([]byte, error) {
+		mepConfigYaml := `
+dnsAgent:
+  type: dataplane
+
+dataplane:
+  type: none
+
+timing:
+  source: ptp
+  status:
+    file: /var/run/ptp/time_status
+  maxOffset: 100
+  ptpMasters:
+    - ipAddress: 192.0.2.2
+      localPriority: 1
+      delayReqMaxRate: 16
+`
+		return []byte(mepConfigYaml), nil
+	})
+	defer patch1.Reset()
+
+	config, err := LoadMepServerConfig()
+	if err != nil {
+		assert.Fail(t, err.Error())
+		return
+	}
+	assert.Equal(t, "ptp", config.Timing.Source, responseNilError)
+	assert.Equal(t, "/var/run/ptp/time_status", config.Timing.Status.File, responseNilError)
+	assert.Equal(t, 100, config.Timing.MaxOffset, responseNilError)
+	assert.Equal(t, "192.0.2.2", config.Timing.PtpMasters[0].IpAddress, responseNilError)
+}
@@ -47,6 +47,58 @@ func GetRecord(path string) (record []byte, errorCode int) {
 	return resp.Kvs[0].Value, 0
 }
 
+// Read a single record on the exact path along with its modification revision, the revision is used in the
+// conditional write
+func GetRecordWithRevision(path string) (record []byte, revision int64, errorCode int) {
+	log.Debugf("DB: Read request: %v", path)
+	opts := []registry.PluginOp{
+		registry.OpGet(registry.WithStrKey(path)),
+	}
+	resp, err := backend.Registry().TxnWithCmp(context.Background(), opts, nil, nil)
+	if err != nil {
+		log.Errorf(nil, "get single entry from data-store failed")
+		return nil, 0, meputil.OperateDataWithEtcdErr
+	}
+	if len(resp.Kvs) == 0 {
+		log.Errorf(nil, "record does not exists on given path")
+		return nil, 0, meputil.SubscriptionNotFound
+	}
+	return resp.Kvs[0].Value, resp.Kvs[0].ModRevision, 0
+}
+
+// Write the record only if it is not modified since the given revision, revision 0 writes only a new record
+func PutRecordIfRevision(path string, value []byte, revision int64) int {
+	log.Debugf("DB: Conditional write request: %v", path)
+	return putRecordWithCmps(path, value, []registry.CompareOp{
+		registry.OpCmp(registry.CmpStrModRev(path), registry.CMP_EQUAL, revision),
+	})
+}
+
+// Write the record only if it is not modified since the given revision and no record exists on the absent path
+func PutRecordIfRevisionAndAbsent(path string, value []byte, revision int64, absentPath string) int {
+	log.Debugf("DB: Conditional write request: %v, absent: %v", path, absentPath)
+	return putRecordWithCmps(path, value, []registry.CompareOp{
+		registry.OpCmp(registry.CmpStrModRev(path), registry.CMP_EQUAL, revision),
+		registry.OpCmp(registry.CmpStrCreateRev(absentPath), registry.CMP_EQUAL, 0),
+	})
+}
+
+func putRecordWithCmps(path string, value []byte, cmps []registry.CompareOp) int {
+	opts := []registry.PluginOp{
+		registry.OpPut(registry.WithStrKey(path), registry.WithValue(value)),
+	}
+	resp, err := backend.Registry().TxnWithCmp(context.Background(), opts, cmps, nil)
+	if err != nil {
+		log.Errorf(nil, "write to data-store failed")
+		return meputil.OperateDataWithEtcdErr
+	}
+	if !resp.Succeeded {
+		log.Warnf("record on %s is modified concurrently, write skipped", path)
+		return meputil.RecordModifiedErr
+	}
+	return 0
+}
+
 // Read multiple records on the given path
 func GetRecords(path string) (records map[string][]byte, errorCode int) {
 	log.Debugf("DB: Read requests: %v", path)
@@ -126,3 +178,8 @@ func DeletePaths(paths []string, continueOnFailure bool) int {
 	}
 	return 0
 }
+
+// Wait till the data store is ready to serve the requests
+func WaitForReady() {
+	<-backend.Registry().Ready()
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dataplane
+
+import (
+	"fmt"
+
+	meputil "mepserver/common/util"
+)
+
+// Traffic rule actions
+const (
+	ActionDrop                  = "DROP"
+	ActionForwardDecapsulated   = "FORWARD_DECAPSULATED"
+	ActionForwardAsIs           = "FORWARD_AS_IS"
+	ActionPassThrough           = "PASSTHROUGH"
+	ActionDuplicateDecapsulated = "DUPLICATE_DECAPSULATED"
+	ActionDuplicateAsIs         = "DUPLICATE_AS_IS"
+)
+
+// Traffic filter fields, named same as in the json input
+const (
+	FilterSrcAddress       = "srcAddress"
+	FilterDstAddress       = "dstAddress"
+	FilterSrcPort          = "srcPort"
+	FilterDstPort          = "dstPort"
+	FilterProtocol         = "protocol"
+	FilterTag              = "tag"
+	FilterSrcTunnelAddress = "srcTunnelAddress"
+	FilterTgtTunnelAddress = "tgtTunnelAddress"
+	FilterSrcTunnelPort    = "srcTunnelPort"
+	FilterDstTunnelPort    = "dstTunnelPort"
+	FilterQCI              = "qCI"
+	FilterDSCP             = "dSCP"
+	FilterTC               = "tC"
+)
+
+// Destination interface types
+const (
+	InterfaceTypeTunnel = "TUNNEL"
+	InterfaceTypeMac    = "MAC"
+	InterfaceTypeIP     = "IP"
+)
+
+// List of the filter fields which are set in the traffic filter
+func (f *TrafficFilter) usedFields() []string {
+	var fields []string
+	listFields := []struct {
+		name  string
+		value []string
+	}{
+		{FilterSrcAddress, f.SrcAddress},
+		{FilterDstAddress, f.DstAddress},
+		{FilterSrcPort, f.SrcPort},
+		{FilterDstPort, f.DstPort},
+		{FilterProtocol, f.Protocol},
+		{FilterTag, f.Tag},
+		{FilterSrcTunnelAddress, f.SrcTunnelAddress},
+		{FilterTgtTunnelAddress, f.TgtTunnelAddress},
+		{FilterSrcTunnelPort, f.SrcTunnelPort},
+		{FilterDstTunnelPort, f.DstTunnelPort},
+	}
+	for _, field := range listFields {
+		if len(field.value) != 0 {
+			fields = append(fields, field.name)
+		}
+	}
+	if f.QCI != 0 {
+		fields = append(fields, FilterQCI)
+	}
+	if f.DSCP != 0 {
+		fields = append(fields, FilterDSCP)
+	}
+	if f.TC != 0 {
+		fields = append(fields, FilterTC)
+	}
+	return fields
+}
+
+// Validate the traffic rule against the data-plane capabilities. Nil capabilities means the data-plane
+// capabilities are not known, hence no restriction is applied.
+func (c *Capabilities) ValidateTrafficRule(rule *TrafficRule) error {
+	if c == nil || rule == nil {
+		return nil
+	}
+	if !meputil.StringInList(rule.Action, c.Actions) {
+		return fmt.Errorf("action %s is not supported by the data-plane", rule.Action)
+	}
+	for _, filter := range rule.TrafficFilter {
+		for _, field := range filter.usedFields() {
+			if !meputil.StringInList(field, c.FilterFields) {
+				return fmt.Errorf("traffic filter %s is not supported by the data-plane", field)
+			}
+		}
+	}
+	for _, dstInterface := range rule.DstInterface {
+		if len(dstInterface.InterfaceType) != 0 &&
+			!meputil.StringInList(dstInterface.InterfaceType, c.InterfaceTypes) {
+			return fmt.Errorf("interface type %s is not supported by the data-plane", dstInterface.InterfaceType)
+		}
+	}
+	return nil
+}
@@ -76,10 +76,20 @@ type ApplicationInfo struct {
 	ApplicationName string
 }
 
+// Capabilities supported by the data-plane
+type Capabilities struct {
+	Actions        []string `json:"actions"`
+	FilterFields   []string `json:"filterFields"`
+	InterfaceTypes []string `json:"interfaceTypes"`
+}
+
 type DataPlane interface {
 	// Initialize the data-plane
 	InitDataPlane(config *config.MepServerConfig) (err error)
 
+	// Query the actions, filter fields and interface types supported by the data-plane
+	GetCapabilities() (capabilities *Capabilities, err error)
+
 	// Add new Traffic Rule
 	AddTrafficRule(appInfo ApplicationInfo, trafficRuleId, filterType, action string, priority int,
 		filter []TrafficFilter) (err error)
@@ -31,6 +31,19 @@ func (n *NoneDataPlane) InitDataPlane(config *config.MepServerConfig) (err error
 	return nil
 }
 
+func (n *NoneDataPlane) GetCapabilities() (capabilities *dataplane.Capabilities, err error) {
+	// None data-plane accepts every rule, so report all as supported
+	return &dataplane.Capabilities{
+		Actions: []string{dataplane.ActionDrop, dataplane.ActionForwardDecapsulated, dataplane.ActionForwardAsIs,
+			dataplane.ActionPassThrough, dataplane.ActionDuplicateDecapsulated, dataplane.ActionDuplicateAsIs},
+		FilterFields: []string{dataplane.FilterSrcAddress, dataplane.FilterDstAddress, dataplane.FilterSrcPort,
+			dataplane.FilterDstPort, dataplane.FilterProtocol, dataplane.FilterTag, dataplane.FilterSrcTunnelAddress,
+			dataplane.FilterTgtTunnelAddress, dataplane.FilterSrcTunnelPort, dataplane.FilterDstTunnelPort,
+			dataplane.FilterQCI, dataplane.FilterDSCP, dataplane.FilterTC},
+		InterfaceTypes: []string{dataplane.InterfaceTypeTunnel, dataplane.InterfaceTypeMac, dataplane.InterfaceTypeIP},
+	}, nil
+}
+
 func (n *NoneDataPlane) AddTrafficRule(appInfo dataplane.ApplicationInfo, trafficRuleId, filterType, action string, priority int,
 	filter []dataplane.TrafficFilter) (err error) {
 	log.Infof("Added traffic rule(%s) successfully to data-plane for app %v.", trafficRuleId, appInfo)
@@ -36,10 +36,32 @@ func NewRuleRecord(domainName string, ipAddressType string, ipAddress string, TT
 		State:         state}
 }
 
+// Batch of DNS entry modifications to be applied atomically, deletes are applied before the sets
+type RecordBatch struct {
+	Set    []ResourceRecord
+	Delete []ResourceRecord // Only name and type are used
+}
+
+// Add a set/add DNS entry to the batch
+func (b *RecordBatch) AddSet(host, rrtype, class string, pointTo []string, ttl uint32) {
+	b.Set = append(b.Set, ResourceRecord{Name: host, Type: rrtype, Class: class, TTL: ttl, RData: pointTo})
+}
+
+// Add a delete DNS entry to the batch
+func (b *RecordBatch) AddDelete(host, rrtype string) {
+	b.Delete = append(b.Delete, ResourceRecord{Name: host, Type: rrtype})
+}
+
+func (b *RecordBatch) IsEmpty() bool {
+	return len(b.Set) == 0 && len(b.Delete) == 0
+}
+
 // DNS agent interface
 type DNSAgent interface {
 	// Set/Add DNS entry
 	SetResourceRecordTypeA(host, rrtype, class string, pointTo []string, ttl uint32) error
 	// Delete DNS entry
 	DeleteResourceRecordTypeA(host, rrtype string) error
+	// Set/Add and delete a batch of DNS entries, either all or none gets applied
+	UpdateResourceRecords(batch *RecordBatch) error
 }
@@ -0,0 +1,47 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package path implements dns client
+package dns
+
+import (
+	"fmt"
+	"mepserver/common/config"
+
+	meputil "mepserver/common/util"
+)
+
+// Constructor of a dns agent backend
+type AgentFactory func(config *config.MepServerConfig) (DNSAgent, error)
+
+var agentFactories = make(map[string]AgentFactory)
+
+// Register a dns agent backend against the dns agent type in the configuration
+func RegisterDNSAgent(agentType string, factory AgentFactory) {
+	agentFactories[agentType] = factory
+}
+
+// Create the dns agent as per the configured type. Data-plane type doesn't use any agent, hence nil is returned.
+func CreateDNSAgent(config *config.MepServerConfig) (DNSAgent, error) {
+	if config.DNSAgent.Type == meputil.DnsAgentTypeDataPlane {
+		return nil, nil
+	}
+	factory, found := agentFactories[config.DNSAgent.Type]
+	if !found {
+		return nil, fmt.Errorf("unsupported dns agent type(%s)", config.DNSAgent.Type)
+	}
+	return factory(config)
+}
@@ -33,27 +33,46 @@ import (
 	meputil "mepserver/common/util"
 )
 
-var remoteServerHost = meputil.DefaultDnsHost
-var remoteServerPort = meputil.DefaultDnsManagementPort
-
 func init() {
-	host := os.Getenv("DNS_SERVER_HOST")
-	if len(host) > meputil.MaxFQDNLength {
+	newAgent := func(config *config.MepServerConfig) (DNSAgent, error) {
+		return NewRestDNSAgent(config), nil
+	}
+	RegisterDNSAgent(meputil.DnsAgentTypeLocal, newAgent)
+	RegisterDNSAgent(meputil.DnsAgentTypeAll, newAgent)
+}
+
+// Resolve the dns-server management end point. Environment takes precedence over the configuration file and
+// defaults are used if both are not available.
+func getRemoteServerAddress(config *config.MepServerConfig) (string, int) {
+	host := meputil.DefaultDnsHost
+	port := meputil.DefaultDnsManagementPort
+	if config != nil {
+		if len(config.DNSAgent.Endpoint.Address.Host) != 0 {
+			host = config.DNSAgent.Endpoint.Address.Host
+		}
+		if config.DNSAgent.Endpoint.Address.Port != 0 {
+			port = config.DNSAgent.Endpoint.Address.Port
+		}
+	}
+
+	envHost := os.Getenv("DNS_SERVER_HOST")
+	if len(envHost) > meputil.MaxFQDNLength {
 		log.Warn("invalid dns remote server host configured, reset back to default")
-	} else {
-		remoteServerHost = host
+	} else if len(envHost) != 0 {
+		host = envHost
 	}
 
-	port := os.Getenv("DNS_SERVER_PORT")
-	if len(port) > meputil.MaxPortLength {
+	envPort := os.Getenv("DNS_SERVER_PORT")
+	if len(envPort) > meputil.MaxPortLength {
 		log.Warn("invalid dns remote server port configured, reset back to default")
-	} else if num, err := strconv.Atoi(port); err == nil {
+	} else if num, err := strconv.Atoi(envPort); err == nil {
 		if num <= 0 || num > meputil.MaxPortNumber {
 			log.Warn("invalid dns remote server port range, reset back to default")
 		} else {
-			remoteServerPort = num
+			port = num
 		}
 	}
+	return host, port
 }
 
 type ResourceRecord struct {
@@ -69,6 +88,11 @@ type ZoneEntry struct {
 	RR   *[]ResourceRecord `json:"rr"`
 }
 
+type RecordBatchEntry struct {
+	Set    []ZoneEntry      `json:"set"`
+	Delete []ResourceRecord `json:"delete"`
+}
+
 type RestDNSAgent struct {
 	DNSAgent
 	ServerEndPoint *url.URL `json:"serverEndPoint"`
@@ -76,7 +100,8 @@ type RestDNSAgent struct {
 }
 
 func NewRestDNSAgent(config *config.MepServerConfig) *RestDNSAgent {
-	u, err := url.Parse(fmt.Sprintf("http://%s:%d/mep/dns_server_mgmt/v1/", remoteServerHost, remoteServerPort))
+	host, port := getRemoteServerAddress(config)
+	u, err := url.Parse(fmt.Sprintf("http://%s:%d/mep/dns_server_mgmt/v1/", host, port))
 	if err != nil {
 		log.Errorf(nil, "could not parse the DNS server endpoint.")
 		return &RestDNSAgent{}
@@ -158,3 +183,54 @@ func (d *RestDNSAgent) DeleteResourceRecordTypeA(host, rrtype string) error {
 	}
 	return nil
 }
+
+func (d *RestDNSAgent) UpdateResourceRecords(batch *RecordBatch) error {
+	if d.ServerEndPoint == nil {
+		log.Errorf(nil, "invalid dns remote end point")
+		return fmt.Errorf("invalid dns server endpoint")
+	}
+
+	rrSet := make([]ResourceRecord, 0, len(batch.Set))
+	for _, rr := range batch.Set {
+		rr.Name = getFqdn(rr.Name)
+		rrSet = append(rrSet, rr)
+	}
+	batchEntry := RecordBatchEntry{Delete: make([]ResourceRecord, 0, len(batch.Delete))}
+	if len(rrSet) != 0 {
+		batchEntry.Set = []ZoneEntry{{Zone: ".", RR: &rrSet}}
+	}
+	for _, rr := range batch.Delete {
+		batchEntry.Delete = append(batchEntry.Delete, ResourceRecord{Name: getFqdn(rr.Name), Type: rr.Type})
+	}
+	batchJSON, err := json.Marshal(batchEntry)
+	if err != nil {
+		log.Errorf(nil, "marshal dns batch info failed")
+		return err
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, d.GetEndpoint("rrecord", "batch"),
+		bytes.NewBuffer(batchJSON))
+	if err != nil {
+		log.Errorf(nil, "http request creation for dns batch update failed.")
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	httpResp, err := d.client.Do(httpReq)
+	if err != nil {
+		log.Errorf(nil, "request to dns server failed in batch update")
+		return err
+	}
+	if !meputil.IsHttpStatusOK(httpResp.StatusCode) {
+		log.Errorf(nil, "dns rule batch update failed on server(%d: %s).", httpResp.StatusCode, httpResp.Status)
+		return fmt.Errorf("batch update request to dns server failed")
+	}
+	return nil
+}
+
+func getFqdn(host string) string {
+	if !strings.HasSuffix(host, ".") {
+		return host + "."
+	}
+	return host
+}
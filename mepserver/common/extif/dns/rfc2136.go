@@ -0,0 +1,201 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package path implements dns client
+package dns
+
+import (
+	"fmt"
+	"mepserver/common/config"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/servicecomb-service-center/pkg/log"
+	mdns "github.com/miekg/dns"
+
+	meputil "mepserver/common/util"
+)
+
+// Allowed time difference between the signer and the dns server in seconds
+const tsigFudge = 300
+
+var tsigAlgorithms = map[string]string{
+	meputil.TsigHmacSHA1:   mdns.HmacSHA1,
+	meputil.TsigHmacSHA256: mdns.HmacSHA256,
+	meputil.TsigHmacSHA512: mdns.HmacSHA512,
+}
+
+func init() {
+	RegisterDNSAgent(meputil.DnsAgentTypeRFC2136, func(config *config.MepServerConfig) (DNSAgent, error) {
+		return NewRFC2136DNSAgent(config)
+	})
+}
+
+// DNS agent to update any standard dns server using the RFC 2136 dynamic updates, signed with TSIG(RFC 2845)
+// if a key is configured
+type RFC2136DNSAgent struct {
+	DNSAgent
+	server        string
+	zone          string
+	tsigKeyName   string
+	tsigAlgorithm string
+	client        *mdns.Client
+}
+
+func NewRFC2136DNSAgent(config *config.MepServerConfig) (*RFC2136DNSAgent, error) {
+	address := config.DNSAgent.Endpoint.Address
+	if len(address.Host) == 0 {
+		return nil, fmt.Errorf("invalid dns server host")
+	}
+	port := address.Port
+	if port == 0 {
+		port = meputil.DefaultDnsPort
+	}
+	rfc2136 := config.DNSAgent.RFC2136
+	if len(rfc2136.Zone) == 0 {
+		return nil, fmt.Errorf("invalid dns zone")
+	}
+
+	timeout := rfc2136.Timeout
+	if timeout == 0 {
+		timeout = meputil.DefaultDnsUpdateTimeout
+	}
+	agent := &RFC2136DNSAgent{
+		server: net.JoinHostPort(address.Host, strconv.Itoa(port)),
+		zone:   mdns.CanonicalName(rfc2136.Zone),
+		client: &mdns.Client{Net: rfc2136.Transport, Timeout: time.Duration(timeout) * time.Second},
+	}
+
+	if len(rfc2136.TsigKeyName) != 0 {
+		algorithm := rfc2136.TsigAlgorithm
+		if len(algorithm) == 0 {
+			algorithm = meputil.TsigHmacSHA256
+		}
+		tsigAlgorithm, found := tsigAlgorithms[algorithm]
+		if !found {
+			return nil, fmt.Errorf("unsupported tsig algorithm(%s)", algorithm)
+		}
+		agent.tsigKeyName = mdns.CanonicalName(rfc2136.TsigKeyName)
+		agent.tsigAlgorithm = tsigAlgorithm
+		agent.client.TsigSecret = map[string]string{agent.tsigKeyName: rfc2136.TsigSecret}
+	}
+	return agent, nil
+}
+
+func (d *RFC2136DNSAgent) SetResourceRecordTypeA(host, rrtype, class string, pointTo []string, ttl uint32) error {
+	hostName, rrType, err := d.validateRecord(host, rrtype)
+	if err != nil {
+		return err
+	}
+
+	records, err := newResourceRecords(hostName, rrtype, class, pointTo, ttl)
+	if err != nil {
+		return err
+	}
+	// Replace the existing record set with the new one
+	msg := new(mdns.Msg)
+	msg.SetUpdate(d.zone)
+	msg.RemoveRRset([]mdns.RR{&mdns.ANY{Hdr: mdns.RR_Header{Name: hostName, Rrtype: rrType}}})
+	msg.Insert(records)
+
+	return d.sendUpdate(msg)
+}
+
+func (d *RFC2136DNSAgent) DeleteResourceRecordTypeA(host, rrtype string) error {
+	hostName, rrType, err := d.validateRecord(host, rrtype)
+	if err != nil {
+		return err
+	}
+
+	msg := new(mdns.Msg)
+	msg.SetUpdate(d.zone)
+	msg.RemoveRRset([]mdns.RR{&mdns.ANY{Hdr: mdns.RR_Header{Name: hostName, Rrtype: rrType}}})
+
+	return d.sendUpdate(msg)
+}
+
+func (d *RFC2136DNSAgent) UpdateResourceRecords(batch *RecordBatch) error {
+	// A single update message is applied atomically by the dns server as per RFC 2136 section 3.4
+	msg := new(mdns.Msg)
+	msg.SetUpdate(d.zone)
+	for _, rr := range batch.Delete {
+		hostName, rrType, err := d.validateRecord(rr.Name, rr.Type)
+		if err != nil {
+			return err
+		}
+		msg.RemoveRRset([]mdns.RR{&mdns.ANY{Hdr: mdns.RR_Header{Name: hostName, Rrtype: rrType}}})
+	}
+	for _, rr := range batch.Set {
+		hostName, rrType, err := d.validateRecord(rr.Name, rr.Type)
+		if err != nil {
+			return err
+		}
+		records, err := newResourceRecords(hostName, rr.Type, rr.Class, rr.RData, rr.TTL)
+		if err != nil {
+			return err
+		}
+		msg.RemoveRRset([]mdns.RR{&mdns.ANY{Hdr: mdns.RR_Header{Name: hostName, Rrtype: rrType}}})
+		msg.Insert(records)
+	}
+
+	return d.sendUpdate(msg)
+}
+
+func newResourceRecords(hostName, rrtype, class string, pointTo []string, ttl uint32) ([]mdns.RR, error) {
+	var records []mdns.RR
+	for _, ip := range pointTo {
+		record, err := mdns.NewRR(fmt.Sprintf("%s %d %s %s %s", hostName, ttl, class, rrtype, ip))
+		if err != nil {
+			log.Errorf(nil, "invalid dns resource record(%s %s %s).", hostName, rrtype, ip)
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// Check the record belongs to the configured zone and convert it to the wire format values
+func (d *RFC2136DNSAgent) validateRecord(host, rrtype string) (string, uint16, error) {
+	hostName := mdns.CanonicalName(host)
+	if !mdns.IsSubDomain(d.zone, hostName) {
+		log.Errorf(nil, "dns record(%s) is out of the zone(%s).", hostName, d.zone)
+		return "", 0, fmt.Errorf("dns record is out of the configured zone")
+	}
+	rrType, found := mdns.StringToType[strings.ToUpper(rrtype)]
+	if !found {
+		return "", 0, fmt.Errorf("unsupported dns record type(%s)", rrtype)
+	}
+	return hostName, rrType, nil
+}
+
+func (d *RFC2136DNSAgent) sendUpdate(msg *mdns.Msg) error {
+	if len(d.tsigKeyName) != 0 {
+		msg.SetTsig(d.tsigKeyName, d.tsigAlgorithm, tsigFudge, time.Now().Unix())
+	}
+
+	rsp, _, err := d.client.Exchange(msg, d.server)
+	if err != nil {
+		log.Errorf(nil, "dns update request to server(%s) failed.", d.server)
+		return err
+	}
+	if rsp.Rcode != mdns.RcodeSuccess {
+		log.Errorf(nil, "dns update failed on server(%s: %s).", d.server, mdns.RcodeToString[rsp.Rcode])
+		return fmt.Errorf("update request to dns server failed(%s)", mdns.RcodeToString[rsp.Rcode])
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dns
+
+import (
+	"mepserver/common/config"
+	"net"
+	"testing"
+	"time"
+
+	mdns "github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+const tsigKeyName = "mep-key."
+const tsigSecret = "c2VjcmV0LWtleS1mb3ItbWVw"
+const testZone = "example.com."
+const defaultDnsPort = 53
+
+// Start a dns server which accepts only the TSIG signed updates and records the received update sections
+func startUpdateServer(t *testing.T, updates chan<- *mdns.Msg) (*mdns.Server, int) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := &mdns.Server{PacketConn: conn, TsigSecret: map[string]string{tsigKeyName: tsigSecret},
+		// Default accept function rejects the update opcode
+		MsgAcceptFunc: func(dh mdns.Header) mdns.MsgAcceptAction { return mdns.MsgAccept }}
+	server.Handler = mdns.HandlerFunc(func(w mdns.ResponseWriter, req *mdns.Msg) {
+		rsp := new(mdns.Msg)
+		rsp.SetReply(req)
+		if req.IsTsig() == nil || w.TsigStatus() != nil {
+			rsp.Rcode = mdns.RcodeNotAuth
+		} else {
+			updates <- req
+			rsp.SetTsig(tsigKeyName, mdns.HmacSHA256, tsigFudge, int64(req.IsTsig().TimeSigned))
+		}
+		_ = w.WriteMsg(rsp)
+	})
+	started := make(chan struct{})
+	server.NotifyStartedFunc = func() { close(started) }
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+	<-started
+	return server, conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+func receiveUpdate(t *testing.T, updates <-chan *mdns.Msg) *mdns.Msg {
+	select {
+	case update := <-updates:
+		return update
+	case <-time.After(time.Second):
+		t.Fatalf("dns update not received")
+	}
+	return nil
+}
+
+func newRFC2136Config(port int, secret string) *config.MepServerConfig {
+	mepConfig := &config.MepServerConfig{}
+	mepConfig.DNSAgent.Type = "rfc2136"
+	mepConfig.DNSAgent.Endpoint.Address = config.Address{Host: "127.0.0.1", Port: port}
+	mepConfig.DNSAgent.RFC2136 = config.RFC2136{Zone: testZone, TsigKeyName: tsigKeyName, TsigSecret: secret}
+	return mepConfig
+}
+
+func TestRFC2136SetAndDeleteRecord(t *testing.T) {
+	updates := make(chan *mdns.Msg, 2)
+	server, port := startUpdateServer(t, updates)
+	defer server.Shutdown()
+
+	agent, err := CreateDNSAgent(newRFC2136Config(port, tsigSecret))
+	if err != nil {
+		t.Fatalf("agent creation failed: %v", err)
+	}
+
+	err = agent.SetResourceRecordTypeA("www.example.com", "A", "IN", []string{"192.0.2.10"}, 30)
+	assert.NoError(t, err)
+	update := receiveUpdate(t, updates)
+	// Existing record set removal followed by the new record
+	assert.Equal(t, 2, len(update.Ns))
+	assert.Equal(t, uint16(mdns.ClassANY), update.Ns[0].Header().Class)
+	record, ok := update.Ns[1].(*mdns.A)
+	assert.True(t, ok)
+	assert.Equal(t, "www.example.com.", record.Hdr.Name)
+	assert.Equal(t, "192.0.2.10", record.A.String())
+	assert.Equal(t, uint32(30), record.Hdr.Ttl)
+
+	err = agent.DeleteResourceRecordTypeA("www.example.com", "A")
+	assert.NoError(t, err)
+	update = receiveUpdate(t, updates)
+	assert.Equal(t, 1, len(update.Ns))
+	assert.Equal(t, mdns.TypeA, update.Ns[0].Header().Rrtype)
+}
+
+func TestRFC2136WrongTsigSecret(t *testing.T) {
+	updates := make(chan *mdns.Msg, 1)
+	server, port := startUpdateServer(t, updates)
+	defer server.Shutdown()
+
+	agent, err := CreateDNSAgent(newRFC2136Config(port, "d3Jvbmctc2VjcmV0"))
+	if err != nil {
+		t.Fatalf("agent creation failed: %v", err)
+	}
+	err = agent.DeleteResourceRecordTypeA("www.example.com", "A")
+	assert.Error(t, err)
+}
+
+func TestRFC2136RecordOutOfZone(t *testing.T) {
+	agent, err := CreateDNSAgent(newRFC2136Config(defaultDnsPort, tsigSecret))
+	if err != nil {
+		t.Fatalf("agent creation failed: %v", err)
+	}
+	err = agent.SetResourceRecordTypeA("www.example.org", "A", "IN", []string{"192.0.2.10"}, 30)
+	assert.EqualError(t, err, "dns record is out of the configured zone")
+}
+
+func TestCreateDNSAgentUnsupportedType(t *testing.T) {
+	mepConfig := &config.MepServerConfig{}
+	mepConfig.DNSAgent.Type = "unknown"
+	_, err := CreateDNSAgent(mepConfig)
+	assert.Error(t, err)
+
+	mepConfig.DNSAgent.Type = "dataplane"
+	agent, err := CreateDNSAgent(mepConfig)
+	assert.NoError(t, err)
+	assert.Nil(t, agent)
+}
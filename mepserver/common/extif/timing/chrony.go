@@ -0,0 +1,83 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package path implements the platform time source
+package timing
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"mepserver/common/config"
+)
+
+// Default source of the chrony tracking
+var chronyTrackingCommand = []string{"chronyc", "tracking"}
+
+// NTP time source synchronized by chrony, the status is read from the chrony tracking report
+type ChronyTimeSource struct {
+	status    config.TimingStatus
+	maxOffset time.Duration
+}
+
+// Clock is traceable while chrony is synchronized to a server and the system time is within the max offset
+func (c *ChronyTimeSource) GetTimeSourceStatus() (string, error) {
+	output, err := readClockStatus(c.status, chronyTrackingCommand)
+	if err != nil {
+		return "", err
+	}
+	tracking := parseChronyTracking(output)
+
+	if leap := tracking["Leap status"]; len(leap) == 0 || leap == "Not synchronised" {
+		return traceability(false), nil
+	}
+	stratum, err := strconv.Atoi(tracking["Stratum"])
+	if err != nil {
+		return "", fmt.Errorf("invalid chrony tracking stratum")
+	}
+	// System time is reported as "<offset> seconds fast|slow of NTP time"
+	fields := strings.Fields(tracking["System time"])
+	if len(fields) == 0 {
+		return "", fmt.Errorf("invalid chrony tracking system time")
+	}
+	offset, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid chrony tracking system time")
+	}
+	offsetDuration := time.Duration(offset * float64(time.Second))
+	if offsetDuration < 0 {
+		offsetDuration = -offsetDuration
+	}
+	return traceability(stratum > 0 && stratum < 16 && offsetDuration <= c.maxOffset), nil
+}
+
+// Tracking report has a "<name> : <value>" line per field
+func parseChronyTracking(output []byte) map[string]string {
+	tracking := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		tracking[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return tracking
+}
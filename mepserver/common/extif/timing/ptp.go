@@ -0,0 +1,75 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package path implements the platform time source
+package timing
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"mepserver/common/config"
+)
+
+// Default source of the linuxptp time status
+var ptpTimeStatusCommand = []string{"pmc", "-u", "-b", "0", "GET TIME_STATUS_NP"}
+
+// PTP time source synchronized by linuxptp, the status is read from the TIME_STATUS_NP management response
+type PtpTimeSource struct {
+	status    config.TimingStatus
+	maxOffset time.Duration
+}
+
+// Clock is traceable while a grand master is present and the offset from the master is within the max offset
+func (p *PtpTimeSource) GetTimeSourceStatus() (string, error) {
+	output, err := readClockStatus(p.status, ptpTimeStatusCommand)
+	if err != nil {
+		return "", err
+	}
+	timeStatus := parsePtpTimeStatus(output)
+
+	if timeStatus["gmPresent"] != "true" {
+		return traceability(false), nil
+	}
+	// Master offset is in nanoseconds
+	offset, err := strconv.ParseInt(timeStatus["master_offset"], 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid ptp master offset")
+	}
+	offsetDuration := time.Duration(offset)
+	if offsetDuration < 0 {
+		offsetDuration = -offsetDuration
+	}
+	return traceability(offsetDuration <= p.maxOffset), nil
+}
+
+// Management response has a "<name> <value>" line per field
+func parsePtpTimeStatus(output []byte) map[string]string {
+	timeStatus := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		timeStatus[fields[0]] = fields[1]
+	}
+	return timeStatus
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package path implements the platform time source
+package timing
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/apache/servicecomb-service-center/pkg/log"
+
+	meputil "mepserver/common/util"
+)
+
+// Status older than this many poll intervals is not reported, the clock might have drifted meanwhile
+const statusCacheStaleIntervals = 3
+
+// Clock status of a time source read periodically, the status is served from the last read instead of running the
+// status command on every request
+type StatusCache struct {
+	timeSource TimeSource
+	interval   time.Duration
+	mutex      sync.RWMutex
+	status     string
+	err        error
+	readTime   time.Time
+}
+
+// Cache of the clock status of the time source, read every poll interval in seconds once polling
+func NewStatusCache(timeSource TimeSource, pollInterval int) *StatusCache {
+	if pollInterval == 0 {
+		pollInterval = meputil.DefaultTimingPollInterval
+	}
+	return &StatusCache{timeSource: timeSource, interval: time.Duration(pollInterval) * time.Second}
+}
+
+// Read the clock status every poll interval, never returns
+func (c *StatusCache) Poll() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		c.refresh()
+		<-ticker.C
+	}
+}
+
+func (c *StatusCache) refresh() {
+	status, err := c.timeSource.GetTimeSourceStatus()
+	if err != nil {
+		log.Errorf(err, "failed to read the clock status from the time source")
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.status, c.err, c.readTime = status, err, time.Now()
+}
+
+// Clock status of the last read, an error if the status is not read yet or is stale
+func (c *StatusCache) GetTimeSourceStatus() (string, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if c.readTime.IsZero() {
+		return "", errors.New("clock status not read yet")
+	}
+	if time.Since(c.readTime) > statusCacheStaleIntervals*c.interval {
+		return "", errors.New("clock status is stale")
+	}
+	return c.status, c.err
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package path implements the platform time source
+package timing
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"time"
+
+	"mepserver/common/config"
+	meputil "mepserver/common/util"
+)
+
+// Time source of the platform clock
+type TimeSource interface {
+	// Traceability of the platform clock to the time source, TRACEABLE or NONTRACEABLE
+	GetTimeSourceStatus() (string, error)
+}
+
+// Create the time source as per the configured type, nil is returned if no time source is configured
+func CreateTimeSource(config *config.MepServerConfig) (TimeSource, error) {
+	timing := config.Timing
+	maxOffset := time.Duration(timing.MaxOffset) * time.Microsecond
+	if maxOffset == 0 {
+		maxOffset = meputil.DefaultTimingMaxOffset * time.Microsecond
+	}
+	switch timing.Source {
+	case "":
+		return nil, nil
+	case meputil.TimeSourceNTP:
+		return &ChronyTimeSource{status: timing.Status, maxOffset: maxOffset}, nil
+	case meputil.TimeSourcePTP:
+		return &PtpTimeSource{status: timing.Status, maxOffset: maxOffset}, nil
+	}
+	return nil, fmt.Errorf("unsupported time source(%s)", timing.Source)
+}
+
+// Read the clock status from the file, or else from the output of the command
+func readClockStatus(status config.TimingStatus, defaultCommand []string) ([]byte, error) {
+	if len(status.File) != 0 {
+		return ioutil.ReadFile(status.File)
+	}
+	command := status.Command
+	if len(command) == 0 {
+		command = defaultCommand
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), meputil.TimingStatusTimeout*time.Second)
+	defer cancel()
+	return exec.CommandContext(ctx, command[0], command[1:]...).Output()
+}
+
+func traceability(traceable bool) string {
+	if traceable {
+		return meputil.TimeSourceTraceable
+	}
+	return meputil.TimeSourceNonTraceable
+}
@@ -0,0 +1,157 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package timing
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"mepserver/common/config"
+	meputil "mepserver/common/util"
+)
+
+const chronySynchronized = `Reference ID    : C0A80101 (192.168.1.1)
+Stratum         : 3
+Ref time (UTC)  : Sun Oct 18 10:00:00 2026
+System time     : 0.000012345 seconds slow of NTP time
+Last offset     : -0.000001234 seconds
+RMS offset      : 0.000010000 seconds
+Frequency       : 1.234 ppm fast
+Leap status     : Normal
+`
+
+const chronyNotSynchronized = `Reference ID    : 00000000 ()
+Stratum         : 0
+Ref time (UTC)  : Thu Jan 01 00:00:00 1970
+System time     : 0.000000000 seconds fast of NTP time
+Leap status     : Not synchronised
+`
+
+const ptpTimeStatus = `sending: GET TIME_STATUS_NP
+	507c6f.fffe.0fe2ff-0 seq 0 RESPONSE MANAGEMENT TIME_STATUS_NP
+		master_offset              %s
+		ingress_time               1792324800123456789
+		gmPresent                  %s
+		gmIdentity                 507c6f.fffe.0fe2ff
+`
+
+// Write the stubbed clock status and create the time source reading it
+func stubTimeSource(t *testing.T, source, status string, maxOffset int) TimeSource {
+	dir, err := ioutil.TempDir("", "timing")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	file := filepath.Join(dir, "status")
+	if err = ioutil.WriteFile(file, []byte(status), 0600); err != nil {
+		t.Fatalf("failed to write the clock status: %v", err)
+	}
+	timeSource, err := CreateTimeSource(&config.MepServerConfig{Timing: config.Timing{Source: source,
+		Status: config.TimingStatus{File: file}, MaxOffset: maxOffset}})
+	assert.Nil(t, err)
+	return timeSource
+}
+
+func TestChronyTimeSource(t *testing.T) {
+	status, err := stubTimeSource(t, meputil.TimeSourceNTP, chronySynchronized, 0).GetTimeSourceStatus()
+	assert.Nil(t, err)
+	assert.Equal(t, meputil.TimeSourceTraceable, status)
+
+	// Offset of 12us is above the max offset
+	status, err = stubTimeSource(t, meputil.TimeSourceNTP, chronySynchronized, 10).GetTimeSourceStatus()
+	assert.Nil(t, err)
+	assert.Equal(t, meputil.TimeSourceNonTraceable, status)
+
+	status, err = stubTimeSource(t, meputil.TimeSourceNTP, chronyNotSynchronized, 0).GetTimeSourceStatus()
+	assert.Nil(t, err)
+	assert.Equal(t, meputil.TimeSourceNonTraceable, status)
+}
+
+func TestPtpTimeSource(t *testing.T) {
+	status, err := stubTimeSource(t, meputil.TimeSourcePTP, fmt.Sprintf(ptpTimeStatus, "-120", "true"),
+		0).GetTimeSourceStatus()
+	assert.Nil(t, err)
+	assert.Equal(t, meputil.TimeSourceTraceable, status)
+
+	status, err = stubTimeSource(t, meputil.TimeSourcePTP, fmt.Sprintf(ptpTimeStatus, "0", "false"),
+		0).GetTimeSourceStatus()
+	assert.Nil(t, err)
+	assert.Equal(t, meputil.TimeSourceNonTraceable, status)
+
+	_, err = stubTimeSource(t, meputil.TimeSourcePTP, fmt.Sprintf(ptpTimeStatus, "invalid", "true"),
+		0).GetTimeSourceStatus()
+	assert.NotNil(t, err)
+}
+
+func TestTimeSourceCommand(t *testing.T) {
+	timeSource, err := CreateTimeSource(&config.MepServerConfig{Timing: config.Timing{Source: meputil.TimeSourceNTP,
+		Status: config.TimingStatus{Command: []string{"echo", "Leap status : Not synchronised"}}}})
+	assert.Nil(t, err)
+	status, err := timeSource.GetTimeSourceStatus()
+	assert.Nil(t, err)
+	assert.Equal(t, meputil.TimeSourceNonTraceable, status)
+
+	timeSource, err = CreateTimeSource(&config.MepServerConfig{})
+	assert.Nil(t, err)
+	assert.Nil(t, timeSource)
+}
+
+// Time source counting the clock status reads
+type countingTimeSource struct {
+	reads int
+	err   error
+}
+
+func (c *countingTimeSource) GetTimeSourceStatus() (string, error) {
+	c.reads++
+	return meputil.TimeSourceTraceable, c.err
+}
+
+func TestStatusCache(t *testing.T) {
+	timeSource := &countingTimeSource{}
+	cache := NewStatusCache(timeSource, 0)
+	assert.Equal(t, meputil.DefaultTimingPollInterval*time.Second, cache.interval)
+
+	_, err := cache.GetTimeSourceStatus()
+	assert.NotNil(t, err, "status must not be reported before the first read")
+
+	cache.refresh()
+	for i := 0; i < 3; i++ {
+		status, err := cache.GetTimeSourceStatus()
+		assert.Nil(t, err)
+		assert.Equal(t, meputil.TimeSourceTraceable, status)
+	}
+	assert.Equal(t, 1, timeSource.reads, "status must be served from the last read")
+
+	timeSource.err = errors.New("chronyc not found")
+	cache.refresh()
+	_, err = cache.GetTimeSourceStatus()
+	assert.Equal(t, timeSource.err, err)
+
+	timeSource.err = nil
+	cache.refresh()
+	cache.readTime = time.Now().Add(-(statusCacheStaleIntervals + 1) * cache.interval)
+	_, err = cache.GetTimeSourceStatus()
+	assert.NotNil(t, err, "stale status must not be reported")
+}
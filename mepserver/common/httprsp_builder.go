@@ -43,6 +43,15 @@ type SendHttpRsp struct {
 	W          http.ResponseWriter `json:"w,in"`
 	HttpRsp    interface{}         `json:"httpRsp,in"`
 	StatusCode int
+	// Status code decided by the plans on the request, overrides the StatusCode
+	RspStatusCode int `json:"rspStatusCode,in"`
+	Page          *ResponsePage
+}
+
+// set the paged collection of the response and return SendHttpRsp
+func (t *SendHttpRsp) WithPage(idAttribute string, listPath ...string) *SendHttpRsp {
+	t.Page = &ResponsePage{IdAttribute: idAttribute, ListPath: listPath}
+	return t
 }
 
 // OnRequest
@@ -80,6 +89,16 @@ func (t *SendHttpRsp) OnRequest(data string) workspace.TaskCode {
 			util.GetResourceInfo(t.R), errInfo.Message)
 		return workspace.TaskFinish
 	}
+	if t.Page != nil {
+		var pageErr *workspace.SerErrInfo
+		if t.HttpRsp, pageErr = t.Page.apply(t.R, t.W, t.HttpRsp); pageErr != nil {
+			statusCode, httpBody := t.cvtHttpErrInfo(pageErr)
+			util.HttpErrResponse(t.W, statusCode, httpBody)
+			log.Infof(failureEventLogFormat, util.GetClientIp(t.R), util.GetAppInstanceId(t.R), util.GetMethod(t.R),
+				util.GetResourceInfo(t.R), pageErr.Message)
+			return workspace.TaskFinish
+		}
+	}
 	log.Infof(successEventLogFormat, util.GetClientIp(t.R), util.GetAppInstanceId(t.R), util.GetMethod(t.R),
 		util.GetResourceInfo(t.R))
 	t.writeResponse(t.W, t.HttpErrInf, t.HttpRsp)
@@ -104,6 +123,9 @@ func (t *SendHttpRsp) writeResponse(w http.ResponseWriter, resp *proto.Response,
 		return
 	}
 	w.Header().Set(rest.HEADER_CONTENT_TYPE, rest.CONTENT_TYPE_JSON)
+	if t.RspStatusCode != 0 {
+		t.StatusCode = t.RspStatusCode
+	}
 	if t.StatusCode == 0 {
 		t.StatusCode = http.StatusOK
 	}
@@ -161,6 +183,15 @@ func (t *SendHttpRsp) cvtHttpErrInfo(errInfo *workspace.SerErrInfo) (int, interf
 	case util.ForbiddenOperation:
 		statusCode = http.StatusForbidden
 		body.Title = "Operation Not Allowed"
+	case util.TaskFinishedErr:
+		statusCode = http.StatusConflict
+		body.Title = "Task already finished"
+	case util.TerminationNotInProgressErr:
+		statusCode = http.StatusConflict
+		body.Title = "Termination not in progress"
+	case util.RecordModifiedErr:
+		statusCode = http.StatusConflict
+		body.Title = "Resource modified concurrently"
 
 	default:
 		body.Title = "Bad Request"
@@ -27,8 +27,14 @@ type AppDConfig struct {
 	AppDNSRule     []dataplane.DNSRule     `json:"appDNSRule" validate:"dive,max=32"`
 	AppSupportMp1  bool                    `json:"appSupportMp1"`
 	AppName        string                  `json:"appName" validate:"required,min=1,max=63"`
+	// Mp1RuleMgmt allows the app to create and delete its own traffic and dns rules over mp1
+	Mp1RuleMgmt bool `json:"mp1RuleMgmt,omitempty"`
 	// Operation specifies the type of the request
 	Operation string `json:"operation,omitempty"` // For local use in the DB only
+	// TaskId specifies the task processing the request
+	TaskId string `json:"taskId,omitempty"` // For local use in the DB only
+	// CallbackUri is taken from the request query and kept in the task status
+	CallbackUri string `json:"-"`
 }
 
 type TaskStatus struct {
@@ -36,6 +42,15 @@ type TaskStatus struct {
 	TrafficRuleStatusLst []RuleStatus `json:"trafficRuleStatusList"`
 	DNSRuleStatusLst     []RuleStatus `json:"dnsRuleStatusList"`
 	Details              string       `json:"details" validate:"omitempty"`
+	// Recovery specifies how the task was completed after a mep-server restart
+	Recovery string `json:"recovery,omitempty"`
+	// Reverting holds the final progress of a task while its applied rules are being reverted
+	Reverting int `json:"reverting,omitempty"`
+	// Task creation and last update time in unix seconds
+	CreateTime int64 `json:"createTime,omitempty"`
+	UpdateTime int64 `json:"updateTime,omitempty"`
+	// CallbackUri receives the task progress once the task succeeds or fails
+	CallbackUri string `json:"callbackUri,omitempty"`
 }
 
 type RuleStatus struct {
@@ -50,6 +65,76 @@ type TaskProgress struct {
 	ConfigResult  string `json:"configResult"`
 	ConfigPhase   string `json:"configPhase"`
 	Details       string `json:"Detailed"`
+	Recovery      string `json:"recovery,omitempty"`
+	CreateTime    int64  `json:"createTime,omitempty"`
+	UpdateTime    int64  `json:"updateTime,omitempty"`
+}
+
+// Rule operations computed for an AppD configuration request without applying it
+type AppDConfigPlan struct {
+	AppInstanceId string     `json:"appInstanceId"`
+	TrafficRules  []RulePlan `json:"trafficRules"`
+	DNSRules      []RulePlan `json:"dnsRules"`
+}
+
+type RulePlan struct {
+	Id        string `json:"id"`
+	Operation string `json:"operation"`
+}
+
+// Rule level add, replace and remove operations on an AppD configuration
+type AppDRuleOperations struct {
+	AppTrafficRule []TrafficRuleOperation `json:"appTrafficRule" validate:"dive"`
+	AppDNSRule     []DNSRuleOperation     `json:"appDNSRule" validate:"dive"`
+}
+
+type TrafficRuleOperation struct {
+	Op            string                 `json:"op" validate:"required,oneof=add replace remove"`
+	TrafficRuleID string                 `json:"trafficRuleId" validate:"required,min=1,max=63"`
+	Value         *dataplane.TrafficRule `json:"value,omitempty"` // Required for add and replace
+}
+
+type DNSRuleOperation struct {
+	Op        string             `json:"op" validate:"required,oneof=add replace remove"`
+	DNSRuleID string             `json:"dnsRuleId" validate:"required,min=1,max=63"`
+	Value     *dataplane.DNSRule `json:"value,omitempty"` // Required for add and replace
+}
+
+// Applied AppD configuration kept in the version history
+type AppDConfigVersion struct {
+	Version    int        `json:"version"`
+	TaskId     string     `json:"taskId"`
+	CreateTime int64      `json:"createTime"`
+	AppDConfig AppDConfig `json:"appDConfig"`
+}
+
+// Rule operations to move the AppD configuration from one version to another
+type AppDConfigVersionDiff struct {
+	AppInstanceId string     `json:"appInstanceId"`
+	FromVersion   int        `json:"fromVersion"`
+	ToVersion     int        `json:"toVersion"`
+	TrafficRules  []RulePlan `json:"trafficRules"`
+	DNSRules      []RulePlan `json:"dnsRules"`
+}
+
+type AppDConfigRollback struct {
+	Version int `json:"version" validate:"required,min=1"`
+}
+
+// Load of the appd configuration task processing, wait times are in milliseconds
+type TaskQueueStats struct {
+	Workers         int    `json:"workers"`
+	BusyWorkers     int    `json:"busyWorkers"`
+	QueueDepth      int    `json:"queueDepth"`
+	OldestWaitTime  int64  `json:"oldestWaitTime"`
+	AverageWaitTime int64  `json:"averageWaitTime"`
+	MaxWaitTime     int64  `json:"maxWaitTime"`
+	StartedTasks    uint64 `json:"startedTasks"`
+}
+
+type TaskProgressList struct {
+	TotalCount int            `json:"totalCount"`
+	Tasks      []TaskProgress `json:"tasks"`
 }
 
 //Use ProblemDetails struct for Returning task fail immediate response
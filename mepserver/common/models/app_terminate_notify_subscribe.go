@@ -19,9 +19,45 @@ package models
 
 // This type represents a subscription to the notifications from the  MEC platform regarding the availability of a MEC service or a list of MEC services.
 type AppTerminationNotificationSubscription struct {
-	SubscriptionId    string `json:"subscriptionId,omitempty"`
-	SubscriptionType  string `json:"subscriptionType" validate:"required,oneof=AppTerminationNotificationSubscription SerAvailabilityNotificationSubscription"`
-	CallbackReference string `json:"callbackReference" validate:"required,uri"`
-	Links             Links  `json:"_links,omitempty" validate:"required"`
-	AppInstanceId     string `json:"appInstanceId" validate:"required,uuid"`
+	SubscriptionId     string              `json:"subscriptionId,omitempty"`
+	SubscriptionType   string              `json:"subscriptionType" validate:"required,oneof=AppTerminationNotificationSubscription SerAvailabilityNotificationSubscription"`
+	CallbackReference  string              `json:"callbackReference,omitempty" validate:"required_without=WebsockNotifConfig,omitempty,uri"`
+	WebsockNotifConfig *WebsockNotifConfig `json:"websockNotifConfig,omitempty"`
+	Links              Links               `json:"_links,omitempty" validate:"required"`
+	AppInstanceId      string              `json:"appInstanceId" validate:"required,uuid"`
+	ExpiryDeadline     *TimeStamp          `json:"expiryDeadline,omitempty"`
+}
+
+// Uri the notifications of the subscription are sent to
+func (s *AppTerminationNotificationSubscription) NotificationUri() string {
+	return s.WebsockNotifConfig.notificationUri(s.CallbackReference)
+}
+
+// This type represents the information that the MEC platform notifies the subscribed application instance about
+// the corresponding application instance termination/stop.
+type AppTerminationNotification struct {
+	NotificationType   string                          `json:"notificationType"`
+	OperationAction    string                          `json:"operationAction"`
+	MaxGracefulTimeout uint32                          `json:"maxGracefulTimeout"`
+	Links              AppTerminationNotificationLinks `json:"_links"`
+}
+
+type AppTerminationNotificationLinks struct {
+	Subscription       SerLinkType `json:"subscription"`
+	ConfirmTermination SerLinkType `json:"confirmTermination"`
+}
+
+// This type represents the information that the application instance provides to the MEC platform when
+// confirming the termination/stop.
+type AppTerminationConfirmation struct {
+	OperationAction string `json:"operationAction" validate:"required,oneof=STOPPING TERMINATING"`
+}
+
+// Termination in progress for an app instance, for local use in the DB only
+type AppTerminationStatus struct {
+	OperationAction string `json:"operationAction"`
+	Confirmed       bool   `json:"confirmed"`
+	Deadline        int64  `json:"deadline"` // unix time the graceful wait ends
+	Domain          string `json:"domain"`
+	Project         string `json:"project"`
 }
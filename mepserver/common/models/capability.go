@@ -17,6 +17,8 @@
 // Package path implements mep server object models
 package models
 
+import "mepserver/common/extif/dataplane"
+
 // Represent a consumer data
 type Consumer struct {
 	AppInstanceId string `json:"applicationInstanceId"`
@@ -24,9 +26,16 @@ type Consumer struct {
 
 // Represents single Capability entry
 type PlatformCapability struct {
-	CapabilityId   string     `json:"capabilityId"`
-	CapabilityName string     `json:"capabilityName"`
-	Status         string     `json:"status"`
-	Version        string     `json:"version"`
-	Consumers      []Consumer `json:"consumers"`
+	CapabilityId    string               `json:"capabilityId"`
+	CapabilityName  string               `json:"capabilityName"`
+	Status          string               `json:"status"`
+	Version         string               `json:"version"`
+	Consumers       []Consumer           `json:"consumers"`
+	SerCategory     *CategoryRef         `json:"serCategory,omitempty"`
+	Serializer      string               `json:"serializer,omitempty"`
+	ScopeOfLocality string               `json:"scopeOfLocality,omitempty"`
+	TransportInfo   *TransportInfo       `json:"transportInfo,omitempty"`
+	Liveness        *ServiceLivenessInfo `json:"liveness,omitempty"`
+	// Capabilities of the data-plane, reported only in the data-plane capability
+	DataPlane *dataplane.Capabilities `json:"dataPlane,omitempty"`
 }
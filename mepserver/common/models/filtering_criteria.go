@@ -17,6 +17,12 @@
 // Package path implements mep server object models
 package models
 
+import (
+	"strconv"
+
+	meputil "mepserver/common/util"
+)
+
 // Filtering criteria to match services for which events are requested to be reported. If absent, matches all services. All child attributes are combined with the logical  \"AND\" operation.
 type FilteringCriteria struct {
 	SerInstanceIds []string      `json:"serInstanceIds" validate:"omitempty,min=0,dive,max=32,validateId"`
@@ -25,3 +31,16 @@ type FilteringCriteria struct {
 	States         []string      `json:"states" validate:"omitempty,min=0,dive,oneof=ACTIVE INACTIVE SUSPENDED"`
 	IsLocal        bool          `json:"isLocal,omitempty"`
 }
+
+// Service filter of the criteria. Categories are matched by their id only, and isLocal restricts the match to the
+// local services when set.
+func (c *FilteringCriteria) ServiceFilter() *meputil.ServiceFilter {
+	filter := &meputil.ServiceFilter{SerInstanceIds: c.SerInstanceIds, SerNames: c.SerNames, States: c.States}
+	for _, category := range c.SerCategories {
+		filter.SerCategoryIds = append(filter.SerCategoryIds, category.ID)
+	}
+	if c.IsLocal {
+		filter.IsLocal = strconv.FormatBool(c.IsLocal)
+	}
+	return filter
+}
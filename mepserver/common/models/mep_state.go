@@ -0,0 +1,48 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package path implements mep server object models
+package models
+
+import "github.com/apache/servicecomb-service-center/server/core/proto"
+
+// Versioned snapshot of the mep state for the backup and migration
+type MepStateSnapshot struct {
+	Version    int               `json:"version" validate:"required"`
+	CreateTime int64             `json:"createTime"`
+	Records    []MepStateRecord  `json:"records" validate:"dive"`
+	Services   []MepStateService `json:"services" validate:"dive"`
+}
+
+// Data-store record, key is relative to the mep root path
+type MepStateRecord struct {
+	Key   string `json:"key" validate:"required"`
+	Value []byte `json:"value"`
+}
+
+// Service registered by the apps along with its instances
+type MepStateService struct {
+	Service   *proto.MicroService           `json:"service" validate:"required"`
+	Instances []*proto.MicroServiceInstance `json:"instances"`
+}
+
+// Restored state, AppD configurations are re-programmed by the listed tasks
+type MepStateImportResult struct {
+	Records   int            `json:"records"`
+	Services  int            `json:"services"`
+	Instances int            `json:"instances"`
+	Tasks     []TaskProgress `json:"tasks"`
+}
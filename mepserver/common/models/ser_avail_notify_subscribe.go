@@ -17,13 +17,37 @@
 // Package path implements mep server object models
 package models
 
+import "time"
+
 // This type represents a subscription to the notifications from the  MEC platform regarding the availability of a MEC service or a list of MEC services.
 type SerAvailabilityNotificationSubscription struct {
-	SubscriptionId    string            `json:"subscriptionId,omitempty"`
-	SubscriptionType  string            `json:"subscriptionType" validate:"required,oneof=AppTerminationNotificationSubscription SerAvailabilityNotificationSubscription"`
-	CallbackReference string            `json:"callbackReference" validate:"required,uri"`
-	Links             Links             `json:"_links" validate:"required"`
-	FilteringCriteria FilteringCriteria `json:"filteringCriteria,omitempty"`
+	SubscriptionId     string              `json:"subscriptionId,omitempty"`
+	SubscriptionType   string              `json:"subscriptionType" validate:"required,oneof=AppTerminationNotificationSubscription SerAvailabilityNotificationSubscription"`
+	CallbackReference  string              `json:"callbackReference,omitempty" validate:"required_without=WebsockNotifConfig,omitempty,uri"`
+	WebsockNotifConfig *WebsockNotifConfig `json:"websockNotifConfig,omitempty"`
+	Links              Links               `json:"_links" validate:"required"`
+	FilteringCriteria  FilteringCriteria   `json:"filteringCriteria,omitempty"`
+	ExpiryDeadline     *TimeStamp          `json:"expiryDeadline,omitempty"`
+}
+
+// This type represents the configuration of the websocket delivery of the notifications, the app requests a
+// websocket and the MEC platform returns the uri the app connects to.
+type WebsockNotifConfig struct {
+	WebsocketUri        string `json:"websocketUri,omitempty"`
+	RequestWebsocketUri bool   `json:"requestWebsocketUri,omitempty"`
+}
+
+// Uri the notifications are sent to, the websocket takes the precedence over the callback
+func (c *WebsockNotifConfig) notificationUri(callbackReference string) string {
+	if c != nil && len(c.WebsocketUri) != 0 {
+		return c.WebsocketUri
+	}
+	return callbackReference
+}
+
+// Uri the notifications of the subscription are sent to
+func (s *SerAvailabilityNotificationSubscription) NotificationUri() string {
+	return s.WebsockNotifConfig.notificationUri(s.CallbackReference)
 }
 
 type Links struct {
@@ -59,3 +83,33 @@ type ServiceReferences struct {
 type SerLinkType struct {
 	Href string `json:"href,omitempty"`
 }
+
+// Delivery state of the notifications of a subscription, subscription is SUSPENDED when the oldest pending
+// notification fails all the delivery attempts
+type NotificationDeliveryStatus struct {
+	SubscriptionId   string `json:"subscriptionId"`
+	State            string `json:"state"`
+	PendingCount     int    `json:"pendingCount"`
+	DeliveredCount   uint64 `json:"deliveredCount"`
+	FailedAttempts   int    `json:"failedAttempts"`
+	LastAttemptTime  int64  `json:"lastAttemptTime,omitempty"`
+	LastDeliveryTime int64  `json:"lastDeliveryTime,omitempty"`
+	LastError        string `json:"lastError,omitempty"`
+}
+
+// This type represents the information that the MEC platform notifies the subscribed application instance about
+// the upcoming expiry of its subscription.
+type ExpiryNotification struct {
+	NotificationType string                  `json:"notificationType"`
+	Links            ExpiryNotificationLinks `json:"_links"`
+	ExpiryDeadline   TimeStamp               `json:"expiryDeadline"`
+}
+
+type ExpiryNotificationLinks struct {
+	Subscription SerLinkType `json:"subscription"`
+}
+
+// Time of the time stamp
+func (t *TimeStamp) Time() time.Time {
+	return time.Unix(int64(t.Seconds), int64(t.Nanoseconds))
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package path implements mep server object models
+package models
+
+// Current time of the platform along with its traceability to the time source
+type CurrentTime struct {
+	Seconds          uint32 `json:"seconds"`
+	NanoSeconds      uint32 `json:"nanoSeconds"`
+	TimeSourceStatus string `json:"timeSourceStatus"`
+}
+
+// Timing capabilities of the platform, the NTP servers or the PTP masters as per the time source
+type TimingCaps struct {
+	TimeStamp  TimeStamp   `json:"timeStamp"`
+	NtpServers []NtpServer `json:"ntpServers,omitempty"`
+	PtpMasters []PtpMaster `json:"ptpMasters,omitempty"`
+}
+
+type NtpServer struct {
+	NtpServerAddrType    string `json:"ntpServerAddrType"`
+	NtpServerAddr        string `json:"ntpServerAddr"`
+	MinPollingInterval   int    `json:"minPollingInterval,omitempty"`
+	MaxPollingInterval   int    `json:"maxPollingInterval,omitempty"`
+	LocalPriority        int    `json:"localPriority,omitempty"`
+	AuthenticationOption string `json:"authenticationOption,omitempty"`
+	AuthenticationKeyNum int    `json:"authenticationKeyNum,omitempty"`
+}
+
+type PtpMaster struct {
+	PtpMasterIpAddress     string `json:"ptpMasterIpAddress"`
+	PtpMasterLocalPriority int    `json:"ptpMasterLocalPriority,omitempty"`
+	DelayReqMaxRate        int    `json:"delayReqMaxRate,omitempty"`
+}
@@ -0,0 +1,238 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package path implements mep server api plans
+package common
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apache/servicecomb-service-center/pkg/log"
+
+	"mepserver/common/arch/workspace"
+	"mepserver/common/util"
+)
+
+// Cursor based pagination and attribute selection of a collection response, as per ETSI GS MEC009.
+// limit is the page size and next_page the cursor from the Link header of the previous page. Paged items are ordered
+// by the id attribute and the cursor is the id of the last item returned. fields and exclude_fields are comma
+// separated top level attributes to include in or exclude from every item. Response is left as it is when none of
+// these parameters are given.
+type ResponsePage struct {
+	// Id attribute of the items, path of the item list within the response, empty if the response is the list
+	IdAttribute string
+	ListPath    []string
+}
+
+type pageQuery struct {
+	limit         int
+	cursor        string
+	fields        []string
+	excludeFields []string
+}
+
+// Page of the response selected by the request, the Link header of the next page is set if any item is left
+func (p *ResponsePage) apply(r *http.Request, w http.ResponseWriter, rsp interface{}) (interface{},
+	*workspace.SerErrInfo) {
+	query := r.URL.Query()
+	params, err := parsePageQuery(query)
+	if err != nil {
+		log.Error("invalid pagination parameters", err)
+		return nil, &workspace.SerErrInfo{ErrCode: util.RequestParamErr, Message: err.Error()}
+	}
+	if params == nil {
+		return rsp, nil
+	}
+
+	doc, err := toGenericJSON(rsp)
+	if err != nil {
+		log.Error("response convert to json failed", nil)
+		return nil, &workspace.SerErrInfo{ErrCode: util.ParseInfoErr, Message: "response convert to json failed"}
+	}
+	items, ok := listAt(doc, p.ListPath).([]interface{})
+	if !ok {
+		// Nothing to page in an empty response
+		return rsp, nil
+	}
+
+	next := ""
+	if params.limit != 0 || len(params.cursor) != 0 {
+		items, next = p.page(items, params)
+	}
+	for _, item := range items {
+		selectFields(item, params)
+	}
+	doc = setListAt(doc, p.ListPath, items)
+
+	if len(next) != 0 {
+		query.Set("next_page", next)
+		w.Header().Set("Link", fmt.Sprintf("<%s?%s>; rel=\"next\"", r.URL.Path, encodeLinkQuery(query)))
+	}
+	return doc, nil
+}
+
+// Limit and cursor of the page requested, so that a plan reads only the items after the cursor from the data-store.
+// Zero limit when no limit is requested, an invalid query is left to the response paging to report.
+func PageRange(r *http.Request) (int, string) {
+	params, err := parsePageQuery(r.URL.Query())
+	if err != nil || params == nil {
+		return 0, ""
+	}
+	return params.limit, params.cursor
+}
+
+func parsePageQuery(query url.Values) (*pageQuery, error) {
+	params := &pageQuery{cursor: query.Get("next_page")}
+	if limit := query.Get("limit"); len(limit) != 0 {
+		var err error
+		params.limit, err = strconv.Atoi(limit)
+		if err != nil || params.limit < 1 || params.limit > util.MaxPageLimit {
+			return nil, fmt.Errorf("limit must be between 1 and %d", util.MaxPageLimit)
+		}
+	}
+	if len(params.cursor) != 0 {
+		cursor, err := base64.RawURLEncoding.DecodeString(params.cursor)
+		if err != nil {
+			return nil, errors.New("invalid next_page cursor")
+		}
+		params.cursor = string(cursor)
+	}
+	params.fields = splitAttributes(query.Get("fields"))
+	params.excludeFields = splitAttributes(query.Get("exclude_fields"))
+	if len(params.fields) != 0 && len(params.excludeFields) != 0 {
+		return nil, errors.New("fields and exclude_fields are mutually exclusive")
+	}
+	if params.limit == 0 && len(params.cursor) == 0 && len(params.fields) == 0 && len(params.excludeFields) == 0 {
+		return nil, nil
+	}
+	return params, nil
+}
+
+func splitAttributes(value string) []string {
+	var attributes []string
+	for _, attribute := range strings.Split(value, ",") {
+		if attribute = strings.TrimSpace(attribute); len(attribute) != 0 {
+			attributes = append(attributes, attribute)
+		}
+	}
+	return attributes
+}
+
+// Items after the cursor up to the limit, along with the cursor of the next page if any item is left
+func (p *ResponsePage) page(items []interface{}, params *pageQuery) ([]interface{}, string) {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		if attributes, ok := item.(map[string]interface{}); ok {
+			ids[i] = fmt.Sprint(attributes[p.IdAttribute])
+		}
+	}
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return ids[order[i]] < ids[order[j]]
+	})
+
+	start := 0
+	if len(params.cursor) != 0 {
+		start = sort.Search(len(order), func(i int) bool {
+			return ids[order[i]] > params.cursor
+		})
+	}
+	end := len(order)
+	if params.limit != 0 && start+params.limit < end {
+		end = start + params.limit
+	}
+
+	paged := make([]interface{}, 0, end-start)
+	for _, i := range order[start:end] {
+		paged = append(paged, items[i])
+	}
+	if end == len(order) {
+		return paged, ""
+	}
+	return paged, base64.RawURLEncoding.EncodeToString([]byte(ids[order[end-1]]))
+}
+
+func selectFields(item interface{}, params *pageQuery) {
+	attributes, ok := item.(map[string]interface{})
+	if !ok {
+		return
+	}
+	if len(params.fields) != 0 {
+		for attribute := range attributes {
+			if util.StringContains(params.fields, attribute) == -1 {
+				delete(attributes, attribute)
+			}
+		}
+	}
+	for _, attribute := range params.excludeFields {
+		delete(attributes, attribute)
+	}
+}
+
+// Response as generic json values, numbers are kept as they are
+func toGenericJSON(rsp interface{}) (interface{}, error) {
+	rspBytes, err := json.Marshal(rsp)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(rspBytes))
+	decoder.UseNumber()
+	var doc interface{}
+	err = decoder.Decode(&doc)
+	return doc, err
+}
+
+func listAt(doc interface{}, listPath []string) interface{} {
+	for _, name := range listPath {
+		object, ok := doc.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		doc = object[name]
+	}
+	return doc
+}
+
+func setListAt(doc interface{}, listPath []string, items []interface{}) interface{} {
+	if len(listPath) == 0 {
+		return items
+	}
+	parent, _ := listAt(doc, listPath[:len(listPath)-1]).(map[string]interface{})
+	parent[listPath[len(listPath)-1]] = items
+	return doc
+}
+
+// Query of the next page link without the path parameters added by the router
+func encodeLinkQuery(query url.Values) string {
+	for name := range query {
+		if strings.HasPrefix(name, ":") {
+			query.Del(name)
+		}
+	}
+	return query.Encode()
+}
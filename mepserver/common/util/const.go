@@ -44,6 +44,9 @@ const (
 	ServiceInactive                               = 18
 	DuplicateOperation                            = 19
 	ForbiddenOperation                            = 20
+	TaskFinishedErr                               = 21
+	TerminationNotInProgressErr                   = 22
+	RecordModifiedErr                             = 23
 )
 
 const (
@@ -57,14 +60,27 @@ const (
 	AppServicesPath     = RootPath + MecServicePath + "/applications/:appInstanceId" + "/services"
 	AppSubscribePath    = RootPath + MecServicePath + "/applications/:appInstanceId/subscriptions"
 	ServicesPath        = RootPath + MecServicePath + "/services"
+	TransportsPath      = RootPath + MecServicePath + "/transports"
+	CurrentTimePath     = RootPath + MecAppSupportPath + "/timing/current_time"
+	TimingCapsPath      = RootPath + MecAppSupportPath + "/timing/timing_caps"
 	EndAppSubscribePath = RootPath + MecAppSupportPath + "/applications/:appInstanceId/subscriptions"
 	DNSRulesPath        = RootPath + MecAppSupportPath + "/applications/:appInstanceId/dns_rules"
 	TrafficRulesPath    = RootPath + MecAppSupportPath + "/applications/:appInstanceId/traffic_rules"
 
-	CapabilityPath        = Mm5RootPath + MecPlatformConfigPath + "/capabilities"
-	AppDConfigPath        = Mm5RootPath + MecAppDConfigPath + "/applications/:appInstanceId/appd_configuration"
-	AppDQueryResPath      = Mm5RootPath + MecAppDConfigPath + "/tasks/:taskId/appd_configuration"
-	AppInsTerminationPath = RootPath + MecAppSupportPath + "/applications/:appInstanceId/AppInstanceTermination"
+	CapabilityPath            = Mm5RootPath + MecPlatformConfigPath + "/capabilities"
+	AppDConfigPath            = Mm5RootPath + MecAppDConfigPath + "/applications/:appInstanceId/appd_configuration"
+	AppDQueryResPath          = Mm5RootPath + MecAppDConfigPath + "/tasks/:taskId/appd_configuration"
+	AppDTaskListPath          = Mm5RootPath + MecAppDConfigPath + "/tasks"
+	AppDTaskQueuePath         = AppDTaskListPath + "/queue"
+	AppDRuleOperationsPath    = AppDConfigPath + "/rule_operations"
+	AppDConfigVersionsPath    = AppDConfigPath + "/versions"
+	AppDConfigVersionDiffPath = AppDConfigVersionsPath + "/diff"
+	AppDConfigRollbackPath    = AppDConfigPath + "/rollback"
+	MepStatePath              = Mm5RootPath + MecPlatformConfigPath + "/state"
+	AppInsTerminationPath     = RootPath + MecAppSupportPath + "/applications/:appInstanceId/AppInstanceTermination"
+	ConfirmTerminationPath    = RootPath + MecAppSupportPath + "/applications/:appInstanceId/confirm_termination"
+	DeliveryStatusPath        = "/delivery_status"
+	WebsocketPath             = "/websocket"
 
 	DNSRuleIdPath      = "/:dnsRuleId"
 	TrafficRuleIdPath  = "/:trafficRuleId"
@@ -87,12 +103,16 @@ const (
 
 const DBRootPath = "/cse-sr/etsi/"
 const (
-	EndAppSubKeyPath      = DBRootPath + "app-end-subscribe/"
-	AvailAppSubKeyPath    = DBRootPath + "subscribe/"
-	AppDConfigKeyPath     = DBRootPath + "appd/"
-	AppDLCMJobsPath       = DBRootPath + "mep/applcm/jobs/"
-	AppDLCMTasksPath      = DBRootPath + "mep/applcm/tasks/"
-	AppDLCMTaskStatusPath = DBRootPath + "mep/applcm/taskstatus/"
+	EndAppSubKeyPath       = DBRootPath + "app-end-subscribe/"
+	AvailAppSubKeyPath     = DBRootPath + "subscribe/"
+	AppDConfigKeyPath      = DBRootPath + "appd/"
+	AppDLCMJobsPath        = DBRootPath + "mep/applcm/jobs/"
+	AppDLCMTasksPath       = DBRootPath + "mep/applcm/tasks/"
+	AppDLCMTaskStatusPath  = DBRootPath + "mep/applcm/taskstatus/"
+	AppDConfigHistoryPath  = DBRootPath + "appdhistory/"
+	AppTerminationKeyPath  = DBRootPath + "mep/appterminate/"
+	NotificationOutboxPath = DBRootPath + "mep/notification/outbox/"
+	NotificationStatusPath = DBRootPath + "mep/notification/status/"
 )
 
 const (
@@ -108,6 +128,55 @@ const FormatIntBase = 10
 
 const SerAvailabilityNotificationSubscription string = "SerAvailabilityNotificationSubscription"
 const AppTerminationNotificationSubscription string = "AppTerminationNotificationSubscription"
+const AppTerminationNotification string = "AppTerminationNotification"
+const ExpiryNotification string = "ExpiryNotification"
+
+// Kind of the service change in the service availability notification
+const (
+	ChangeTypeAdded             = "ADDED"
+	ChangeTypeRemoved           = "REMOVED"
+	ChangeTypeStateChanged      = "STATE_CHANGED"
+	ChangeTypeAttributesChanged = "ATTRIBUTES_CHANGED"
+)
+
+// Subscriptions with an expiry deadline are swept at the interval, the expiry notification is sent the notice time
+// before the deadline. Both are in seconds.
+const (
+	SubscriptionExpirySweepInterval = 10
+	SubscriptionExpiryNoticeTime    = 60
+)
+
+// App termination operation actions
+const (
+	OperationActionStopping    = "STOPPING"
+	OperationActionTerminating = "TERMINATING"
+)
+
+// Graceful timeout in seconds the app gets to confirm the termination, and the confirmation poll interval
+const (
+	DefaultGracefulTimeout         = 10
+	TerminationConfirmPollInterval = 500 * time.Millisecond
+)
+
+// Notification delivery retries with exponential backoff in seconds, the subscription is suspended once the oldest
+// pending notification fails the max attempts. Outbox keeps the latest notifications of a subscription up to the limit.
+const (
+	NotificationRetryInterval    = 1
+	NotificationMaxRetryInterval = 60
+	NotificationMaxAttempts      = 8
+	NotificationTimeout          = 5
+	NotificationOutboxLimit      = 100
+)
+
+// Notification websocket liveness in seconds, the app must answer the pings sent at the interval within the pong wait
+const (
+	WebsocketPingInterval = 30
+	WebsocketPongWait     = 60
+	WebsocketWriteTimeout = 5
+)
+
+// Pagination of the mp1 collections, the page size requested by the limit query parameter is capped at the max
+const MaxPageLimit = 500
 
 const RequestBodyLength = 4096
 const ServicesMaxCount = 50
@@ -150,6 +219,8 @@ const maxHostNameLen = 253
 const (
 	DefaultDnsHost           = "localhost"
 	DefaultDnsManagementPort = 8080
+	DefaultDnsPort           = 53
+	DefaultDnsUpdateTimeout  = 5
 )
 
 const (
@@ -173,6 +244,35 @@ const (
 	DnsAgentTypeLocal     = "local"
 	DnsAgentTypeDataPlane = "dataplane"
 	DnsAgentTypeAll       = "all"
+	DnsAgentTypeRFC2136   = "rfc2136"
+)
+
+// Time source options of the platform clock
+const (
+	TimeSourceNTP = "ntp"
+	TimeSourcePTP = "ptp"
+)
+
+// Traceability of the platform clock to its time source
+const (
+	TimeSourceTraceable    = "TRACEABLE"
+	TimeSourceNonTraceable = "NONTRACEABLE"
+)
+
+// Clock offset from the time source in microseconds, above which the clock is not traceable
+const DefaultTimingMaxOffset = 1000
+
+// Time in seconds to read the clock status from its local source
+const TimingStatusTimeout = 5
+
+// Interval in seconds to read the clock status from its local source, the timing api reports the last status read
+const DefaultTimingPollInterval = 10
+
+// TSIG algorithm options for the rfc2136 dns agent
+const (
+	TsigHmacSHA1   = "hmac-sha1"
+	TsigHmacSHA256 = "hmac-sha256"
+	TsigHmacSHA512 = "hmac-sha512"
 )
 
 type AppDRuleType int
@@ -191,6 +291,20 @@ const (
 	OperDelete                 // Operation type delete
 )
 
+// Operation type names used in the responses
+var OperTypeNames = map[OperType]string{
+	OperCreate: "CREATE",
+	OperModify: "MODIFY",
+	OperDelete: "DELETE",
+}
+
+// Rule level operations on an AppD configuration
+const (
+	RuleOperationAdd     = "add"
+	RuleOperationReplace = "replace"
+	RuleOperationRemove  = "remove"
+)
+
 // AppD rule state machine
 type AppDRuleStatus int
 
@@ -210,11 +324,63 @@ const (
 )
 
 const TaskProgressFailure = -1
+const TaskProgressCancelled = -2
 
 const (
 	TASK_STATE_SUCCESS    = "SUCCESS"
 	TASK_STATE_PROCESSING = "PROCESSING"
 	TASK_STATE_FAILURE    = "FAILURE"
+	TASK_STATE_CANCELLED  = "CANCELLED"
+)
+
+// Task list paging
+const (
+	DefaultTaskListLimit = 100
+	MaxTaskListLimit     = 1000
+)
+
+// Capability list paging and the optional data included in the capabilities
+const (
+	DefaultCapabilityListLimit     = 100
+	MaxCapabilityListLimit         = 1000
+	CapabilityIncludeMetadata      = "metadata"
+	CapabilityIncludeTransportInfo = "transportInfo"
+	CapabilityIncludeLiveness      = "liveness"
+	CapabilityIncludeDataPlane     = "dataplane"
+	TotalCountHeader               = "X-Total-Count"
+)
+
+// Capability id of the data-plane in the platform capabilities
+const DataPlaneCapabilityId = "dataplane"
+
+// Task status retention defaults, period in hours and purge interval in minutes
+const DefaultTaskPurgeInterval = 60
+
+// Number of applied AppD configuration versions kept for each app instance
+const MaxAppDConfigVersions = 10
+
+// Number of workers processing the appd configuration tasks, if not configured
+const DefaultWorkerPoolSize = 8
+
+// Mep state snapshot format version and the max size of the snapshot on import
+const (
+	MepStateSnapshotVersion   = 1
+	MaxMepStateSnapshotLength = 64 * 1024 * 1024
+)
+
+// Task completion callback delivery, the retry interval is doubled on every retry
+const (
+	TaskCallbackMaxAttempts   = 5
+	TaskCallbackRetryInterval = 2  // in seconds
+	TaskCallbackTimeout       = 10 // in seconds
+	MaxCallbackUriLength      = 2048
+	TaskCallbackCaCert        = "mepm_cacert"
+)
+
+// Outcome of the unfinished tasks recovered on mep-server restart
+const (
+	TaskRecoveryResumed    = "RESUMED"
+	TaskRecoveryRolledBack = "ROLLED_BACK"
 )
 
 const (
@@ -167,15 +167,21 @@ func GetServiceInstance(ctx context.Context, serviceId string) (*proto.MicroServ
 	return instance, err
 }
 
+// Instances are stored as <instance key path><service id>/<instance id>
+const instanceKeyPath = "/cse-sr/inst/files///"
+
 // get instance by key
 func FindInstanceByKey(result url.Values) (*proto.FindInstancesResponse, error) {
-	serCategoryId := result.Get("ser_category_id")
-	scopeOfLocality := result.Get("scope_of_locality")
-	consumedLocalOnly := result.Get("consumed_local_only")
-	isLocal := result.Get("is_local")
-	isQueryAllSvc := serCategoryId == "" && scopeOfLocality == "" && consumedLocalOnly == "" && isLocal == ""
+	filter := &ServiceFilter{
+		ScopeOfLocality:   result.Get("scope_of_locality"),
+		ConsumedLocalOnly: result.Get("consumed_local_only"),
+		IsLocal:           result.Get("is_local"),
+	}
+	if serCategoryId := result.Get("ser_category_id"); len(serCategoryId) != 0 {
+		filter.SerCategoryIds = []string{serCategoryId}
+	}
 	opts := []registry.PluginOp{
-		registry.OpGet(registry.WithStrKey("/cse-sr/inst/files///"), registry.WithPrefix()),
+		registry.OpGet(registry.WithStrKey(instanceKeyPath), registry.WithPrefix()),
 	}
 	resp, err := backend.Registry().TxnWithCmp(context.Background(), opts, nil, nil)
 	if err != nil {
@@ -183,31 +189,11 @@ func FindInstanceByKey(result url.Values) (*proto.FindInstancesResponse, error)
 	}
 	var findResp []*proto.MicroServiceInstance
 	for _, value := range resp.Kvs {
-		var instance map[string]interface{}
-		err = json.Unmarshal(value.Value, &instance)
-		if err != nil {
-			return nil, fmt.Errorf("string convert to instance failed")
-		}
-		dci := &proto.DataCenterInfo{Name: "", Region: "", AvailableZone: ""}
-		instance["datacenterinfo"] = dci
-		message, err := json.Marshal(&instance)
-		if err != nil {
-			log.Errorf(nil, "instance convert to string failed")
-			return nil, err
-		}
-		var ins *proto.MicroServiceInstance
-		err = json.Unmarshal(message, &ins)
+		ins, err := toMicroServiceInstance(value.Value)
 		if err != nil {
-			log.Errorf(nil, "String convert to MicroServiceInstance failed!")
 			return nil, err
 		}
-		property := ins.Properties
-		if isQueryAllSvc && property != nil {
-			findResp = append(findResp, ins)
-		} else if strings.EqualFold(property["serCategory/id"], serCategoryId) ||
-			strings.EqualFold(property["ConsumedLocalOnly"], consumedLocalOnly) ||
-			strings.EqualFold(property["ScopeOfLocality"], scopeOfLocality) ||
-			strings.EqualFold(property["IsLocal"], isLocal) {
+		if filter.Match(ins) {
 			findResp = append(findResp, ins)
 		}
 	}
@@ -219,6 +205,79 @@ func FindInstanceByKey(result url.Values) (*proto.FindInstancesResponse, error)
 	return ret, nil
 }
 
+// Instances after the given service instance id in the id order, at most limit instances accepted by the match. The
+// instances are read from the data-store in batches of the limit, till the limit is reached. Service ids are of the
+// same length, hence the key order is the service instance id order.
+func FindInstancesPage(afterSerInstanceId string, limit int,
+	match func(*proto.MicroServiceInstance) bool) ([]*proto.MicroServiceInstance, error) {
+	start := instanceKeyPath
+	if len(afterSerInstanceId) != 0 {
+		serviceId := afterSerInstanceId[:len(afterSerInstanceId)/2]
+		start = instanceKeyPath + serviceId + "/" + afterSerInstanceId[len(serviceId):] + "\x00"
+	}
+	end := PrefixRangeEnd(instanceKeyPath)
+
+	instances := make([]*proto.MicroServiceInstance, 0, limit)
+	for {
+		resp, err := backend.Registry().Do(context.Background(), registry.GET, registry.WithStrKey(start),
+			registry.WithStrEndKey(end), registry.WithAscendOrder(), registry.WithLimit(int64(limit)))
+		if err != nil {
+			return nil, fmt.Errorf("query from etch error")
+		}
+		for _, value := range resp.Kvs {
+			ins, err := toMicroServiceInstance(value.Value)
+			if err != nil {
+				return nil, err
+			}
+			if match(ins) {
+				instances = append(instances, ins)
+				if len(instances) == limit {
+					return instances, nil
+				}
+			}
+		}
+		if len(resp.Kvs) < limit {
+			return instances, nil
+		}
+		// Next batch starts after the last key read
+		start = string(resp.Kvs[len(resp.Kvs)-1].Key) + "\x00"
+	}
+}
+
+// Smallest key after all the keys with the given prefix, the end of the prefix range read
+func PrefixRangeEnd(prefix string) string {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return string(end[:i+1])
+		}
+	}
+	return "\x00"
+}
+
+func toMicroServiceInstance(value []byte) (*proto.MicroServiceInstance, error) {
+	var instance map[string]interface{}
+	err := json.Unmarshal(value, &instance)
+	if err != nil {
+		return nil, fmt.Errorf("string convert to instance failed")
+	}
+	dci := &proto.DataCenterInfo{Name: "", Region: "", AvailableZone: ""}
+	instance["datacenterinfo"] = dci
+	message, err := json.Marshal(&instance)
+	if err != nil {
+		log.Errorf(nil, "instance convert to string failed")
+		return nil, err
+	}
+	var ins *proto.MicroServiceInstance
+	err = json.Unmarshal(message, &ins)
+	if err != nil {
+		log.Errorf(nil, "String convert to MicroServiceInstance failed!")
+		return nil, err
+	}
+	return ins, nil
+}
+
 // set map value
 func SetMapValue(theMap map[string]interface{}, key string, val interface{}) {
 	mapValue, ok := theMap[key]
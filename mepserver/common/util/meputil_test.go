@@ -0,0 +1,110 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/apache/servicecomb-service-center/server/core/backend"
+	"github.com/apache/servicecomb-service-center/server/core/proto"
+	"github.com/apache/servicecomb-service-center/server/plugin/pkg/registry"
+	"github.com/coreos/etcd/mvcc/mvccpb"
+	"github.com/stretchr/testify/assert"
+)
+
+// Registry serving the range reads from an in memory key set, other operations are not expected
+type rangeRegistry struct {
+	registry.Registry
+	records map[string]string
+	reads   int
+}
+
+func (r *rangeRegistry) Do(ctx context.Context, opts ...registry.PluginOpOption) (*registry.PluginResponse, error) {
+	op := registry.OptionsToOp(opts...)
+	r.reads++
+	keys := make([]string, 0, len(r.records))
+	for key := range r.records {
+		if key >= string(op.Key) && key < string(op.EndKey) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	if int64(len(keys)) > op.Limit {
+		keys = keys[:op.Limit]
+	}
+	resp := &registry.PluginResponse{Action: registry.Get}
+	for _, key := range keys {
+		resp.Kvs = append(resp.Kvs, &mvccpb.KeyValue{Key: []byte(key), Value: []byte(r.records[key])})
+	}
+	return resp, nil
+}
+
+var pageRegistry *rangeRegistry
+
+func getPageRegistry() registry.Registry {
+	return pageRegistry
+}
+
+func instancePageRecord(serviceId, instanceId, appInstanceId string) (string, string) {
+	return instanceKeyPath + serviceId + "/" + instanceId, `{"serviceId":"` + serviceId + `","instanceId":"` +
+		instanceId + `","properties":{"appInstanceId":"` + appInstanceId + `"}}`
+}
+
+func matchPageAppInstance(instance *proto.MicroServiceInstance) bool {
+	return instance.Properties["appInstanceId"] == "5abe4782-2c70-4e47-9a4e-0ee3a1a0fd1f"
+}
+
+func TestFindInstancesPage(t *testing.T) {
+	pageRegistry = &rangeRegistry{records: make(map[string]string)}
+	for _, record := range [][]string{
+		{"a1000000000000a1", "b1000000000000b1", "5abe4782-2c70-4e47-9a4e-0ee3a1a0fd1f"},
+		{"a1000000000000a1", "b2000000000000b2", "f7e898d1-4ad6-4dbc-9d93-5bbd1e8e2b9b"},
+		{"a1000000000000a1", "b3000000000000b3", "f7e898d1-4ad6-4dbc-9d93-5bbd1e8e2b9b"},
+		{"a2000000000000a2", "b4000000000000b4", "5abe4782-2c70-4e47-9a4e-0ee3a1a0fd1f"},
+		{"a3000000000000a3", "b5000000000000b5", "5abe4782-2c70-4e47-9a4e-0ee3a1a0fd1f"},
+	} {
+		key, value := instancePageRecord(record[0], record[1], record[2])
+		pageRegistry.records[key] = value
+	}
+	// Keys outside the instance range are never read
+	pageRegistry.records["/cse-sr/ms/files///a1000000000000a1"] = "{}"
+	patches := gomonkey.ApplyFunc(backend.Registry, getPageRegistry)
+	defer patches.Reset()
+
+	instances, err := FindInstancesPage("", 2, matchPageAppInstance)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(instances))
+	assert.Equal(t, "b1000000000000b1", instances[0].InstanceId)
+	assert.Equal(t, "b4000000000000b4", instances[1].InstanceId)
+	assert.Equal(t, 2, pageRegistry.reads, "second batch read once the first has non matching instances")
+
+	pageRegistry.reads = 0
+	instances, err = FindInstancesPage("a2000000000000a2b4000000000000b4", 2, matchPageAppInstance)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(instances))
+	assert.Equal(t, "b5000000000000b5", instances[0].InstanceId)
+	assert.Equal(t, 1, pageRegistry.reads, "short batch is the end of the range")
+}
+
+func TestPrefixRangeEnd(t *testing.T) {
+	assert.Equal(t, "/cse-sr/inst/files//0", PrefixRangeEnd(instanceKeyPath))
+	assert.Equal(t, "b", PrefixRangeEnd("a\xff"))
+	assert.Equal(t, "\x00", PrefixRangeEnd("\xff"))
+}
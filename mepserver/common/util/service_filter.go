@@ -0,0 +1,145 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/apache/servicecomb-service-center/server/core/proto"
+)
+
+// Service instance filter shared by the service availability subscriptions and the service discovery.
+// Instance ids, names and category ids are alternatives to select the services, Match uses the first non-empty one in
+// that order. The callers reject a filter with more than one of them. The selection is combined with the other
+// attributes using logical AND, and an empty attribute matches any service.
+type ServiceFilter struct {
+	SerInstanceIds    []string
+	SerNames          []string
+	SerCategoryIds    []string
+	States            []string
+	ScopeOfLocality   string
+	ConsumedLocalOnly string
+	IsLocal           string
+}
+
+// Scope of locality values of a service
+var scopesOfLocality = []string{"MEC_SYSTEM", "MEC_HOST", "NFVI_POP", "ZONE", "ZONE_GROUP", "NFVI_NODE"}
+
+// Service filter of the MEC011 service discovery query parameters. Lists are given either as comma separated values
+// or as repeated parameters. Service instance ids, names and category ids are mutually exclusive.
+func ServiceFilterFromQuery(query url.Values) (*ServiceFilter, error) {
+	filter := &ServiceFilter{
+		SerInstanceIds:  queryList(query, "ser_instance_id"),
+		SerNames:        queryList(query, "ser_name"),
+		SerCategoryIds:  queryList(query, "ser_category_id"),
+		ScopeOfLocality: query.Get("scope_of_locality"),
+	}
+	selections := 0
+	for _, selection := range [][]string{filter.SerInstanceIds, filter.SerNames, filter.SerCategoryIds} {
+		if len(selection) != 0 {
+			selections++
+		}
+	}
+	if selections > 1 {
+		return nil, errors.New("ser_instance_id, ser_name and ser_category_id are mutually exclusive")
+	}
+	if len(filter.ScopeOfLocality) != 0 && StringContains(scopesOfLocality, filter.ScopeOfLocality) == -1 {
+		return nil, fmt.Errorf("invalid scope_of_locality %s", filter.ScopeOfLocality)
+	}
+
+	var err error
+	if filter.ConsumedLocalOnly, err = queryBool(query, "consumed_local_only"); err != nil {
+		return nil, err
+	}
+	if filter.IsLocal, err = queryBool(query, "is_local"); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+func queryList(query url.Values, name string) []string {
+	var list []string
+	for _, value := range query[name] {
+		for _, entry := range strings.Split(value, ",") {
+			if entry = strings.TrimSpace(entry); len(entry) != 0 {
+				list = append(list, entry)
+			}
+		}
+	}
+	return list
+}
+
+// Boolean parameter in its canonical form, so that it compares with the instance properties
+func queryBool(query url.Values, name string) (string, error) {
+	value := query.Get(name)
+	if len(value) == 0 {
+		return "", nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return "", fmt.Errorf("%s must be a boolean", name)
+	}
+	return strconv.FormatBool(parsed), nil
+}
+
+// Match the service instance against the filter
+func (f *ServiceFilter) Match(instance *proto.MicroServiceInstance) bool {
+	if instance == nil || instance.Properties == nil {
+		return false
+	}
+	properties := instance.Properties
+
+	switch {
+	case len(f.SerInstanceIds) != 0:
+		if StringContains(f.SerInstanceIds, instance.ServiceId+instance.InstanceId) == -1 {
+			return false
+		}
+	case len(f.SerNames) != 0:
+		if StringContains(f.SerNames, properties["serName"]) == -1 {
+			return false
+		}
+	case len(f.SerCategoryIds) != 0:
+		if !containsFold(f.SerCategoryIds, properties["serCategory/id"]) {
+			return false
+		}
+	}
+
+	if len(f.States) != 0 && StringContains(f.States, properties["mecState"]) == -1 {
+		return false
+	}
+	return matchFold(f.ScopeOfLocality, properties["ScopeOfLocality"]) &&
+		matchFold(f.ConsumedLocalOnly, properties["ConsumedLocalOnly"]) &&
+		matchFold(f.IsLocal, properties["IsLocal"])
+}
+
+// Empty filter value matches any value
+func matchFold(filterValue, value string) bool {
+	return len(filterValue) == 0 || strings.EqualFold(filterValue, value)
+}
+
+func containsFold(arr []string, val string) bool {
+	for _, entry := range arr {
+		if strings.EqualFold(entry, val) {
+			return true
+		}
+	}
+	return false
+}
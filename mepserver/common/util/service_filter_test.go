@@ -0,0 +1,100 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/apache/servicecomb-service-center/server/core/proto"
+)
+
+func TestServiceFilterMatch(t *testing.T) {
+	instance := &proto.MicroServiceInstance{
+		ServiceId:  "c936bdb887337c15",
+		InstanceId: "a8612ca7603ad979",
+		Properties: map[string]string{
+			"serName":           "faceapp01",
+			"serCategory/id":    "RNI01",
+			"serCategory/name":  "RNI",
+			"mecState":          ActiveState,
+			"ScopeOfLocality":   "MEC_HOST",
+			"ConsumedLocalOnly": "false",
+			"IsLocal":           "true",
+		},
+	}
+
+	cases := []struct {
+		name   string
+		filter ServiceFilter
+		match  bool
+	}{
+		{"empty filter", ServiceFilter{}, true},
+		{"instance id", ServiceFilter{SerInstanceIds: []string{"c936bdb887337c15a8612ca7603ad979"}}, true},
+		{"other instance id", ServiceFilter{SerInstanceIds: []string{"c936bdb887337c15a8612ca7603ad970"}}, false},
+		{"name", ServiceFilter{SerNames: []string{"faceapp02", "faceapp01"}}, true},
+		{"category id only", ServiceFilter{SerCategoryIds: []string{"rni01"}}, true},
+		{"other category", ServiceFilter{SerCategoryIds: []string{"RNI02"}}, false},
+		// Instance ids take the precedence over the other alternatives
+		{"alternatives", ServiceFilter{SerInstanceIds: []string{"c936bdb887337c15a8612ca7603ad970"},
+			SerNames: []string{"faceapp01"}}, false},
+		{"name and state", ServiceFilter{SerNames: []string{"faceapp01"}, States: []string{ActiveState}}, true},
+		{"name and other state", ServiceFilter{SerNames: []string{"faceapp01"},
+			States: []string{InactiveState, SuspendedState}}, false},
+		{"local", ServiceFilter{IsLocal: "true"}, true},
+		{"not local", ServiceFilter{IsLocal: "false"}, false},
+		{"scope of locality", ServiceFilter{ScopeOfLocality: "MEC_HOST", ConsumedLocalOnly: "false"}, true},
+		{"other scope of locality", ServiceFilter{ScopeOfLocality: "ZONE"}, false},
+	}
+	for _, c := range cases {
+		if match := c.filter.Match(instance); match != c.match {
+			t.Errorf("%s: expected match %v, got %v", c.name, c.match, match)
+		}
+	}
+}
+
+func TestServiceFilterFromQuery(t *testing.T) {
+	query := url.Values{
+		"ser_name":            {"faceapp01,faceapp02", "faceapp03"},
+		"scope_of_locality":   {"MEC_HOST"},
+		"consumed_local_only": {"False"},
+		"is_local":            {"1"},
+	}
+	filter, err := ServiceFilterFromQuery(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := &ServiceFilter{SerNames: []string{"faceapp01", "faceapp02", "faceapp03"},
+		ScopeOfLocality: "MEC_HOST", ConsumedLocalOnly: "false", IsLocal: "true"}
+	if !reflect.DeepEqual(filter, expected) {
+		t.Errorf("expected filter %+v, got %+v", expected, filter)
+	}
+
+	invalid := []url.Values{
+		{"ser_name": {"faceapp01"}, "ser_category_id": {"RNI01"}},
+		{"ser_instance_id": {"c936bdb887337c15a8612ca7603ad979"}, "ser_name": {"faceapp01"}},
+		{"scope_of_locality": {"DATA_CENTER"}},
+		{"is_local": {"yes"}},
+		{"consumed_local_only": {"local"}},
+	}
+	for _, q := range invalid {
+		if _, err := ServiceFilterFromQuery(q); err == nil {
+			t.Errorf("expected error for query %v", q)
+		}
+	}
+}
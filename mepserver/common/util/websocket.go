@@ -0,0 +1,157 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package path implements mep server utility functions and constants
+package util
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/servicecomb-service-center/pkg/log"
+	"github.com/gorilla/websocket"
+)
+
+// Notification websocket of a subscription, pushes are serialized as the websocket allows a single writer
+type websocketSession struct {
+	mutex sync.Mutex
+	conn  *websocket.Conn
+}
+
+// Connected notification websockets keyed by the path of the websocket uri
+var websocketSessions = struct {
+	sync.RWMutex
+	sessions map[string]*websocketSession
+}{sessions: make(map[string]*websocketSession)}
+
+var websocketUpgrader = websocket.Upgrader{
+	HandshakeTimeout: WebsocketWriteTimeout * time.Second,
+	// Apps connect from anywhere, the access is authorized by the app instance id like any other mp1 request
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// Websocket uri of the subscription served by the mep-server, in the scheme of the request
+func WebsocketUri(r *http.Request, subscriptionPath string) string {
+	scheme := "ws"
+	if r.TLS != nil {
+		scheme = "wss"
+	}
+	return scheme + "://" + r.Host + subscriptionPath + WebsocketPath
+}
+
+// Whether the notifications are pushed over a websocket instead of the callback
+func IsWebsocketUri(uri string) bool {
+	return strings.HasPrefix(uri, "ws://") || strings.HasPrefix(uri, "wss://")
+}
+
+func websocketKey(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+	return u.Path
+}
+
+// Upgrade the request to the notification websocket and serve it till the app disconnects or stops answering the
+// pings. A new connection replaces the previous one of the subscription. onConnected is called once the websocket is
+// ready to push.
+func ServeWebsocket(w http.ResponseWriter, r *http.Request, onConnected func()) error {
+	conn, err := websocketUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return err
+	}
+	key := r.URL.Path
+	session := &websocketSession{conn: conn}
+	websocketSessions.Lock()
+	previous := websocketSessions.sessions[key]
+	websocketSessions.sessions[key] = session
+	websocketSessions.Unlock()
+	if previous != nil {
+		_ = previous.conn.Close()
+	}
+	log.Infof("Notification websocket(%s) connected", key)
+
+	defer func() {
+		websocketSessions.Lock()
+		if websocketSessions.sessions[key] == session {
+			delete(websocketSessions.sessions, key)
+		}
+		websocketSessions.Unlock()
+		_ = conn.Close()
+		log.Infof("Notification websocket(%s) disconnected", key)
+	}()
+
+	// The app must answer the pings within the pong wait, it sends nothing else
+	_ = conn.SetReadDeadline(time.Now().Add(WebsocketPongWait * time.Second))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(WebsocketPongWait * time.Second))
+	})
+	done := make(chan struct{})
+	defer close(done)
+	go pingWebsocket(conn, done)
+
+	if onConnected != nil {
+		onConnected()
+	}
+	for {
+		if _, _, err = conn.ReadMessage(); err != nil {
+			return nil
+		}
+	}
+}
+
+func pingWebsocket(conn *websocket.Conn, done chan struct{}) {
+	ticker := time.NewTicker(WebsocketPingInterval * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			deadline := time.Now().Add(WebsocketWriteTimeout * time.Second)
+			if err := conn.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+				_ = conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// Push the notification over the websocket of the subscription, fails if the app is not connected
+func PushWebsocketNotification(uri string, body []byte) error {
+	websocketSessions.RLock()
+	session := websocketSessions.sessions[websocketKey(uri)]
+	websocketSessions.RUnlock()
+	if session == nil {
+		return errors.New("notification websocket not connected")
+	}
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+	_ = session.conn.SetWriteDeadline(time.Now().Add(WebsocketWriteTimeout * time.Second))
+	return session.conn.WriteMessage(websocket.TextMessage, body)
+}
+
+// Send the notification to the subscriber, over its websocket or to its callback
+func SendNotification(uri string, body []byte) error {
+	if IsWebsocketUri(uri) {
+		return PushWebsocketNotification(uri, body)
+	}
+	return SendPostRequest(uri, body, true)
+}
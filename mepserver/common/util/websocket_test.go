@@ -0,0 +1,68 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestWebsocketNotification(t *testing.T) {
+	connected := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = ServeWebsocket(w, r, func() { close(connected) })
+	}))
+	defer server.Close()
+
+	uri := "ws" + strings.TrimPrefix(server.URL, "http") + "/mep/subscriptions/sub1" + WebsocketPath
+	if !IsWebsocketUri(uri) {
+		t.Fatalf("%s is not a websocket uri", uri)
+	}
+	if err := SendNotification(uri, []byte("{}")); err == nil {
+		t.Fatal("expected push failure without the websocket connection")
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(uri, nil)
+	if err != nil {
+		t.Fatalf("websocket dial failed: %v", err)
+	}
+	defer conn.Close()
+	select {
+	case <-connected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("websocket connection not served")
+	}
+
+	// Session is looked up by the path, irrespective of the host the app used
+	if err = SendNotification("wss://mep.example.com/mep/subscriptions/sub1"+WebsocketPath,
+		[]byte(`{"notificationType":"SerAvailabilityNotification"}`)); err != nil {
+		t.Fatalf("websocket push failed: %v", err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, message, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("websocket read failed: %v", err)
+	}
+	if string(message) != `{"notificationType":"SerAvailabilityNotification"}` {
+		t.Errorf("unexpected notification %s", message)
+	}
+}
@@ -13,11 +13,14 @@ require (
 	github.com/agiledragon/gomonkey v2.0.1+incompatible
 	github.com/apache/servicecomb-service-center v0.0.0-20191027084911-c2dc0caef706
 	github.com/astaxie/beego v1.12.0
+	github.com/coreos/etcd v3.3.6+incompatible
 	github.com/ghodss/yaml v1.0.0
 	github.com/go-chassis/paas-lager v1.1.1 // indirect
 	github.com/go-mesh/openlogging v1.0.1 // indirect
 	github.com/go-playground/validator/v10 v10.4.1
+	github.com/gorilla/websocket v1.4.1
 	github.com/grpc-ecosystem/go-grpc-middleware v1.2.0 // indirect
+	github.com/miekg/dns v1.1.29
 	github.com/satori/go.uuid v1.2.0
 	github.com/shiena/ansicolor v0.0.0-20200904210342-c7312218db18 // indirect
 	github.com/stretchr/testify v1.6.1
@@ -17,11 +17,14 @@
 package mm5
 
 import (
+	"context"
 	"fmt"
 	"github.com/apache/servicecomb-service-center/pkg/log"
 	"github.com/apache/servicecomb-service-center/pkg/rest"
+	"github.com/apache/servicecomb-service-center/pkg/util"
 	v4 "github.com/apache/servicecomb-service-center/server/rest/controller/v4"
 	"mepserver/common/config"
+	"mepserver/common/extif/dataplane"
 	dpCommon "mepserver/common/extif/dataplane/common"
 	"mepserver/common/extif/dns"
 	"mepserver/common/models"
@@ -50,8 +53,9 @@ func initMm5Router() {
 
 type Mm5Service struct {
 	v4.MicroServiceService
-	config    *config.MepServerConfig
-	mp2Worker task.Worker
+	config         *config.MepServerConfig
+	mp2Worker      task.Worker
+	dpCapabilities *dataplane.Capabilities
 }
 
 func (m *Mm5Service) Init() error {
@@ -61,10 +65,11 @@ func (m *Mm5Service) Init() error {
 	}
 	m.config = mepConfig
 
-	// Checking if local or both is configured
-	var dnsAgent dns.DNSAgent
-	if m.config.DNSAgent.Type != meputil.DnsAgentTypeDataPlane {
-		dnsAgent = dns.NewRestDNSAgent(mepConfig)
+	// Select the dns agent as per configuration, no agent for data-plane only type
+	dnsAgent, err := dns.CreateDNSAgent(mepConfig)
+	if err != nil {
+		log.Errorf(err, "Dns agent creation failed.")
+		return fmt.Errorf("error: unsupported dns agent")
 	}
 
 	// select data plane as per configuration
@@ -77,9 +82,24 @@ func (m *Mm5Service) Init() error {
 		return err
 	}
 
+	// Cache the data-plane capabilities, rule requests are validated against it
+	m.dpCapabilities, err = dataPlane.GetCapabilities()
+	if err != nil {
+		return fmt.Errorf("error: data-plane capability query failed: %w", err)
+	}
+
 	log.Infof("Data plane initialized to %s", m.config.DataPlane.Type)
 
-	m.mp2Worker.InitializeWorker(dataPlane, dnsAgent, m.config.DNSAgent.Type)
+	m.mp2Worker.InitializeWorker(dataPlane, dnsAgent, m.config.DNSAgent.Type).WithPoolSize(m.config.WorkerPool.Size)
+
+	// Tasks interrupted by a previous restart are continued in the background once the data-store is ready
+	go m.mp2Worker.RecoverUnfinishedTasks()
+
+	// Terminations interrupted by a previous restart wait for the rest of their graceful timeout
+	go plans.ResumeAppTerminations(m.teardownAppInstance)
+
+	// Finished task status records are purged in the background as per the retention policy
+	go task.PurgeFinishedTasks(m.config.TaskRetention)
 
 	return nil
 }
@@ -100,6 +120,27 @@ func (m *Mm5Service) URLPatterns() []rest.Route {
 
 		// App Termination
 		{Method: rest.HTTP_METHOD_DELETE, Path: meputil.AppInsTerminationPath, Func: m.terminateAppInstance},
+
+		// AppD Configuration Task List
+		{Method: rest.HTTP_METHOD_GET, Path: meputil.AppDTaskListPath, Func: m.getTaskList},
+
+		// AppD Configuration Task Cancel
+		{Method: rest.HTTP_METHOD_DELETE, Path: meputil.AppDQueryResPath, Func: m.cancelTask},
+
+		// AppD Configuration Rule Operations
+		{Method: rest.HTTP_METHOD_POST, Path: meputil.AppDRuleOperationsPath, Func: m.appDRuleOperations},
+
+		// AppD Configuration Versions
+		{Method: rest.HTTP_METHOD_GET, Path: meputil.AppDConfigVersionsPath, Func: m.getAppDVersions},
+		{Method: rest.HTTP_METHOD_GET, Path: meputil.AppDConfigVersionDiffPath, Func: m.getAppDVersionDiff},
+		{Method: rest.HTTP_METHOD_POST, Path: meputil.AppDConfigRollbackPath, Func: m.appDRollback},
+
+		// Mep State Export and Import
+		{Method: rest.HTTP_METHOD_GET, Path: meputil.MepStatePath, Func: m.exportMepState},
+		{Method: rest.HTTP_METHOD_POST, Path: meputil.MepStatePath, Func: m.importMepState},
+
+		// AppD Configuration Task Queue Statistics
+		{Method: rest.HTTP_METHOD_GET, Path: meputil.AppDTaskQueuePath, Func: m.getTaskQueueStats},
 	}
 }
 
@@ -107,7 +148,7 @@ func (m *Mm5Service) getPlatformCapabilities(w http.ResponseWriter, r *http.Requ
 	workPlan := NewWorkSpace(w, r)
 	workPlan.Try(
 		&plans.DecodeCapabilityQueryReq{},
-		&plans.CapabilitiesGet{})
+		(&plans.CapabilitiesGet{}).WithCapabilities(m.dpCapabilities))
 	workPlan.Finally(&common.SendHttpRsp{})
 
 	workspace.WkRun(workPlan)
@@ -117,7 +158,7 @@ func (m *Mm5Service) getPlatformCapability(w http.ResponseWriter, r *http.Reques
 	workPlan := NewWorkSpace(w, r)
 	workPlan.Try(
 		&plans.DecodeCapabilityQueryReq{},
-		&plans.CapabilityGet{})
+		(&plans.CapabilityGet{}).WithCapabilities(m.dpCapabilities))
 	workPlan.Finally(&common.SendHttpRsp{})
 
 	workspace.WkRun(workPlan)
@@ -127,7 +168,7 @@ func (m *Mm5Service) appDCreate(w http.ResponseWriter, r *http.Request) {
 	workPlan := NewWorkSpace(w, r)
 	workPlan.Try(
 		(&plans.DecodeAppDRestReq{}).WithBody(&models.AppDConfig{}),
-		(&plans.CreateAppDConfig{}).WithWorker(&m.mp2Worker))
+		(&plans.CreateAppDConfig{}).WithWorker(&m.mp2Worker).WithCapabilities(m.dpCapabilities))
 	workPlan.Finally(&common.SendHttpRsp{})
 
 	workspace.WkRun(workPlan)
@@ -138,7 +179,49 @@ func (m *Mm5Service) appDUpdate(w http.ResponseWriter, r *http.Request) {
 	workPlan := NewWorkSpace(w, r)
 	workPlan.Try(
 		(&plans.DecodeAppDRestReq{}).WithBody(&models.AppDConfig{}),
-		(&plans.UpdateAppDConfig{}).WithWorker(&m.mp2Worker))
+		(&plans.UpdateAppDConfig{}).WithWorker(&m.mp2Worker).WithCapabilities(m.dpCapabilities))
+	workPlan.Finally(&common.SendHttpRsp{})
+
+	workspace.WkRun(workPlan)
+}
+
+func (m *Mm5Service) appDRuleOperations(w http.ResponseWriter, r *http.Request) {
+
+	workPlan := NewWorkSpace(w, r)
+	workPlan.Try(
+		(&plans.DecodeAppDRestReq{}).WithBody(&models.AppDRuleOperations{}),
+		(&plans.ApplyAppDRuleOperations{}).WithWorker(&m.mp2Worker).WithCapabilities(m.dpCapabilities))
+	workPlan.Finally(&common.SendHttpRsp{})
+
+	workspace.WkRun(workPlan)
+}
+
+func (m *Mm5Service) getAppDVersions(w http.ResponseWriter, r *http.Request) {
+	workPlan := NewWorkSpace(w, r)
+	workPlan.Try(
+		&plans.DecodeAppDRestReq{},
+		&plans.AppDConfigVersionsGet{})
+	workPlan.Finally(&common.SendHttpRsp{})
+
+	workspace.WkRun(workPlan)
+}
+
+func (m *Mm5Service) getAppDVersionDiff(w http.ResponseWriter, r *http.Request) {
+	workPlan := NewWorkSpace(w, r)
+	workPlan.Try(
+		&plans.DecodeAppDRestReq{},
+		&plans.AppDConfigVersionDiffGet{})
+	workPlan.Finally(&common.SendHttpRsp{})
+
+	workspace.WkRun(workPlan)
+}
+
+func (m *Mm5Service) appDRollback(w http.ResponseWriter, r *http.Request) {
+
+	workPlan := NewWorkSpace(w, r)
+	workPlan.Try(
+		(&plans.DecodeAppDRestReq{}).WithBody(&models.AppDConfigRollback{}),
+		(&plans.RollbackAppDConfig{}).WithWorker(&m.mp2Worker).WithCapabilities(m.dpCapabilities))
 	workPlan.Finally(&common.SendHttpRsp{})
 
 	workspace.WkRun(workPlan)
@@ -178,13 +261,86 @@ func (m *Mm5Service) getResourceTasks(w http.ResponseWriter, r *http.Request) {
 
 }
 
+func (m *Mm5Service) getTaskList(w http.ResponseWriter, r *http.Request) {
+	workPlan := NewWorkSpace(w, r)
+	workPlan.Try(
+		&plans.DecodeTaskRestReq{},
+		&plans.TaskListGet{})
+	workPlan.Finally(&common.SendHttpRsp{})
+
+	workspace.WkRun(workPlan)
+}
+
+func (m *Mm5Service) getTaskQueueStats(w http.ResponseWriter, r *http.Request) {
+	workPlan := NewWorkSpace(w, r)
+	workPlan.Try(
+		(&plans.TaskQueueStatsGet{}).WithWorker(&m.mp2Worker))
+	workPlan.Finally(&common.SendHttpRsp{})
+
+	workspace.WkRun(workPlan)
+}
+
+func (m *Mm5Service) cancelTask(w http.ResponseWriter, r *http.Request) {
+	workPlan := NewWorkSpace(w, r)
+	workPlan.Try(
+		&plans.DecodeTaskRestReq{},
+		(&plans.TaskCancel{}).WithWorker(&m.mp2Worker))
+	workPlan.Finally(&common.SendHttpRsp{})
+
+	workspace.WkRun(workPlan)
+}
+
+// Graceful timeout of the app termination from the configuration
+func (m *Mm5Service) gracefulTimeout() int {
+	if m.config == nil {
+		return 0
+	}
+	return m.config.AppTermination.GracefulTimeout
+}
+
 func (m *Mm5Service) terminateAppInstance(w http.ResponseWriter, r *http.Request) {
 	workPlan := NewWorkSpace(w, r)
 	workPlan.Try(
 		&plans.DecodeAppTerminationReq{},
+		(&plans.NotifyAppTermination{}).WithGracefulTimeout(m.gracefulTimeout()).WithTeardown(m.teardownAppInstance),
+		(&plans.DeleteAppDConfigWithSync{}).WithWorker(&m.mp2Worker),
+		&plans.DeleteService{},
+		&plans.DeleteFromMepauth{})
+	workPlan.Finally(&common.SendHttpRsp{})
+
+	workspace.WkRun(workPlan)
+}
+
+// Tear down the app after the graceful wait of an accepted termination, the request is already answered by then
+func (m *Mm5Service) teardownAppInstance(ctx context.Context, appInstanceId string) {
+	workPlan := NewWorkSpace(nil, nil)
+	workPlan.Ctx = util.SetTargetDomainProject(context.Background(), util.ParseTargetDomain(ctx),
+		util.ParseTargetProject(ctx))
+	workPlan.AppInstanceId = appInstanceId
+	workPlan.Try(
 		(&plans.DeleteAppDConfigWithSync{}).WithWorker(&m.mp2Worker),
 		&plans.DeleteService{},
 		&plans.DeleteFromMepauth{})
+
+	workspace.WkRun(workPlan)
+	log.Infof("App(%s) torn down after the termination", appInstanceId)
+}
+
+func (m *Mm5Service) exportMepState(w http.ResponseWriter, r *http.Request) {
+	workPlan := NewWorkSpace(w, r)
+	workPlan.Try(
+		&plans.DecodeMepStateReq{},
+		&plans.MepStateExport{})
+	workPlan.Finally(&common.SendHttpRsp{})
+
+	workspace.WkRun(workPlan)
+}
+
+func (m *Mm5Service) importMepState(w http.ResponseWriter, r *http.Request) {
+	workPlan := NewWorkSpace(w, r)
+	workPlan.Try(
+		(&plans.DecodeMepStateReq{}).WithBody(&models.MepStateSnapshot{}),
+		(&plans.MepStateImport{}).WithWorker(&m.mp2Worker))
 	workPlan.Finally(&common.SendHttpRsp{})
 
 	workspace.WkRun(workPlan)
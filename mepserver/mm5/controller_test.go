@@ -23,9 +23,12 @@ import (
 	"fmt"
 	"github.com/ghodss/yaml"
 	uuid "github.com/satori/go.uuid"
+	"io"
 	"math/rand"
+	"mepserver/common"
 	"mepserver/common/arch/workspace"
 	"mepserver/common/config"
+	"mepserver/common/extif/dataplane"
 	"mepserver/common/extif/dns"
 	"mepserver/common/models"
 	"mepserver/mm5/task"
@@ -42,6 +45,7 @@ import (
 
 	"github.com/agiledragon/gomonkey"
 	"github.com/apache/servicecomb-service-center/pkg/log"
+	scutil "github.com/apache/servicecomb-service-center/pkg/util"
 	_ "github.com/apache/servicecomb-service-center/server"
 	_ "github.com/apache/servicecomb-service-center/server/bootstrap"
 	"github.com/apache/servicecomb-service-center/server/core/proto"
@@ -398,6 +402,10 @@ func TestDeleteConfigRules(t *testing.T) {
 		return 0
 	})
 
+	patch2.ApplyFunc(backend.PutRecordIfRevision, func(path string, value []byte, revision int64) int {
+		return 0
+	})
+
 	patch3 := gomonkey.ApplyFunc(backend.DeletePaths, func(paths []string, continueOnFailure bool) int {
 
 		// Return Success.
@@ -430,6 +438,58 @@ func TestDeleteConfigRules(t *testing.T) {
 	mockWriter.AssertExpectations(t)
 }
 
+var jobRecordWritten bool
+
+// Delete ConfigRules - job of another request created after the ongoing operation check
+func TestDeleteConfigRulesJobCreatedConcurrently(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mm5Service{}
+
+	deleteRequest, _ := http.NewRequest("DELETE", fmt.Sprintf(appConfigUrlFormat, defaultAppInstanceId), nil)
+	deleteRequest.URL.RawQuery = fmt.Sprintf(appInstanceQueryFormat, defaultAppInstanceId)
+	deleteRequest.Header.Set(appInstanceIdHeader, defaultAppInstanceId)
+
+	mockWriter := &mockHttpWriter{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write", []byte("{\"title\":\"Operation Not Allowed\",\"status\":20,"+
+		"\"detail\":\"app instance has other operation in progress\"}\n")).Return(0, nil)
+	mockWriter.On("WriteHeader", 403)
+
+	patches := gomonkey.ApplyFunc(backend.GetRecord, func(path string) ([]byte, int) {
+		if strings.Contains(path, "jobs") {
+			return nil, 1111
+		}
+		outBytes, _ := json.Marshal(&models.AppDConfig{AppName: "abc", AppDNSRule: []dataplane.DNSRule{
+			{DNSRuleID: "dnsRule1", DomainName: "www.example.com", IPAddressType: "IP_V4", IPAddress: "192.0.2.0",
+				TTL: 30, State: "ACTIVE"}}})
+		return outBytes, 0
+	})
+	defer patches.Reset()
+	patches.ApplyFunc(plans.IsAppInstanceIdAlreadyExists, func(appInstanceId string) bool {
+		return true
+	})
+	patches.ApplyFunc(backend.PutRecordIfRevision, func(path string, value []byte, revision int64) int {
+		return util.RecordModifiedErr
+	})
+	jobRecordWritten = false
+	patches.ApplyFunc(backend.PutRecord, func(path string, value []byte) int {
+		jobRecordWritten = true
+		return 0
+	})
+
+	service.URLPatterns()[3].Func(mockWriter, deleteRequest)
+
+	assert.Equal(t, "403", responseHeader.Get(responseStatusHeader), "Response status code must be 403")
+	assert.False(t, jobRecordWritten, "Task must not be recorded for the rejected request")
+	mockWriter.AssertExpectations(t)
+}
+
 // Delete ConfigRules - Application Instance not exist
 func TestDeleteConfigRulesAppNotExist(t *testing.T) {
 	defer func() {
@@ -1554,6 +1614,105 @@ func TestGetCapabilitiesWithMultiConsumersAndCategoryFilter(t *testing.T) {
 }
 
 // Query capability
+// Capabilities consumed by an app, with the metadata and liveness of the services
+func TestGetCapabilitiesWithQueryFilters(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mm5Service{}
+
+	getRequest, _ := http.NewRequest("GET", getCapabilitiesUrl, bytes.NewReader([]byte("")))
+	getRequest.URL.RawQuery = fmt.Sprintf("state=ACTIVE&consumer=%s&include=metadata,liveness&limit=1",
+		appInstanceId2)
+
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 200)
+
+	patch1 := gomonkey.ApplyFunc(util.FindInstanceByKey, func(result url.Values) (*proto.FindInstancesResponse, error) {
+		response := proto.FindInstancesResponse{}
+		response.Instances = append(response.Instances, &proto.MicroServiceInstance{
+			InstanceId: defCapabilityId[len(defCapabilityId)/2:],
+			ServiceId:  defCapabilityId[:len(defCapabilityId)/2],
+			Version:    "3.2.1",
+			Properties: map[string]string{"serName": "FaceRegService6", "mecState": "ACTIVE"},
+		})
+		response.Instances = append(response.Instances, &proto.MicroServiceInstance{
+			InstanceId: defCapabilityId2[len(defCapabilityId2)/2:],
+			ServiceId:  defCapabilityId2[:len(defCapabilityId2)/2],
+			Version:    "3.2.1",
+			Properties: map[string]string{
+				"serName":           "FaceRegService5",
+				"mecState":          "ACTIVE",
+				"serializer":        "JSON",
+				"ScopeOfLocality":   "MEC_HOST",
+				"serCategory/id":    "id12345",
+				"serCategory/name":  "RNI",
+				"livenessInterval":  "60",
+				"timestamp/seconds": "1600000000",
+			},
+		})
+		return &response, nil
+	})
+	defer patch1.Reset()
+
+	patch2 := gomonkey.ApplyFunc(backend.GetRecordsWithCompleteKeyPath, func(path string) (map[string][]byte, int) {
+		records := make(map[string][]byte)
+		entry := models.SerAvailabilityNotificationSubscription{SubscriptionId: subscriberId2}
+		entry.FilteringCriteria.SerInstanceIds = append(entry.FilteringCriteria.SerInstanceIds, defCapabilityId2)
+		outBytes, _ := json.Marshal(&entry)
+		records[fmt.Sprintf(recordDB, appInstanceId2, subscriberId2)] = outBytes
+		return records, 0
+	})
+	defer patch2.Reset()
+
+	// 5 is the order of the capabilities get handler in the URLPattern
+	service.URLPatterns()[5].Func(mockWriter, getRequest)
+
+	assert.Equal(t, "200", responseHeader.Get(responseStatusHeader), responseCheckFor200)
+	assert.Equal(t, "1", responseHeader.Get(util.TotalCountHeader))
+	capabilities := make([]models.PlatformCapability, 0)
+	_ = json.Unmarshal(mockWriter.response, &capabilities)
+	assert.Equal(t, 1, len(capabilities))
+	assert.Equal(t, defCapabilityId2, capabilities[0].CapabilityId)
+	assert.Equal(t, "RNI", capabilities[0].SerCategory.Name)
+	assert.Equal(t, "JSON", capabilities[0].Serializer)
+	assert.Equal(t, 60, capabilities[0].Liveness.Interval)
+	assert.Nil(t, capabilities[0].TransportInfo)
+	mockWriter.AssertExpectations(t)
+}
+
+// Capabilities query with a page size above the limit
+func TestGetCapabilitiesInvalidLimit(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mm5Service{}
+
+	getRequest, _ := http.NewRequest("GET", getCapabilitiesUrl, bytes.NewReader([]byte("")))
+	getRequest.URL.RawQuery = "limit=1001"
+
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 400)
+
+	// 5 is the order of the capabilities get handler in the URLPattern
+	service.URLPatterns()[5].Func(mockWriter, getRequest)
+
+	assert.Equal(t, "400", responseHeader.Get(responseStatusHeader), "Response status code must be 400")
+	mockWriter.AssertExpectations(t)
+}
+
 func TestGetCapabilitySuccessCase(t *testing.T) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -2152,3 +2311,1121 @@ func TestAppInstanceTermination2(t *testing.T) {
 
 	service.URLPatterns()[7].Func(mockWriterGet, getRequest)
 }
+
+// Query data-plane capabilities
+func TestGetDataPlaneCapabilities(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mm5Service{dpCapabilities: &dataplane.Capabilities{
+		Actions:        []string{dataplane.ActionDrop},
+		FilterFields:   []string{dataplane.FilterSrcAddress},
+		InterfaceTypes: []string{dataplane.InterfaceTypeIP},
+	}}
+
+	getRequest, _ := http.NewRequest("GET", "/mepcfg/mec_platform_config/v1/capabilities/dataplane", nil)
+	getRequest.URL.RawQuery = ":capabilityId=dataplane"
+
+	mockWriter := &mockHttpWriter{}
+	response := []byte("{\"capabilityId\":\"dataplane\",\"capabilityName\":\"dataplane\",\"status\":\"ACTIVE\"," +
+		"\"version\":\"\",\"consumers\":[],\"dataPlane\":{\"actions\":[\"DROP\"],\"filterFields\":[\"srcAddress\"]," +
+		"\"interfaceTypes\":[\"IP\"]}}\n")
+	responseHeader := http.Header{}
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write", response).Return(0, nil)
+	mockWriter.On("WriteHeader", 200)
+
+	// 6 is the order of the capability get handler in the URLPattern
+	service.URLPatterns()[6].Func(mockWriter, getRequest)
+
+	assert.Equal(t, "200", responseHeader.Get(responseStatusHeader), responseCheckFor200)
+	mockWriter.AssertExpectations(t)
+}
+
+// Create ConfigRules - Traffic rule action not supported by the data-plane
+func TestCreateAppDConfigUnsupportedAction(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mm5Service{dpCapabilities: &dataplane.Capabilities{
+		Actions: []string{dataplane.ActionPassThrough},
+	}}
+
+	postRequest, _ := http.NewRequest("POST",
+		fmt.Sprintf(appConfigUrlFormat, defaultAppInstanceId),
+		bytes.NewReader([]byte(`
+{
+  "appTrafficRule": [
+    {
+      "trafficRuleId": "TrafficRule1",
+      "filterType": "FLOW",
+      "priority": 1,
+      "action": "DROP",
+      "state": "ACTIVE",
+      "trafficFilter": [
+        {
+          "srcAddress": [
+            "192.168.1.1/28"
+          ]
+        }
+      ]
+    }
+  ],
+  "appSupportMp1": true,
+  "appName": "abc"
+}`)))
+
+	postRequest.URL.RawQuery = fmt.Sprintf(appInstanceQueryFormat, defaultAppInstanceId)
+	postRequest.Header.Set(appInstanceIdHeader, defaultAppInstanceId)
+
+	patch1 := gomonkey.ApplyFunc(plans.IsAppInstanceIdAlreadyExists, func(appInstanceId string) (isExists bool) {
+		return false
+	})
+	defer patch1.Reset()
+
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{}
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 400)
+
+	service.URLPatterns()[0].Func(mockWriter, postRequest)
+	assert.Equal(t, "400", responseHeader.Get(responseStatusHeader), responseCheckFor400)
+	mockWriter.AssertExpectations(t)
+}
+
+// Query Task List filtered by the state with paging
+func TestGetTaskList(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mm5Service{}
+
+	getRequest, _ := http.NewRequest("GET", "/mepcfg/app_lcm/v1/tasks", nil)
+	getRequest.URL.RawQuery = "state=SUCCESS&startTime=1000&offset=1&limit=1"
+
+	ruleStatus := []models.RuleStatus{{Id: "r123", State: util.WaitConfigDBWrite, Method: util.OperCreate}}
+	statusRecords := map[string]*models.TaskStatus{
+		"task1": {Progress: 1, TrafficRuleStatusLst: ruleStatus, CreateTime: 1000, UpdateTime: 1001},
+		"task2": {Progress: 1, TrafficRuleStatusLst: ruleStatus, CreateTime: 2000, UpdateTime: 2001},
+		"task3": {Progress: util.TaskProgressFailure, TrafficRuleStatusLst: ruleStatus, Details: "failed",
+			CreateTime: 3000, UpdateTime: 3001},
+		"task4": {Progress: 1, TrafficRuleStatusLst: ruleStatus, CreateTime: 500, UpdateTime: 501},
+	}
+
+	// Latest successful task is skipped by the offset
+	expected := models.TaskProgressList{TotalCount: 2, Tasks: []models.TaskProgress{
+		{TaskId: "task1", AppInstanceId: defaultAppInstanceId, ConfigResult: util.TASK_STATE_SUCCESS,
+			ConfigPhase: "100", CreateTime: 1000, UpdateTime: 1001}}}
+	expectedBytes, _ := json.Marshal(&expected)
+
+	mockWriter := &mockHttpWriter{}
+	responseHeader := http.Header{}
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write", append(expectedBytes, '\n')).Return(0, nil)
+	mockWriter.On("WriteHeader", 200)
+
+	patch1 := gomonkey.ApplyFunc(backend.GetRecordsWithCompleteKeyPath, func(path string) (map[string][]byte, int) {
+		records := make(map[string][]byte)
+		for taskId, status := range statusRecords {
+			records[util.AppDLCMTaskStatusPath+defaultAppInstanceId+"/"+taskId], _ = json.Marshal(status)
+		}
+		return records, 0
+	})
+	defer patch1.Reset()
+
+	service.URLPatterns()[8].Func(mockWriter, getRequest)
+
+	assert.Equal(t, "200", responseHeader.Get(responseStatusHeader), responseCheckFor200)
+	mockWriter.AssertExpectations(t)
+}
+
+// Query Task List with invalid state filter
+func TestGetTaskListInvalidQuery(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mm5Service{}
+
+	getRequest, _ := http.NewRequest("GET", "/mepcfg/app_lcm/v1/tasks", nil)
+	getRequest.URL.RawQuery = "state=DONE"
+
+	mockWriter := &mockHttpWriter{}
+	responseHeader := http.Header{}
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write", []byte("{\"title\":\"Request parameter error\",\"status\":14,\"detail\":\"invalid task state\"}\n")).
+		Return(0, nil)
+	mockWriter.On("WriteHeader", 400)
+
+	service.URLPatterns()[8].Func(mockWriter, getRequest)
+
+	assert.Equal(t, "400", responseHeader.Get(responseStatusHeader), responseCheckFor400)
+	mockWriter.AssertExpectations(t)
+}
+
+// Cancel Task - running task
+func TestCancelTask(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mm5Service{}
+
+	deleteRequest, _ := http.NewRequest("DELETE", fmt.Sprintf(getTaskStatusFormat, defaultTaskId), nil)
+	deleteRequest.URL.RawQuery = fmt.Sprintf(taskQueryFormat, defaultTaskId)
+
+	mockWriter := &mockHttpWriter{}
+	responseHeader := http.Header{}
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write", []byte(fmt.Sprintf("{\"taskId\":\"%s\",\"appInstanceId\":\"%s\",\"configResult\":"+
+		"\"PROCESSING\",\"configPhase\":\"0\",\"Detailed\":\"Cancellation in progress\"}\n", defaultTaskId,
+		defaultAppInstanceId))).Return(0, nil)
+	mockWriter.On("WriteHeader", 200)
+
+	patch1 := gomonkey.ApplyFunc(backend.GetRecord, func(path string) ([]byte, int) {
+		if strings.Contains(path, "jobs") {
+			outBytes, _ := json.Marshal(&models.AppDConfig{AppName: "AppName", TaskId: defaultTaskId})
+			return outBytes, 0
+		}
+		return []byte(defaultAppInstanceId), 0
+	})
+	defer patch1.Reset()
+	patch2 := gomonkey.ApplyMethod(reflect.TypeOf(&service.mp2Worker), "CancelTask", func(*task.Worker, string) bool {
+		return true
+	})
+	defer patch2.Reset()
+
+	service.URLPatterns()[9].Func(mockWriter, deleteRequest)
+
+	assert.Equal(t, "200", responseHeader.Get(responseStatusHeader), responseCheckFor200)
+	mockWriter.AssertExpectations(t)
+}
+
+// Cancel Task - task finished after its job is read
+func TestCancelTaskFinishedOnWorker(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mm5Service{}
+
+	deleteRequest, _ := http.NewRequest("DELETE", fmt.Sprintf(getTaskStatusFormat, defaultTaskId), nil)
+	deleteRequest.URL.RawQuery = fmt.Sprintf(taskQueryFormat, defaultTaskId)
+
+	mockWriter := &mockHttpWriter{}
+	responseHeader := http.Header{}
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write", []byte("{\"title\":\"Task already finished\",\"status\":21,\"detail\":\"task is already finished\"}\n")).
+		Return(0, nil)
+	mockWriter.On("WriteHeader", 409)
+
+	patch1 := gomonkey.ApplyFunc(backend.GetRecord, func(path string) ([]byte, int) {
+		if strings.Contains(path, "jobs") {
+			outBytes, _ := json.Marshal(&models.AppDConfig{AppName: "AppName", TaskId: defaultTaskId})
+			return outBytes, 0
+		}
+		return []byte(defaultAppInstanceId), 0
+	})
+	defer patch1.Reset()
+
+	service.URLPatterns()[9].Func(mockWriter, deleteRequest)
+
+	assert.Equal(t, "409", responseHeader.Get(responseStatusHeader), "Expected 409 response")
+	mockWriter.AssertExpectations(t)
+}
+
+// Cancel Task - task already finished
+func TestCancelFinishedTask(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mm5Service{}
+
+	deleteRequest, _ := http.NewRequest("DELETE", fmt.Sprintf(getTaskStatusFormat, defaultTaskId), nil)
+	deleteRequest.URL.RawQuery = fmt.Sprintf(taskQueryFormat, defaultTaskId)
+
+	mockWriter := &mockHttpWriter{}
+	responseHeader := http.Header{}
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write", []byte("{\"title\":\"Task already finished\",\"status\":21,\"detail\":\"task is already finished\"}\n")).
+		Return(0, nil)
+	mockWriter.On("WriteHeader", 409)
+
+	patch1 := gomonkey.ApplyFunc(backend.GetRecord, func(path string) ([]byte, int) {
+		if strings.Contains(path, "jobs") {
+			return nil, util.SubscriptionNotFound
+		}
+		return []byte(defaultAppInstanceId), 0
+	})
+	defer patch1.Reset()
+
+	service.URLPatterns()[9].Func(mockWriter, deleteRequest)
+
+	assert.Equal(t, "409", responseHeader.Get(responseStatusHeader), "Expected 409 response")
+	mockWriter.AssertExpectations(t)
+}
+
+// Update ConfigRules - dry-run returns the rule operations without applying them
+func TestUpdateAppDConfigDryRun(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mm5Service{}
+
+	putRequest, _ := http.NewRequest("PUT",
+		fmt.Sprintf(appConfigUrlFormat, defaultAppInstanceId),
+		bytes.NewReader([]byte(`
+{
+  "appTrafficRule": [
+    {
+      "trafficRuleId": "TrafficRule1",
+      "filterType": "FLOW",
+      "priority": 2,
+      "action": "DROP",
+      "state": "ACTIVE",
+      "trafficFilter": [{"srcAddress": ["192.168.1.1/28"]}]
+    },
+    {
+      "trafficRuleId": "TrafficRule3",
+      "filterType": "FLOW",
+      "priority": 1,
+      "action": "DROP",
+      "state": "ACTIVE",
+      "trafficFilter": [{"srcAddress": ["192.168.1.2/28"]}]
+    }
+  ],
+  "appSupportMp1": true,
+  "appName": "abc"
+}`)))
+	putRequest.URL.RawQuery = fmt.Sprintf(":appInstanceId=%s&dryRun=true", defaultAppInstanceId)
+
+	storedConfig := models.AppDConfig{AppName: "abc", AppSupportMp1: true, AppTrafficRule: []dataplane.TrafficRule{
+		{TrafficRuleID: "TrafficRule1", FilterType: "FLOW", Priority: 1, Action: "DROP", State: "ACTIVE",
+			TrafficFilter: []dataplane.TrafficFilter{{SrcAddress: []string{"192.168.1.1/28"}}}},
+		{TrafficRuleID: "TrafficRule2", FilterType: "FLOW", Priority: 1, Action: "DROP", State: "ACTIVE",
+			TrafficFilter: []dataplane.TrafficFilter{{SrcAddress: []string{"192.168.1.3/28"}}}},
+	}}
+
+	patch1 := gomonkey.ApplyFunc(plans.IsAppInstanceIdAlreadyExists, func(appInstanceId string) (isExists bool) {
+		return true
+	})
+	patch2 := gomonkey.ApplyFunc(plans.IsAnyOngoingOperationExist, func(appInstanceId string) (isExists bool) {
+		return false
+	})
+	patch3 := gomonkey.ApplyFunc(backend.GetRecord, func(path string) ([]byte, int) {
+		outBytes, _ := json.Marshal(&storedConfig)
+		return outBytes, 0
+	})
+	patch4 := gomonkey.ApplyFunc(backend.GetRecords, func(path string) (map[string][]byte, int) {
+		return map[string][]byte{}, 0
+	})
+	patch5 := gomonkey.ApplyFunc(backend.PutRecord, func(path string, value []byte) int {
+		t.Errorf("dry-run must not write to the data-store(%s)", path)
+		return 0
+	})
+	defer patch1.Reset()
+	defer patch2.Reset()
+	defer patch3.Reset()
+	defer patch4.Reset()
+	defer patch5.Reset()
+
+	mockWriter := &mockHttpWriter{}
+	responseHeader := http.Header{}
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write", []byte(fmt.Sprintf("{\"appInstanceId\":\"%s\",\"trafficRules\":["+
+		"{\"id\":\"TrafficRule1\",\"operation\":\"MODIFY\"},{\"id\":\"TrafficRule2\",\"operation\":\"DELETE\"},"+
+		"{\"id\":\"TrafficRule3\",\"operation\":\"CREATE\"}],\"dnsRules\":[]}\n", defaultAppInstanceId))).
+		Return(0, nil)
+	mockWriter.On("WriteHeader", 200)
+
+	service.URLPatterns()[1].Func(mockWriter, putRequest)
+
+	assert.Equal(t, "200", responseHeader.Get(responseStatusHeader), responseCheckFor200)
+	mockWriter.AssertExpectations(t)
+}
+
+// Rule operations - dry-run of replace, remove and add on the stored configuration
+func TestAppDRuleOperationsDryRun(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mm5Service{}
+
+	postRequest, _ := http.NewRequest("POST",
+		fmt.Sprintf(appConfigUrlFormat, defaultAppInstanceId)+"/rule_operations",
+		bytes.NewReader([]byte(`
+{
+  "appTrafficRule": [
+    {
+      "op": "replace",
+      "trafficRuleId": "TrafficRule1",
+      "value": {
+        "trafficRuleId": "TrafficRule1",
+        "filterType": "FLOW",
+        "priority": 2,
+        "action": "DROP",
+        "state": "ACTIVE",
+        "trafficFilter": [{"srcAddress": ["192.168.1.1/28"]}]
+      }
+    },
+    {
+      "op": "remove",
+      "trafficRuleId": "TrafficRule2"
+    }
+  ],
+  "appDNSRule": [
+    {
+      "op": "add",
+      "dnsRuleId": "dnsRule1",
+      "value": {
+        "dnsRuleId": "dnsRule1",
+        "domainName": "www.example.com",
+        "ipAddressType": "IP_V4",
+        "ipAddress": "192.0.2.0",
+        "ttl": 30,
+        "state": "ACTIVE"
+      }
+    }
+  ]
+}`)))
+	postRequest.URL.RawQuery = fmt.Sprintf(":appInstanceId=%s&dryRun=true", defaultAppInstanceId)
+
+	storedConfig := models.AppDConfig{AppName: "abc", AppSupportMp1: true, AppTrafficRule: []dataplane.TrafficRule{
+		{TrafficRuleID: "TrafficRule1", FilterType: "FLOW", Priority: 1, Action: "DROP", State: "ACTIVE",
+			TrafficFilter: []dataplane.TrafficFilter{{SrcAddress: []string{"192.168.1.1/28"}}}},
+		{TrafficRuleID: "TrafficRule2", FilterType: "FLOW", Priority: 1, Action: "DROP", State: "ACTIVE",
+			TrafficFilter: []dataplane.TrafficFilter{{SrcAddress: []string{"192.168.1.3/28"}}}},
+	}}
+
+	patch1 := gomonkey.ApplyFunc(plans.IsAppInstanceIdAlreadyExists, func(appInstanceId string) (isExists bool) {
+		return true
+	})
+	patch2 := gomonkey.ApplyFunc(plans.IsAnyOngoingOperationExist, func(appInstanceId string) (isExists bool) {
+		return false
+	})
+	patch3 := gomonkey.ApplyFunc(backend.GetRecord, func(path string) ([]byte, int) {
+		outBytes, _ := json.Marshal(&storedConfig)
+		return outBytes, 0
+	})
+	patch4 := gomonkey.ApplyFunc(backend.GetRecords, func(path string) (map[string][]byte, int) {
+		return map[string][]byte{}, 0
+	})
+	patch5 := gomonkey.ApplyFunc(backend.PutRecord, func(path string, value []byte) int {
+		t.Errorf("dry-run must not write to the data-store(%s)", path)
+		return 0
+	})
+	defer patch1.Reset()
+	defer patch2.Reset()
+	defer patch3.Reset()
+	defer patch4.Reset()
+	defer patch5.Reset()
+
+	mockWriter := &mockHttpWriter{}
+	responseHeader := http.Header{}
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write", []byte(fmt.Sprintf("{\"appInstanceId\":\"%s\",\"trafficRules\":["+
+		"{\"id\":\"TrafficRule1\",\"operation\":\"MODIFY\"},{\"id\":\"TrafficRule2\",\"operation\":\"DELETE\"}],"+
+		"\"dnsRules\":[{\"id\":\"dnsRule1\",\"operation\":\"CREATE\"}]}\n", defaultAppInstanceId))).
+		Return(0, nil)
+	mockWriter.On("WriteHeader", 200)
+
+	service.URLPatterns()[10].Func(mockWriter, postRequest)
+
+	assert.Equal(t, "200", responseHeader.Get(responseStatusHeader), responseCheckFor200)
+	mockWriter.AssertExpectations(t)
+}
+
+// Rule operations - remove of a rule which is not in the stored configuration
+func TestAppDRuleOperationsRuleNotFound(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mm5Service{}
+
+	postRequest, _ := http.NewRequest("POST",
+		fmt.Sprintf(appConfigUrlFormat, defaultAppInstanceId)+"/rule_operations",
+		bytes.NewReader([]byte(`{"appDNSRule": [{"op": "remove", "dnsRuleId": "dnsRule9"}]}`)))
+	postRequest.URL.RawQuery = fmt.Sprintf(appInstanceQueryFormat, defaultAppInstanceId)
+
+	storedConfig := models.AppDConfig{AppName: "abc", AppSupportMp1: true}
+
+	patch1 := gomonkey.ApplyFunc(plans.IsAppInstanceIdAlreadyExists, func(appInstanceId string) (isExists bool) {
+		return true
+	})
+	patch2 := gomonkey.ApplyFunc(plans.IsAnyOngoingOperationExist, func(appInstanceId string) (isExists bool) {
+		return false
+	})
+	patch3 := gomonkey.ApplyFunc(backend.GetRecord, func(path string) ([]byte, int) {
+		outBytes, _ := json.Marshal(&storedConfig)
+		return outBytes, 0
+	})
+	defer patch1.Reset()
+	defer patch2.Reset()
+	defer patch3.Reset()
+
+	mockWriter := &mockHttpWriter{}
+	responseHeader := http.Header{}
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write", []byte("{\"title\":\"Request parameter error\",\"status\":14,"+
+		"\"detail\":\"dns rule(dnsRule9) not found\"}\n")).Return(0, nil)
+	mockWriter.On("WriteHeader", 400)
+
+	service.URLPatterns()[10].Func(mockWriter, postRequest)
+
+	assert.Equal(t, "400", responseHeader.Get(responseStatusHeader), responseCheckFor400)
+	mockWriter.AssertExpectations(t)
+}
+
+// Create AppD config - callback uri must use https
+func TestCreateAppDConfigInvalidCallbackUri(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mm5Service{}
+
+	postRequest, _ := http.NewRequest("POST", fmt.Sprintf(appConfigUrlFormat, defaultAppInstanceId),
+		bytes.NewReader([]byte(`{"appTrafficRule": [], "appDNSRule": [], "appSupportMp1": true, "appName": "abc"}`)))
+	postRequest.URL.RawQuery = fmt.Sprintf(":appInstanceId=%s&callbackUri=%s", defaultAppInstanceId,
+		url.QueryEscape("http://mepm.example.com/tasks"))
+
+	mockWriter := &mockHttpWriter{}
+	responseHeader := http.Header{}
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write", []byte("{\"title\":\"Request parameter error\",\"status\":14,"+
+		"\"detail\":\"invalid callback uri\"}\n")).Return(0, nil)
+	mockWriter.On("WriteHeader", 400)
+
+	service.URLPatterns()[0].Func(mockWriter, postRequest)
+
+	assert.Equal(t, "400", responseHeader.Get(responseStatusHeader), responseCheckFor400)
+	mockWriter.AssertExpectations(t)
+}
+
+// Versions of the AppD config used in the version tests
+var testAppDConfigVersions = []models.AppDConfigVersion{
+	{Version: 1, TaskId: "task1", AppDConfig: models.AppDConfig{AppName: "abc", AppTrafficRule: []dataplane.TrafficRule{
+		{TrafficRuleID: "TrafficRule1", FilterType: "FLOW", Priority: 1, Action: "DROP", State: "ACTIVE",
+			TrafficFilter: []dataplane.TrafficFilter{{SrcAddress: []string{"192.168.1.1/28"}}}},
+		{TrafficRuleID: "TrafficRule2", FilterType: "FLOW", Priority: 1, Action: "DROP", State: "ACTIVE",
+			TrafficFilter: []dataplane.TrafficFilter{{SrcAddress: []string{"192.168.1.3/28"}}}},
+	}}},
+	{Version: 2, TaskId: "task2", AppDConfig: models.AppDConfig{AppName: "abc", AppTrafficRule: []dataplane.TrafficRule{
+		{TrafficRuleID: "TrafficRule1", FilterType: "FLOW", Priority: 2, Action: "DROP", State: "ACTIVE",
+			TrafficFilter: []dataplane.TrafficFilter{{SrcAddress: []string{"192.168.1.1/28"}}}},
+		{TrafficRuleID: "TrafficRule3", FilterType: "FLOW", Priority: 1, Action: "DROP", State: "ACTIVE",
+			TrafficFilter: []dataplane.TrafficFilter{{SrcAddress: []string{"192.168.1.2/28"}}}},
+	}}},
+}
+
+// Diff between two AppD config versions
+func TestGetAppDConfigVersionDiff(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mm5Service{}
+
+	getRequest, _ := http.NewRequest("GET",
+		fmt.Sprintf(appConfigUrlFormat, defaultAppInstanceId)+"/versions/diff", nil)
+	getRequest.URL.RawQuery = fmt.Sprintf(":appInstanceId=%s&from=1&to=2", defaultAppInstanceId)
+
+	patch1 := gomonkey.ApplyFunc(common.GetAppDConfigVersions, func(string) ([]models.AppDConfigVersion, int) {
+		return testAppDConfigVersions, 0
+	})
+	defer patch1.Reset()
+	patch1.ApplyFunc(plans.IsAppInstanceIdAlreadyExists, func(appInstanceId string) bool {
+		return true
+	})
+
+	mockWriter := &mockHttpWriter{}
+	responseHeader := http.Header{}
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write", []byte(fmt.Sprintf("{\"appInstanceId\":\"%s\",\"fromVersion\":1,\"toVersion\":2,"+
+		"\"trafficRules\":[{\"id\":\"TrafficRule1\",\"operation\":\"MODIFY\"},"+
+		"{\"id\":\"TrafficRule2\",\"operation\":\"DELETE\"},{\"id\":\"TrafficRule3\",\"operation\":\"CREATE\"}],"+
+		"\"dnsRules\":[]}\n", defaultAppInstanceId))).Return(0, nil)
+	mockWriter.On("WriteHeader", 200)
+
+	service.URLPatterns()[12].Func(mockWriter, getRequest)
+
+	assert.Equal(t, "200", responseHeader.Get(responseStatusHeader), responseCheckFor200)
+	mockWriter.AssertExpectations(t)
+}
+
+// Diff of the AppD config versions of an unknown app
+func TestGetAppDConfigVersionDiffAppNotFound(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mm5Service{}
+
+	getRequest, _ := http.NewRequest("GET",
+		fmt.Sprintf(appConfigUrlFormat, defaultAppInstanceId)+"/versions/diff", nil)
+	getRequest.URL.RawQuery = fmt.Sprintf(":appInstanceId=%s&from=1&to=2", defaultAppInstanceId)
+
+	patch1 := gomonkey.ApplyFunc(plans.IsAppInstanceIdAlreadyExists, func(appInstanceId string) bool {
+		return false
+	})
+	defer patch1.Reset()
+
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{}
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 404)
+
+	service.URLPatterns()[12].Func(mockWriter, getRequest)
+
+	assert.Equal(t, "404", responseHeader.Get(responseStatusHeader), "Response status code must be 404")
+	mockWriter.AssertExpectations(t)
+}
+
+// Rollback dry-run from the latest version to the earlier one
+func TestRollbackAppDConfigDryRun(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mm5Service{}
+
+	postRequest, _ := http.NewRequest("POST", fmt.Sprintf(appConfigUrlFormat, defaultAppInstanceId)+"/rollback",
+		bytes.NewReader([]byte(`{"version": 1}`)))
+	postRequest.URL.RawQuery = fmt.Sprintf(":appInstanceId=%s&dryRun=true", defaultAppInstanceId)
+
+	patch1 := gomonkey.ApplyFunc(plans.IsAppInstanceIdAlreadyExists, func(appInstanceId string) (isExists bool) {
+		return true
+	})
+	patch2 := gomonkey.ApplyFunc(plans.IsAnyOngoingOperationExist, func(appInstanceId string) (isExists bool) {
+		return false
+	})
+	patch3 := gomonkey.ApplyFunc(common.GetAppDConfigVersions, func(string) ([]models.AppDConfigVersion, int) {
+		return testAppDConfigVersions, 0
+	})
+	patch4 := gomonkey.ApplyFunc(backend.GetRecord, func(path string) ([]byte, int) {
+		outBytes, _ := json.Marshal(&testAppDConfigVersions[1].AppDConfig)
+		return outBytes, 0
+	})
+	patch5 := gomonkey.ApplyFunc(backend.GetRecords, func(path string) (map[string][]byte, int) {
+		return map[string][]byte{}, 0
+	})
+	defer patch1.Reset()
+	defer patch2.Reset()
+	defer patch3.Reset()
+	defer patch4.Reset()
+	defer patch5.Reset()
+
+	mockWriter := &mockHttpWriter{}
+	responseHeader := http.Header{}
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write", []byte(fmt.Sprintf("{\"appInstanceId\":\"%s\",\"trafficRules\":["+
+		"{\"id\":\"TrafficRule1\",\"operation\":\"MODIFY\"},{\"id\":\"TrafficRule2\",\"operation\":\"CREATE\"},"+
+		"{\"id\":\"TrafficRule3\",\"operation\":\"DELETE\"}],\"dnsRules\":[]}\n", defaultAppInstanceId))).
+		Return(0, nil)
+	mockWriter.On("WriteHeader", 200)
+
+	service.URLPatterns()[13].Func(mockWriter, postRequest)
+
+	assert.Equal(t, "200", responseHeader.Get(responseStatusHeader), responseCheckFor200)
+	mockWriter.AssertExpectations(t)
+}
+
+const terminationCallbackUri = "https://edgegallery:8080/callback"
+
+var terminationNotifications = make(chan []byte, 1)
+
+// Termination is accepted and the app is torn down before the graceful timeout once the app confirms it
+func TestNotifyAppTerminationConfirmed(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	patches := gomonkey.ApplyFunc(backend.GetRecords, func(path string) (map[string][]byte, int) {
+		subscription := models.AppTerminationNotificationSubscription{
+			SubscriptionType:  util.AppTerminationNotificationSubscription,
+			CallbackReference: terminationCallbackUri,
+			AppInstanceId:     defaultAppInstanceId,
+		}
+		subscriptionBytes, _ := json.Marshal(&subscription)
+		return map[string][]byte{subscriberId1: subscriptionBytes}, 0
+	})
+	defer patches.Reset()
+	patches.ApplyFunc(backend.PutRecordIfRevision, func(path string, value []byte, revision int64) int {
+		return 0
+	})
+	patches.ApplyFunc(backend.DeleteRecord, func(path string) int {
+		return 0
+	})
+	// App confirms once the notification is delivered
+	patches.ApplyFunc(backend.GetRecord, func(path string) ([]byte, int) {
+		status := models.AppTerminationStatus{OperationAction: util.OperationActionTerminating,
+			Confirmed: len(terminationNotifications) != 0}
+		statusBytes, _ := json.Marshal(&status)
+		return statusBytes, 0
+	})
+
+	tornDown := make(chan string, 1)
+	notifyPlan := (&plans.NotifyAppTermination{Ctx: context.Background(), AppInstanceId: defaultAppInstanceId}).
+		WithGracefulTimeout(5).
+		WithNotifier(func(uri string, body []byte) error {
+			terminationNotifications <- body
+			return nil
+		}).
+		WithTeardown(func(ctx context.Context, appInstanceId string) {
+			tornDown <- appInstanceId
+		})
+	notifyPlan.OnRequest("")
+	assert.True(t, notifyPlan.TerminationAccepted, "Termination must be accepted")
+	assert.Equal(t, http.StatusAccepted, notifyPlan.RspStatusCode)
+
+	select {
+	case appInstanceId := <-tornDown:
+		assert.Equal(t, defaultAppInstanceId, appInstanceId)
+	case <-time.After(4 * time.Second):
+		t.Fatalf("App must be torn down before the graceful timeout once confirmed")
+	}
+
+	notification := models.AppTerminationNotification{}
+	select {
+	case notificationBytes := <-terminationNotifications:
+		_ = json.Unmarshal(notificationBytes, &notification)
+	case <-time.After(time.Second):
+		t.Fatalf("Termination notification not sent")
+	}
+	assert.Equal(t, util.AppTerminationNotification, notification.NotificationType)
+	assert.Equal(t, util.OperationActionTerminating, notification.OperationAction)
+	assert.Equal(t, uint32(5), notification.MaxGracefulTimeout)
+	assert.Equal(t, fmt.Sprintf("/mec_app_support/v1/applications/%s/confirm_termination", defaultAppInstanceId),
+		notification.Links.ConfirmTermination.Href)
+}
+
+// Termination repeated during the graceful wait is accepted without notifying the app again
+func TestNotifyAppTerminationInProgress(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	patches := gomonkey.ApplyFunc(backend.GetRecords, func(path string) (map[string][]byte, int) {
+		subscription := models.AppTerminationNotificationSubscription{
+			SubscriptionType:  util.AppTerminationNotificationSubscription,
+			CallbackReference: terminationCallbackUri,
+			AppInstanceId:     defaultAppInstanceId,
+		}
+		subscriptionBytes, _ := json.Marshal(&subscription)
+		return map[string][]byte{subscriberId1: subscriptionBytes}, 0
+	})
+	defer patches.Reset()
+	// Status of the termination in progress exists already
+	patches.ApplyFunc(backend.PutRecordIfRevision, func(path string, value []byte, revision int64) int {
+		return util.RecordModifiedErr
+	})
+
+	notified := make(chan []byte, 1)
+	tornDown := make(chan string, 1)
+	notifyPlan := (&plans.NotifyAppTermination{Ctx: context.Background(), AppInstanceId: defaultAppInstanceId}).
+		WithGracefulTimeout(5).
+		WithNotifier(func(uri string, body []byte) error {
+			notified <- body
+			return nil
+		}).
+		WithTeardown(func(ctx context.Context, appInstanceId string) {
+			tornDown <- appInstanceId
+		})
+	notifyPlan.OnRequest("")
+	assert.True(t, notifyPlan.TerminationAccepted, "Termination must be accepted")
+	assert.Equal(t, http.StatusAccepted, notifyPlan.RspStatusCode)
+
+	select {
+	case <-notified:
+		t.Fatalf("App must not be notified again")
+	case <-tornDown:
+		t.Fatalf("App must not be torn down by the repeated termination")
+	case <-time.After(500 * time.Millisecond):
+	}
+}
+
+var resumedTerminationStatus []byte
+var resumedTerminationDeleted = make(chan string, 1)
+var resumedTerminationTornDown = make(chan string, 1)
+
+func getResumedTerminations(path string) (map[string][]byte, int) {
+	return map[string][]byte{defaultAppInstanceId: resumedTerminationStatus}, 0
+}
+
+func getResumedTerminationStatus(path string) ([]byte, int) {
+	return resumedTerminationStatus, 0
+}
+
+func deleteResumedTerminationStatus(path string) int {
+	resumedTerminationDeleted <- path
+	return 0
+}
+
+func skipWaitForReady() {
+}
+
+func tearDownResumedTermination(ctx context.Context, appInstanceId string) {
+	resumedTerminationTornDown <- scutil.ParseTargetDomain(ctx) + "/" + appInstanceId
+}
+
+// Termination interrupted by a restart tears down the app once its graceful timeout expires
+func TestResumeAppTerminations(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	status := models.AppTerminationStatus{OperationAction: util.OperationActionTerminating,
+		Deadline: time.Now().Add(-time.Second).Unix(), Domain: "default", Project: "default"}
+	resumedTerminationStatus, _ = json.Marshal(&status)
+	patches := gomonkey.ApplyFunc(backend.WaitForReady, skipWaitForReady)
+	defer patches.Reset()
+	patches.ApplyFunc(backend.GetRecords, getResumedTerminations)
+	patches.ApplyFunc(backend.GetRecord, getResumedTerminationStatus)
+	patches.ApplyFunc(backend.DeleteRecord, deleteResumedTerminationStatus)
+
+	plans.ResumeAppTerminations(tearDownResumedTermination)
+
+	select {
+	case tornDown := <-resumedTerminationTornDown:
+		assert.Equal(t, "default/"+defaultAppInstanceId, tornDown)
+	case <-time.After(2 * time.Second):
+		t.Fatalf("App must be torn down once the graceful timeout expired")
+	}
+	assert.Equal(t, util.AppTerminationKeyPath+defaultAppInstanceId, <-resumedTerminationDeleted)
+}
+
+const mepStateUrl = "/mepcfg/mec_platform_config/v1/state"
+const mepStateSubscriptionKey = "subscribe/" + defaultAppInstanceId + "/" + subscriberId1
+
+var restoredMepStateRecords []string
+var restoredMepStateTasks []string
+var rolledBackMepStatePaths []string
+var rolledBackMepStateServices []string
+
+func getMepStateSnapshot() models.MepStateSnapshot {
+	appDConfig := models.AppDConfig{AppName: "app1"}
+	appDConfigBytes, _ := json.Marshal(&appDConfig)
+	return models.MepStateSnapshot{
+		Version: util.MepStateSnapshotVersion,
+		Records: []models.MepStateRecord{
+			{Key: mepStateSubscriptionKey, Value: []byte("{}")},
+			{Key: "appd/" + defaultAppInstanceId, Value: appDConfigBytes},
+			{Key: "mep/applcm/jobs/" + defaultAppInstanceId, Value: appDConfigBytes},
+		},
+		Services: []models.MepStateService{{
+			Service: &proto.MicroService{ServiceId: defCapabilityId[:len(defCapabilityId)/2],
+				ServiceName: "FaceRegService6"},
+			Instances: []*proto.MicroServiceInstance{{InstanceId: defCapabilityId[len(defCapabilityId)/2:],
+				Properties: map[string]string{"appInstanceId": defaultAppInstanceId}}},
+		}},
+	}
+}
+
+// Export the data-store records and the services registered by the apps
+func TestExportMepState(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mm5Service{}
+	getRequest, _ := http.NewRequest("GET", mepStateUrl, nil)
+
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 200)
+
+	patches := gomonkey.ApplyFunc(backend.GetRecords, func(path string) (map[string][]byte, int) {
+		return map[string][]byte{}, 0
+	})
+	defer patches.Reset()
+	patches.ApplyFunc(backend.GetRecordsWithCompleteKeyPath, func(path string) (map[string][]byte, int) {
+		return map[string][]byte{util.DBRootPath + mepStateSubscriptionKey: []byte("{}")}, 0
+	})
+	patches.ApplyFunc(util.FindInstanceByKey, func(result url.Values) (*proto.FindInstancesResponse, error) {
+		response := proto.FindInstancesResponse{}
+		response.Instances = append(response.Instances, getMepStateSnapshot().Services[0].Instances[0])
+		response.Instances[0].ServiceId = defCapabilityId[:len(defCapabilityId)/2]
+		// Instance not registered through mep is left out
+		response.Instances = append(response.Instances, &proto.MicroServiceInstance{InstanceId: "instance2",
+			ServiceId: "service2", Properties: map[string]string{}})
+		return &response, nil
+	})
+	patches.ApplyMethod(reflect.TypeOf(&srv.MicroServiceService{}), "GetOne",
+		func(*srv.MicroServiceService, context.Context, *proto.GetServiceRequest) (*proto.GetServiceResponse, error) {
+			return &proto.GetServiceResponse{Service: getMepStateSnapshot().Services[0].Service}, nil
+		})
+
+	// 14 is the order of the mep state export handler in the URLPattern
+	service.URLPatterns()[14].Func(mockWriter, getRequest)
+
+	assert.Equal(t, "200", responseHeader.Get(responseStatusHeader), responseCheckFor200)
+	snapshot := models.MepStateSnapshot{}
+	_ = json.Unmarshal(mockWriter.response, &snapshot)
+	assert.Equal(t, util.MepStateSnapshotVersion, snapshot.Version)
+	assert.Equal(t, 1, len(snapshot.Records))
+	assert.Equal(t, mepStateSubscriptionKey, snapshot.Records[0].Key)
+	assert.Equal(t, 1, len(snapshot.Services))
+	assert.Equal(t, 1, len(snapshot.Services[0].Instances))
+	mockWriter.AssertExpectations(t)
+}
+
+// Import the snapshot into an empty mep, appd configurations are processed as new tasks
+func TestImportMepState(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mm5Service{}
+	snapshotBytes, _ := json.Marshal(getMepStateSnapshot())
+	postRequest, _ := http.NewRequest("POST", mepStateUrl, bytes.NewReader(snapshotBytes))
+
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 200)
+
+	restoredMepStateRecords = nil
+	restoredMepStateTasks = nil
+	patches := gomonkey.ApplyFunc(backend.GetRecords, func(path string) (map[string][]byte, int) {
+		return map[string][]byte{}, 0
+	})
+	defer patches.Reset()
+	patches.ApplyFunc(util.FindInstanceByKey, func(result url.Values) (*proto.FindInstancesResponse, error) {
+		return nil, fmt.Errorf("null")
+	})
+	patches.ApplyMethod(reflect.TypeOf(&srv.MicroServiceService{}), "Create",
+		func(_ *srv.MicroServiceService, _ context.Context,
+			in *proto.CreateServiceRequest) (*proto.CreateServiceResponse, error) {
+			return &proto.CreateServiceResponse{ServiceId: in.Service.ServiceId}, nil
+		})
+	patches.ApplyMethod(reflect.TypeOf(&srv.InstanceService{}), "Register",
+		func(_ *srv.InstanceService, _ context.Context,
+			in *proto.RegisterInstanceRequest) (*proto.RegisterInstanceResponse, error) {
+			return &proto.RegisterInstanceResponse{InstanceId: in.Instance.InstanceId}, nil
+		})
+	patches.ApplyFunc(backend.PutRecord, func(path string, value []byte) int {
+		restoredMepStateRecords = append(restoredMepStateRecords, path)
+		return 0
+	})
+	patches.ApplyFunc(plans.UpdateProcessingDatabase, func(string, string, *models.AppDConfig) (workspace.ErrCode,
+		string) {
+		return 0, ""
+	})
+	patches.ApplyMethod(reflect.TypeOf(&task.Worker{}), "StartNewTask",
+		func(_ *task.Worker, appName, appInstanceId, taskId string) {
+			restoredMepStateTasks = append(restoredMepStateTasks, appInstanceId)
+		})
+
+	// 15 is the order of the mep state import handler in the URLPattern
+	service.URLPatterns()[15].Func(mockWriter, postRequest)
+
+	assert.Equal(t, "200", responseHeader.Get(responseStatusHeader), responseCheckFor200)
+	result := models.MepStateImportResult{}
+	_ = json.Unmarshal(mockWriter.response, &result)
+	assert.Equal(t, 1, result.Records)
+	assert.Equal(t, 1, result.Services)
+	assert.Equal(t, 1, result.Instances)
+	assert.Equal(t, 1, len(result.Tasks))
+	assert.Equal(t, []string{util.DBRootPath + mepStateSubscriptionKey}, restoredMepStateRecords)
+	assert.Equal(t, []string{defaultAppInstanceId}, restoredMepStateTasks)
+	mockWriter.AssertExpectations(t)
+}
+
+// A failed import removes the restored records and services, so that the import can be retried
+func TestImportMepStateRollback(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mm5Service{}
+	snapshotBytes, _ := json.Marshal(getMepStateSnapshot())
+	postRequest, _ := http.NewRequest("POST", mepStateUrl, bytes.NewReader(snapshotBytes))
+
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 400)
+
+	restoredMepStateRecords = nil
+	restoredMepStateTasks = nil
+	rolledBackMepStatePaths = nil
+	rolledBackMepStateServices = nil
+	patches := gomonkey.ApplyFunc(backend.GetRecords, func(path string) (map[string][]byte, int) {
+		return map[string][]byte{}, 0
+	})
+	defer patches.Reset()
+	patches.ApplyFunc(util.FindInstanceByKey, func(result url.Values) (*proto.FindInstancesResponse, error) {
+		return nil, fmt.Errorf("null")
+	})
+	patches.ApplyMethod(reflect.TypeOf(&srv.MicroServiceService{}), "Create",
+		func(_ *srv.MicroServiceService, _ context.Context,
+			in *proto.CreateServiceRequest) (*proto.CreateServiceResponse, error) {
+			return &proto.CreateServiceResponse{ServiceId: in.Service.ServiceId}, nil
+		})
+	patches.ApplyMethod(reflect.TypeOf(&srv.InstanceService{}), "Register",
+		func(_ *srv.InstanceService, _ context.Context,
+			in *proto.RegisterInstanceRequest) (*proto.RegisterInstanceResponse, error) {
+			return &proto.RegisterInstanceResponse{InstanceId: in.Instance.InstanceId}, nil
+		})
+	patches.ApplyMethod(reflect.TypeOf(&srv.MicroServiceService{}), "Delete",
+		func(_ *srv.MicroServiceService, _ context.Context,
+			in *proto.DeleteServiceRequest) (*proto.DeleteServiceResponse, error) {
+			rolledBackMepStateServices = append(rolledBackMepStateServices, in.ServiceId)
+			return &proto.DeleteServiceResponse{}, nil
+		})
+	patches.ApplyFunc(backend.PutRecord, func(path string, value []byte) int {
+		restoredMepStateRecords = append(restoredMepStateRecords, path)
+		return 0
+	})
+	patches.ApplyFunc(backend.DeletePaths, func(paths []string, continueOnFailure bool) int {
+		rolledBackMepStatePaths = append(rolledBackMepStatePaths, paths...)
+		return 0
+	})
+	patches.ApplyFunc(plans.UpdateProcessingDatabase, func(string, string, *models.AppDConfig) (workspace.ErrCode,
+		string) {
+		return util.RequestParamErr, "appd config restore failed"
+	})
+	patches.ApplyMethod(reflect.TypeOf(&task.Worker{}), "StartNewTask",
+		func(_ *task.Worker, appName, appInstanceId, taskId string) {
+			restoredMepStateTasks = append(restoredMepStateTasks, appInstanceId)
+		})
+
+	// 15 is the order of the mep state import handler in the URLPattern
+	service.URLPatterns()[15].Func(mockWriter, postRequest)
+
+	assert.Equal(t, "400", responseHeader.Get(responseStatusHeader), responseCheckFor400)
+	assert.Equal(t, restoredMepStateRecords, rolledBackMepStatePaths)
+	assert.Equal(t, []string{getMepStateSnapshot().Services[0].Service.ServiceId}, rolledBackMepStateServices)
+	assert.Equal(t, 0, len(restoredMepStateTasks))
+	mockWriter.AssertExpectations(t)
+}
+
+// Snapshot larger than the limit is rejected without reading the whole body
+func TestImportMepStateTooLarge(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mm5Service{}
+	body := &countingReader{limit: util.MaxMepStateSnapshotLength * 2}
+	postRequest, _ := http.NewRequest("POST", mepStateUrl, body)
+
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 400)
+
+	// 15 is the order of the mep state import handler in the URLPattern
+	service.URLPatterns()[15].Func(mockWriter, postRequest)
+
+	assert.Equal(t, "400", responseHeader.Get(responseStatusHeader), responseCheckFor400)
+	assert.Equal(t, util.MaxMepStateSnapshotLength+1, body.read)
+	mockWriter.AssertExpectations(t)
+}
+
+// Reader producing the given number of bytes, keeps the count of the bytes read
+type countingReader struct {
+	limit int
+	read  int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	if r.read >= r.limit {
+		return 0, io.EOF
+	}
+	n := len(p)
+	if n > r.limit-r.read {
+		n = r.limit - r.read
+	}
+	for i := 0; i < n; i++ {
+		p[i] = ' '
+	}
+	r.read += n
+	return n, nil
+}
+
+// Import is rejected when the mep already has a state
+func TestImportMepStateNotEmpty(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mm5Service{}
+	snapshotBytes, _ := json.Marshal(getMepStateSnapshot())
+	postRequest, _ := http.NewRequest("POST", mepStateUrl, bytes.NewReader(snapshotBytes))
+
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 403)
+
+	patches := gomonkey.ApplyFunc(backend.GetRecords, func(path string) (map[string][]byte, int) {
+		return map[string][]byte{subscriberId1: []byte("{}")}, 0
+	})
+	defer patches.Reset()
+
+	// 15 is the order of the mep state import handler in the URLPattern
+	service.URLPatterns()[15].Func(mockWriter, postRequest)
+
+	assert.Equal(t, "403", responseHeader.Get(responseStatusHeader), "Response status code must be 403")
+	mockWriter.AssertExpectations(t)
+}
@@ -38,11 +38,16 @@ type MepSpace struct {
 	DNSRuleId     string          `json:"dnsRuleId"`
 	CapabilityId  string          `json:"capabilityId"`
 	TaskId        string          `json:"taskId"`
+	DryRun        bool            `json:"dryRun"`
+	CallbackUri   string          `json:"callbackUri"`
 	QueryParam    url.Values      `json:"queryParam"`
 	CoreRequest   interface{}     `json:"coreRequest"`
 	CoreRsp       interface{}     `json:"coreRsp"`
 	HttPErrInf    *proto.Response `json:"httpErrInf"`
 	HttPRsp       interface{}     `json:"httpRsp"`
+	RspStatusCode int             `json:"rspStatusCode"`
+	// Termination accepted and the app is torn down in the background
+	TerminationAccepted bool `json:"terminationAccepted"`
 }
 
 // new a work space
@@ -20,6 +20,7 @@ import (
 	"mepserver/mm5/task"
 	"net/http"
 	"os"
+	"time"
 )
 
 type DecodeAppTerminationReq struct {
@@ -58,16 +59,205 @@ func (t *DecodeAppTerminationReq) GetFindParam(r *http.Request) (context.Context
 	return Ctx, nil
 }
 
+type NotifyAppTermination struct {
+	workspace.TaskBase
+	Ctx                 context.Context `json:"ctx,in"`
+	AppInstanceId       string          `json:"appInstanceId,in"`
+	TerminationAccepted bool            `json:"terminationAccepted,out"`
+	RspStatusCode       int             `json:"rspStatusCode,out"`
+	HttpRsp             interface{}     `json:"httpRsp,out"`
+	gracefulTimeout     int
+	notifier            func(uri string, body []byte) error
+	teardown            func(ctx context.Context, appInstanceId string)
+}
+
+// Graceful timeout in seconds, default timeout is used if it is not set
+func (t *NotifyAppTermination) WithGracefulTimeout(gracefulTimeout int) *NotifyAppTermination {
+	t.gracefulTimeout = gracefulTimeout
+	return t
+}
+
+// Notification sender, the notification is sent to the callback or the websocket of the subscription if it is not set
+func (t *NotifyAppTermination) WithNotifier(notifier func(uri string, body []byte) error) *NotifyAppTermination {
+	t.notifier = notifier
+	return t
+}
+
+// Tear down of the app run after the graceful wait
+func (t *NotifyAppTermination) WithTeardown(teardown func(ctx context.Context,
+	appInstanceId string)) *NotifyAppTermination {
+	t.teardown = teardown
+	return t
+}
+
+// OnRequest notifies the termination subscribers of the app and accepts the termination, the app is torn down in the
+// background once the app confirms the termination or the graceful timeout expires. App without the termination
+// subscribers is torn down right away by the following plans.
+func (t *NotifyAppTermination) OnRequest(data string) workspace.TaskCode {
+	records, errCode := backend.GetRecords(meputil.EndAppSubKeyPath + t.AppInstanceId + "/")
+	if errCode != 0 {
+		log.Errorf(nil, "get termination subscriptions from data-store failed")
+		return workspace.TaskFinish
+	}
+	subscriptions := make(map[string]*models.AppTerminationNotificationSubscription)
+	for subscriptionId, record := range records {
+		subscription := &models.AppTerminationNotificationSubscription{}
+		if err := json.Unmarshal(record, subscription); err != nil ||
+			subscription.SubscriptionType != meputil.AppTerminationNotificationSubscription {
+			log.Warnf("failed to parse the termination subscription(%s)", subscriptionId)
+			continue
+		}
+		subscriptions[subscriptionId] = subscription
+	}
+	if len(subscriptions) == 0 || t.teardown == nil {
+		log.Infof("No termination subscription for the app(%s)", t.AppInstanceId)
+		return workspace.TaskFinish
+	}
+
+	gracefulTimeout := t.gracefulTimeout
+	if gracefulTimeout == 0 {
+		gracefulTimeout = meputil.DefaultGracefulTimeout
+	}
+
+	statusPath := meputil.AppTerminationKeyPath + t.AppInstanceId
+	deadline := time.Now().Add(time.Duration(gracefulTimeout) * time.Second)
+	statusBytes, _ := json.Marshal(&models.AppTerminationStatus{
+		OperationAction: meputil.OperationActionTerminating,
+		Deadline:        deadline.Unix(),
+		Domain:          util.ParseTargetDomain(t.Ctx),
+		Project:         util.ParseTargetProject(t.Ctx),
+	})
+	// Status is created only once, a repeated termination during the graceful wait is accepted without notifying the
+	// app again
+	errCode = backend.PutRecordIfRevision(statusPath, statusBytes, 0)
+	if errCode == meputil.RecordModifiedErr {
+		log.Infof("Termination of the app(%s) is already in progress", t.AppInstanceId)
+		t.acceptTermination()
+		return workspace.TaskFinish
+	} else if errCode != 0 {
+		log.Errorf(nil, "termination status write failed, app(%s) is terminated without notification",
+			t.AppInstanceId)
+		return workspace.TaskFinish
+	}
+
+	notifier := t.notifier
+	if notifier == nil {
+		notifier = meputil.SendNotification
+	}
+	for subscriptionId, subscription := range subscriptions {
+		notification := models.AppTerminationNotification{
+			NotificationType:   meputil.AppTerminationNotification,
+			OperationAction:    meputil.OperationActionTerminating,
+			MaxGracefulTimeout: uint32(gracefulTimeout),
+			Links: models.AppTerminationNotificationLinks{
+				Subscription: models.SerLinkType{Href: fmt.Sprintf("%s/applications/%s/subscriptions/%s",
+					meputil.MecAppSupportPath, t.AppInstanceId, subscriptionId)},
+				ConfirmTermination: models.SerLinkType{Href: fmt.Sprintf("%s/applications/%s/confirm_termination",
+					meputil.MecAppSupportPath, t.AppInstanceId)},
+			},
+		}
+		notificationBytes, err := json.Marshal(&notification)
+		if err != nil {
+			continue
+		}
+		go func(callbackUri string) {
+			if err := notifier(callbackUri, notificationBytes); err != nil {
+				log.Errorf(err, "failed to send the termination notification to %s", callbackUri)
+			}
+		}(subscription.NotificationUri())
+	}
+
+	go awaitTermination(t.Ctx, t.AppInstanceId, deadline, t.teardown)
+
+	t.acceptTermination()
+	return workspace.TaskFinish
+}
+
+func (t *NotifyAppTermination) acceptTermination() {
+	t.TerminationAccepted = true
+	t.RspStatusCode = http.StatusAccepted
+	t.HttpRsp = ""
+}
+
+// Resume the graceful wait of the terminations interrupted by a previous run of the mep-server, the app is torn down
+// right away if its graceful timeout expired meanwhile
+func ResumeAppTerminations(teardown func(ctx context.Context, appInstanceId string)) {
+	// Wait for the data-store to be available
+	backend.WaitForReady()
+	resumeAppTerminations(teardown)
+}
+
+func resumeAppTerminations(teardown func(ctx context.Context, appInstanceId string)) {
+	records, errCode := backend.GetRecords(meputil.AppTerminationKeyPath)
+	if errCode != 0 {
+		log.Errorf(nil, "retrieve pending terminations from data-store failed")
+		return
+	}
+	for appInstanceId, record := range records {
+		// Status which can't be parsed has no deadline, the app is torn down right away
+		status := &models.AppTerminationStatus{}
+		if err := json.Unmarshal(record, status); err != nil {
+			log.Warnf("failed to parse the termination status(app-id: %s)", appInstanceId)
+		}
+		ctx := util.SetTargetDomainProject(context.Background(), status.Domain, status.Project)
+		go awaitTermination(ctx, appInstanceId, time.Unix(status.Deadline, 0), teardown)
+	}
+}
+
+// Wait for the confirmation of the app till the deadline and tear down the app either way
+func awaitTermination(ctx context.Context, appInstanceId string, deadline time.Time,
+	teardown func(ctx context.Context, appInstanceId string)) {
+	log.Infof("Waiting till %s for the app(%s) to confirm the termination", deadline.Format(time.RFC3339),
+		appInstanceId)
+	statusPath := meputil.AppTerminationKeyPath + appInstanceId
+	ticker := time.NewTicker(meputil.TerminationConfirmPollInterval)
+	defer ticker.Stop()
+	for {
+		if isTerminationConfirmed(statusPath) {
+			log.Infof("App(%s) confirmed the termination", appInstanceId)
+			break
+		}
+		if !time.Now().Before(deadline) {
+			log.Warnf("App(%s) didn't confirm the termination in time", appInstanceId)
+			break
+		}
+		<-ticker.C
+	}
+
+	// Confirmation is written only on the existing status, hence a late confirmation can't leave the status behind
+	if errCode := backend.DeleteRecord(statusPath); errCode != 0 {
+		log.Errorf(nil, "termination status(app-id: %s) delete failed", appInstanceId)
+	}
+	teardown(ctx, appInstanceId)
+}
+
+func isTerminationConfirmed(statusPath string) bool {
+	statusBytes, errCode := backend.GetRecord(statusPath)
+	if errCode != 0 {
+		return false
+	}
+	status := &models.AppTerminationStatus{}
+	if err := json.Unmarshal(statusBytes, status); err != nil {
+		return false
+	}
+	return status.Confirmed
+}
+
 type DeleteService struct {
 	workspace.TaskBase
-	Ctx           context.Context `json:"ctx,in"`
-	HttpErrInf    *proto.Response `json:"httpErrInf,out"`
-	HttpRsp       interface{}     `json:"httpRsp,out"`
-	AppInstanceId string          `json:"appInstanceId,in"`
+	Ctx                 context.Context `json:"ctx,in"`
+	HttpErrInf          *proto.Response `json:"httpErrInf,out"`
+	HttpRsp             interface{}     `json:"httpRsp,out"`
+	AppInstanceId       string          `json:"appInstanceId,in"`
+	TerminationAccepted bool            `json:"terminationAccepted,in"`
 }
 
 // OnRequest
 func (t *DeleteService) OnRequest(data string) workspace.TaskCode {
+	// App is torn down in the background after the graceful wait
+	if t.TerminationAccepted {
+		return workspace.TaskFinish
+	}
 	log.Info("Deleting service")
 	resp, errInt := backend.GetRecords("/cse-sr/inst/files///")
 	if errInt != 0 {
@@ -142,11 +332,16 @@ func checkErr(response *proto.UnregisterInstanceResponse, err error) (int, strin
 
 type DeleteFromMepauth struct {
 	workspace.TaskBase
-	AppInstanceId string `json:"appInstanceId,in"`
+	AppInstanceId       string `json:"appInstanceId,in"`
+	TerminationAccepted bool   `json:"terminationAccepted,in"`
 }
 
 // OnRequest
 func (t *DeleteFromMepauth) OnRequest(data string) workspace.TaskCode {
+	// App is torn down in the background after the graceful wait
+	if t.TerminationAccepted {
+		return workspace.TaskFinish
+	}
 	log.Info("Deleting the mepauth")
 	mepauthPort := os.Getenv("MEPAUTH_SERVICE_PORT")
 	if mepauthPort == "" {
@@ -218,10 +413,11 @@ func (t *DeleteFromMepauth) TlsConfig() (*tls.Config, error) {
 
 type DeleteAppDConfigWithSync struct {
 	workspace.TaskBase
-	Ctx           context.Context `json:"ctx,in"`
-	AppInstanceId string          `json:"appInstanceId,in"`
-	HttpRsp       interface{}     `json:"httpRsp,out"`
-	Worker        *task.Worker
+	Ctx                 context.Context `json:"ctx,in"`
+	AppInstanceId       string          `json:"appInstanceId,in"`
+	TerminationAccepted bool            `json:"terminationAccepted,in"`
+	HttpRsp             interface{}     `json:"httpRsp,out"`
+	Worker              *task.Worker
 }
 
 func (t *DeleteAppDConfigWithSync) WithWorker(w *task.Worker) *DeleteAppDConfigWithSync {
@@ -230,6 +426,10 @@ func (t *DeleteAppDConfigWithSync) WithWorker(w *task.Worker) *DeleteAppDConfigW
 }
 
 func (t *DeleteAppDConfigWithSync) OnRequest(data string) workspace.TaskCode {
+	// App is torn down in the background after the graceful wait
+	if t.TerminationAccepted {
+		return workspace.TaskFinish
+	}
 
 	log.Info("Deleting the DNS and traffic rule")
 	/*
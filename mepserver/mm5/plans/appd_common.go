@@ -26,6 +26,8 @@ import (
 	meputil "mepserver/common/util"
 	"net/http"
 	"reflect"
+	"sort"
+	"time"
 )
 
 const DBFailure = "put app config rule to data-store failed"
@@ -79,36 +81,74 @@ func IsAnyOngoingOperationExist(appInstanceId string) (isExists bool) {
 	return false
 }
 
+// Validate the traffic rules against the capabilities reported by the data-plane
+func validateTrafficRulesCapability(capabilities *dataplane.Capabilities, rules []dataplane.TrafficRule) error {
+	for i := range rules {
+		if err := capabilities.ValidateTrafficRule(&rules[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func GenerateTaskResponse(taskId string, appInstanceId string, result string, percent string, details string) (progress models.TaskProgress) {
 	return models.TaskProgress{
 		TaskId: taskId, AppInstanceId: appInstanceId, ConfigResult: result, ConfigPhase: percent, Details: details,
 	}
 }
 
-func UpdateProcessingDatabase(appInstanceId string, taskId string, appDConfigInput *models.AppDConfig) (code workspace.ErrCode, msg string) {
+// Validate the request against the data-store and build the task status for it. Nothing is written to the
+// data-store, hence it is used for the dry-run also.
+func ValidateAppDConfigRequest(appInstanceId string, appDConfigInput *models.AppDConfig) (*models.AppDConfig,
+	*models.TaskStatus, workspace.ErrCode, string) {
 	appDInStore := &models.AppDConfig{}
 	// Table already exists for modify and delete request, hence reading db for non post scenarios
 	if appDConfigInput.Operation != http.MethodPost {
 		appDConfigEntry, errCode := backend.GetRecord(meputil.AppDConfigKeyPath + appInstanceId)
 		if errCode != 0 {
 			log.Errorf(nil, "app config (appId: %s) retrieval from data-store failed!", appInstanceId)
-			return workspace.ErrCode(errCode), "get app config rule from data-store failed"
+			return nil, nil, workspace.ErrCode(errCode), "get app config rule from data-store failed"
 		}
 		err := json.Unmarshal(appDConfigEntry, appDInStore)
 		if err != nil {
 			log.Errorf(err, "failed to parse the appd config from data-store")
-			return meputil.OperateDataWithEtcdErr, "parsing app config rule from data-store failed"
+			return nil, nil, meputil.OperateDataWithEtcdErr, "parsing app config rule from data-store failed"
 		}
 	}
 	if appDConfigInput.Operation == http.MethodPut && appDConfigInput.AppName != appDInStore.AppName {
 		log.Errorf(nil, "app-name miss-match")
-		return meputil.OperateDataWithEtcdErr, "app-name doesn't match"
+		return nil, nil, meputil.OperateDataWithEtcdErr, "app-name doesn't match"
+	}
+
+	taskStatus := buildTaskStatus(appDConfigInput, appDInStore)
+	if taskStatus.TrafficRuleStatusLst == nil && taskStatus.DNSRuleStatusLst == nil {
+		log.Errorf(nil, "no modification found")
+		return nil, nil, meputil.SubscriptionNotFound, "no modification data found in the input"
+	}
+
+	// Check any duplicate dns entry exists
+	if isDuplicateDomainNameForCreateExists(appInstanceId, appDConfigInput, taskStatus) {
+		log.Errorf(nil, "duplicate dns entry found in the request")
+		return nil, nil, meputil.DuplicateOperation, "duplicate dns entry"
 	}
+	return appDInStore, taskStatus, 0, ""
+}
+
+func UpdateProcessingDatabase(appInstanceId string, taskId string, appDConfigInput *models.AppDConfig) (code workspace.ErrCode, msg string) {
+	appDInStore, taskStatus, code, msg := ValidateAppDConfigRequest(appInstanceId, appDConfigInput)
+	if code != 0 {
+		return code, msg
+	}
+
+	taskStatus.CallbackUri = appDConfigInput.CallbackUri
 
 	var err error
 	var appDConfigBytes []byte
+	// Task id is kept along with the job to resume the processing on restart
+	appDConfigInput.TaskId = taskId
 	if appDConfigInput.Operation == http.MethodDelete {
 		appDInStore.Operation = appDConfigInput.Operation
+		appDInStore.TaskId = taskId
 		appDConfigBytes, err = json.Marshal(appDInStore)
 
 		// App name is required to build the url for data-plane
@@ -122,8 +162,13 @@ func UpdateProcessingDatabase(appInstanceId string, taskId string, appDConfigInp
 		return meputil.ParseInfoErr, "can not marshal appDConfig info"
 	}
 
-	// Add to Jobs DB
-	errCode := backend.PutRecord(meputil.AppDLCMJobsPath+appInstanceId, appDConfigBytes)
+	// Add to Jobs DB only if no job exists, a concurrent request for the app might have passed the ongoing operation
+	// check as well
+	errCode := backend.PutRecordIfRevision(meputil.AppDLCMJobsPath+appInstanceId, appDConfigBytes, 0)
+	if errCode == meputil.RecordModifiedErr {
+		log.Errorf(nil, "app instance(%s) has other operation in progress", appInstanceId)
+		return meputil.ForbiddenOperation, "app instance has other operation in progress"
+	}
 	if errCode != 0 {
 		log.Errorf(nil, "app config (appId: %s) insertion on data-store failed!", appInstanceId)
 		return workspace.ErrCode(errCode), DBFailure
@@ -136,27 +181,10 @@ func UpdateProcessingDatabase(appInstanceId string, taskId string, appDConfigInp
 		return workspace.ErrCode(errCode), DBFailure
 	}
 
-	taskStatus := buildTaskStatus(appDConfigInput, appDInStore)
-	if taskStatus.TrafficRuleStatusLst == nil && taskStatus.DNSRuleStatusLst == nil {
-		_ = backend.DeletePaths([]string{meputil.AppDLCMJobsPath + appInstanceId, meputil.AppDLCMTasksPath + taskId},
-			true)
-		log.Errorf(nil, "no modification found")
-		return meputil.SubscriptionNotFound, "no modification data found in the input"
-	}
-
-	return putInDB(taskStatus, appInstanceId, taskId, appDConfigInput)
+	return putInDB(taskStatus, appInstanceId, taskId)
 }
 
-func putInDB(taskStatus *models.TaskStatus, appInstanceId string, taskId string,
-	appDConfigInput *models.AppDConfig) (code workspace.ErrCode, msg string) {
-	// Check any duplicate dns entry exists
-	if isDuplicateDomainNameForCreateExists(appInstanceId, appDConfigInput, taskStatus) {
-		_ = backend.DeletePaths([]string{meputil.AppDLCMJobsPath + appInstanceId, meputil.AppDLCMTasksPath + taskId},
-			true)
-		log.Errorf(nil, "duplicate dns entry found in the request")
-		return meputil.DuplicateOperation, "duplicate dns entry"
-	}
-
+func putInDB(taskStatus *models.TaskStatus, appInstanceId string, taskId string) (code workspace.ErrCode, msg string) {
 	statusBytes, err := json.Marshal(taskStatus)
 	if err != nil {
 		_ = backend.DeletePaths([]string{meputil.AppDLCMJobsPath + appInstanceId, meputil.AppDLCMTasksPath + taskId},
@@ -176,9 +204,39 @@ func putInDB(taskStatus *models.TaskStatus, appInstanceId string, taskId string,
 	return 0, ""
 }
 
+// Validate the request and respond with the rule operations, without writing anything or starting the task
+func dryRunAppDConfig(appInstanceId string, appDConfigInput *models.AppDConfig) (interface{}, workspace.ErrCode,
+	string) {
+	_, taskStatus, errCode, msg := ValidateAppDConfigRequest(appInstanceId, appDConfigInput)
+	if errCode != 0 {
+		return nil, errCode, msg
+	}
+	log.Infof("AppD config dry-run succeeded(appId: %s, method: %s)", appInstanceId, appDConfigInput.Operation)
+	return BuildAppDConfigPlan(appInstanceId, taskStatus), 0, ""
+}
+
+// Build the per rule operation plan of the request for the dry-run response
+func BuildAppDConfigPlan(appInstanceId string, taskStatus *models.TaskStatus) models.AppDConfigPlan {
+	plan := models.AppDConfigPlan{AppInstanceId: appInstanceId, TrafficRules: make([]models.RulePlan, 0),
+		DNSRules: make([]models.RulePlan, 0)}
+	for _, ruleStatus := range taskStatus.TrafficRuleStatusLst {
+		plan.TrafficRules = append(plan.TrafficRules,
+			models.RulePlan{Id: ruleStatus.Id, Operation: meputil.OperTypeNames[ruleStatus.Method]})
+	}
+	for _, ruleStatus := range taskStatus.DNSRuleStatusLst {
+		plan.DNSRules = append(plan.DNSRules,
+			models.RulePlan{Id: ruleStatus.Id, Operation: meputil.OperTypeNames[ruleStatus.Method]})
+	}
+	sort.Slice(plan.TrafficRules, func(i, j int) bool { return plan.TrafficRules[i].Id < plan.TrafficRules[j].Id })
+	sort.Slice(plan.DNSRules, func(i, j int) bool { return plan.DNSRules[i].Id < plan.DNSRules[j].Id })
+	return plan
+}
+
 func buildTaskStatus(appDConfigInput *models.AppDConfig, appDInStore *models.AppDConfig) *models.TaskStatus {
 	var taskStatus = models.TaskStatus{}
 	taskStatus.Progress = 0
+	taskStatus.CreateTime = time.Now().Unix()
+	taskStatus.UpdateTime = taskStatus.CreateTime
 
 	if appDConfigInput.Operation == http.MethodPost {
 		// create works with only the input data
@@ -23,10 +23,13 @@ import (
 	"fmt"
 	"github.com/go-playground/validator/v10"
 	"io/ioutil"
+	"mepserver/common/extif/dataplane"
 	"mepserver/common/models"
 	meputil "mepserver/common/util"
 	"mepserver/mm5/task"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/apache/servicecomb-service-center/pkg/log"
 	"github.com/apache/servicecomb-service-center/pkg/util"
@@ -39,6 +42,9 @@ type DecodeAppDRestReq struct {
 	Ctx           context.Context `json:"ctx,out"`
 	AppInstanceId string          `json:"appInstanceId,out"`
 	RestBody      interface{}     `json:"restBody,out"`
+	DryRun        bool            `json:"dryRun,out"`
+	CallbackUri   string          `json:"callbackUri,out"`
+	QueryParam    url.Values      `json:"queryParam,out"`
 }
 
 func (t *DecodeAppDRestReq) OnRequest(data string) workspace.TaskCode {
@@ -62,7 +68,39 @@ func (t *DecodeAppDRestReq) WithBody(body interface{}) *DecodeAppDRestReq {
 func (t *DecodeAppDRestReq) getParam(r *http.Request) error {
 	queryReq, _ := meputil.GetHTTPTags(r)
 	t.AppInstanceId = queryReq.Get(":appInstanceId")
+	t.QueryParam = queryReq
 	t.Ctx = util.SetTargetDomainProject(r.Context(), r.Header.Get("X-Domain-Name"), queryReq.Get(":project"))
+
+	// Dry-run validates the request and returns the rule operations without applying it
+	if dryRun := queryReq.Get("dryRun"); len(dryRun) != 0 {
+		var err error
+		t.DryRun, err = strconv.ParseBool(dryRun)
+		if err != nil {
+			t.SetFirstErrorCode(meputil.RequestParamErr, "invalid dry run value")
+			return err
+		}
+	}
+
+	// Final task progress is posted to the callback uri when the task succeeds or fails
+	t.CallbackUri = queryReq.Get("callbackUri")
+	if len(t.CallbackUri) != 0 {
+		if err := validateCallbackUri(t.CallbackUri); err != nil {
+			t.SetFirstErrorCode(meputil.RequestParamErr, err.Error())
+			return err
+		}
+	}
+	return nil
+}
+
+// Callback uri must be an absolute https uri, the server certificate is verified on delivery
+func validateCallbackUri(callbackUri string) error {
+	if len(callbackUri) > meputil.MaxCallbackUriLength {
+		return errors.New("callback uri too long")
+	}
+	uri, err := url.ParseRequestURI(callbackUri)
+	if err != nil || uri.Scheme != "https" || len(uri.Host) == 0 {
+		return errors.New("invalid callback uri")
+	}
 	return nil
 }
 
@@ -99,10 +137,8 @@ func (t *DecodeAppDRestReq) parseBody(r *http.Request) error {
 		return errors.New("json unmarshalling failed")
 	}
 
-	appDConfigInput, _ := t.RestBody.(*models.AppDConfig)
-
 	validate := validator.New()
-	verrs := validate.Struct(appDConfigInput)
+	verrs := validate.Struct(t.RestBody)
 	if verrs != nil {
 		errorString := "Invalid value for input on: "
 		for _, verr := range verrs.(validator.ValidationErrors) {
@@ -149,8 +185,11 @@ type CreateAppDConfig struct {
 	W             http.ResponseWriter `json:"w,in"`
 	AppInstanceId string              `json:"appInstanceId,in"`
 	RestBody      interface{}         `json:"restBody,in"`
+	DryRun        bool                `json:"dryRun,in"`
+	CallbackUri   string              `json:"callbackUri,in"`
 	HttpRsp       interface{}         `json:"httpRsp,out"`
 	worker        *task.Worker
+	capabilities  *dataplane.Capabilities
 }
 
 func (t *CreateAppDConfig) WithWorker(w *task.Worker) *CreateAppDConfig {
@@ -158,6 +197,11 @@ func (t *CreateAppDConfig) WithWorker(w *task.Worker) *CreateAppDConfig {
 	return t
 }
 
+func (t *CreateAppDConfig) WithCapabilities(capabilities *dataplane.Capabilities) *CreateAppDConfig {
+	t.capabilities = capabilities
+	return t
+}
+
 func (t *CreateAppDConfig) OnRequest(data string) workspace.TaskCode {
 
 	appDConfigInput, ok := t.RestBody.(*models.AppDConfig)
@@ -191,7 +235,14 @@ func (t *CreateAppDConfig) OnRequest(data string) workspace.TaskCode {
 		return workspace.TaskFinish
 	}
 
+	if err := validateTrafficRulesCapability(t.capabilities, appDConfigInput.AppTrafficRule); err != nil {
+		log.Errorf(err, "traffic rule not supported by data-plane")
+		t.SetFirstErrorCode(meputil.RequestParamErr, err.Error())
+		return workspace.TaskFinish
+	}
+
 	appDConfigInput.Operation = http.MethodPost
+	appDConfigInput.CallbackUri = t.CallbackUri
 
 	// Change the IP Address type to type common for MP2 and MP1
 	for i, _ := range appDConfigInput.AppDNSRule {
@@ -202,6 +253,16 @@ func (t *CreateAppDConfig) OnRequest(data string) workspace.TaskCode {
 		}
 	}
 
+	if t.DryRun {
+		var errCode workspace.ErrCode
+		var msg string
+		t.HttpRsp, errCode, msg = dryRunAppDConfig(t.AppInstanceId, appDConfigInput)
+		if errCode != 0 {
+			t.SetFirstErrorCode(errCode, msg)
+		}
+		return workspace.TaskFinish
+	}
+
 	// Add to Task InstanceID mapping DB
 	taskId := meputil.GenerateUniqueId()
 
@@ -33,6 +33,7 @@ type DeleteAppDConfig struct {
 	W             http.ResponseWriter `json:"w,in"`
 	AppInstanceId string              `json:"appInstanceId,in"`
 	RestBody      interface{}         `json:"restBody,in"`
+	CallbackUri   string              `json:"callbackUri,in"`
 	HttpRsp       interface{}         `json:"httpRsp,out"`
 	worker        *task.Worker
 }
@@ -64,6 +65,7 @@ func (t *DeleteAppDConfig) OnRequest(data string) workspace.TaskCode {
 
 	var appDConfig models.AppDConfig
 	appDConfig.Operation = http.MethodDelete
+	appDConfig.CallbackUri = t.CallbackUri
 
 	taskId := meputil.GenerateUniqueId()
 
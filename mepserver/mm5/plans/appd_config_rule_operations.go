@@ -0,0 +1,214 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plans
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/apache/servicecomb-service-center/pkg/log"
+	"github.com/go-playground/validator/v10"
+
+	"mepserver/common/arch/workspace"
+	"mepserver/common/extif/backend"
+	"mepserver/common/extif/dataplane"
+	"mepserver/common/models"
+	meputil "mepserver/common/util"
+	"mepserver/mm5/task"
+)
+
+// Partial update of the AppD configuration, rules are added, replaced or removed by their id and the
+// resulting configuration is processed like a complete update
+type ApplyAppDRuleOperations struct {
+	workspace.TaskBase
+	Ctx           context.Context     `json:"ctx,in"`
+	W             http.ResponseWriter `json:"w,in"`
+	AppInstanceId string              `json:"appInstanceId,in"`
+	RestBody      interface{}         `json:"restBody,in"`
+	DryRun        bool                `json:"dryRun,in"`
+	CallbackUri   string              `json:"callbackUri,in"`
+	HttpRsp       interface{}         `json:"httpRsp,out"`
+	worker        *task.Worker
+	capabilities  *dataplane.Capabilities
+}
+
+func (t *ApplyAppDRuleOperations) WithWorker(w *task.Worker) *ApplyAppDRuleOperations {
+	t.worker = w
+	return t
+}
+
+func (t *ApplyAppDRuleOperations) WithCapabilities(capabilities *dataplane.Capabilities) *ApplyAppDRuleOperations {
+	t.capabilities = capabilities
+	return t
+}
+
+func (t *ApplyAppDRuleOperations) OnRequest(data string) workspace.TaskCode {
+
+	ruleOperations, ok := t.RestBody.(*models.AppDRuleOperations)
+	if !ok {
+		t.SetFirstErrorCode(1, "input body parse failed")
+		t.SetSerErrInfo(&workspace.SerErrInfo{ErrCode: http.StatusBadRequest, Message: "Parse body error!"})
+		return workspace.TaskFinish
+	}
+	if len(ruleOperations.AppTrafficRule) == 0 && len(ruleOperations.AppDNSRule) == 0 {
+		log.Errorf(nil, "no rule operation in the request")
+		t.SetFirstErrorCode(meputil.RequestParamErr, "no rule operation in the request")
+		return workspace.TaskFinish
+	}
+
+	if IsAppInstanceIdAlreadyExists(t.AppInstanceId) == false {
+		log.Errorf(nil, "app instance not found")
+		t.SetFirstErrorCode(meputil.SerInstanceNotFound, "app instance not found")
+		return workspace.TaskFinish
+	}
+
+	// Check if any other ongoing operation for this AppInstance Id in the system.
+	if IsAnyOngoingOperationExist(t.AppInstanceId) == true {
+		log.Errorf(nil, "app instance has other operation in progress")
+		t.SetFirstErrorCode(meputil.ForbiddenOperation, "app instance has other operation in progress")
+		return workspace.TaskFinish
+	}
+
+	appDConfigEntry, errCode := backend.GetRecord(meputil.AppDConfigKeyPath + t.AppInstanceId)
+	if errCode != 0 {
+		log.Errorf(nil, "get appD config from data-store failed")
+		t.SetFirstErrorCode(workspace.ErrCode(errCode), "appD config retrieval failed")
+		return workspace.TaskFinish
+	}
+	appDConfigInput := &models.AppDConfig{}
+	if err := json.Unmarshal(appDConfigEntry, appDConfigInput); err != nil {
+		log.Errorf(nil, "failed to parse the appd config from data-store")
+		t.SetFirstErrorCode(meputil.OperateDataWithEtcdErr, "parse appd config from data-store failed")
+		return workspace.TaskFinish
+	}
+	appDConfigInput.Operation = ""
+	appDConfigInput.TaskId = ""
+	appDConfigInput.CallbackUri = t.CallbackUri
+
+	if err := applyRuleOperations(appDConfigInput, ruleOperations); err != nil {
+		log.Errorf(err, "apply rule operations failed")
+		t.SetFirstErrorCode(meputil.RequestParamErr, err.Error())
+		return workspace.TaskFinish
+	}
+	if err := validator.New().Struct(appDConfigInput); err != nil {
+		log.Errorf(err, "appD config validation failed after the rule operations")
+		t.SetFirstErrorCode(meputil.RequestParamErr, "invalid appD config after the rule operations")
+		return workspace.TaskFinish
+	}
+
+	httpRsp, updateErrCode, msg := startAppDConfigUpdate(t.AppInstanceId, appDConfigInput, t.worker,
+		t.capabilities, t.DryRun)
+	if updateErrCode != 0 {
+		t.SetFirstErrorCode(updateErrCode, msg)
+		return workspace.TaskFinish
+	}
+	t.HttpRsp = httpRsp
+	return workspace.TaskFinish
+}
+
+// Apply the rule operations in the request order on the AppD configuration
+func applyRuleOperations(appDConfig *models.AppDConfig, ruleOperations *models.AppDRuleOperations) error {
+	for _, operation := range ruleOperations.AppTrafficRule {
+		if err := applyTrafficRuleOperation(appDConfig, operation); err != nil {
+			return err
+		}
+	}
+	for _, operation := range ruleOperations.AppDNSRule {
+		if err := applyDNSRuleOperation(appDConfig, operation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyTrafficRuleOperation(appDConfig *models.AppDConfig, operation models.TrafficRuleOperation) error {
+	index := -1
+	for i, rule := range appDConfig.AppTrafficRule {
+		if rule.TrafficRuleID == operation.TrafficRuleID {
+			index = i
+			break
+		}
+	}
+
+	if operation.Op != meputil.RuleOperationRemove {
+		if operation.Value == nil {
+			return fmt.Errorf("value is required to %s traffic rule(%s)", operation.Op, operation.TrafficRuleID)
+		}
+		if operation.Value.TrafficRuleID != operation.TrafficRuleID {
+			return fmt.Errorf("traffic rule id mismatch in the value of traffic rule(%s)", operation.TrafficRuleID)
+		}
+	}
+
+	switch operation.Op {
+	case meputil.RuleOperationAdd:
+		if index != -1 {
+			return fmt.Errorf("traffic rule(%s) already exists", operation.TrafficRuleID)
+		}
+		appDConfig.AppTrafficRule = append(appDConfig.AppTrafficRule, *operation.Value)
+	case meputil.RuleOperationReplace:
+		if index == -1 {
+			return fmt.Errorf("traffic rule(%s) not found", operation.TrafficRuleID)
+		}
+		appDConfig.AppTrafficRule[index] = *operation.Value
+	case meputil.RuleOperationRemove:
+		if index == -1 {
+			return fmt.Errorf("traffic rule(%s) not found", operation.TrafficRuleID)
+		}
+		appDConfig.AppTrafficRule = append(appDConfig.AppTrafficRule[:index], appDConfig.AppTrafficRule[index+1:]...)
+	}
+	return nil
+}
+
+func applyDNSRuleOperation(appDConfig *models.AppDConfig, operation models.DNSRuleOperation) error {
+	index := -1
+	for i, rule := range appDConfig.AppDNSRule {
+		if rule.DNSRuleID == operation.DNSRuleID {
+			index = i
+			break
+		}
+	}
+
+	if operation.Op != meputil.RuleOperationRemove {
+		if operation.Value == nil {
+			return fmt.Errorf("value is required to %s dns rule(%s)", operation.Op, operation.DNSRuleID)
+		}
+		if operation.Value.DNSRuleID != operation.DNSRuleID {
+			return fmt.Errorf("dns rule id mismatch in the value of dns rule(%s)", operation.DNSRuleID)
+		}
+	}
+
+	switch operation.Op {
+	case meputil.RuleOperationAdd:
+		if index != -1 {
+			return fmt.Errorf("dns rule(%s) already exists", operation.DNSRuleID)
+		}
+		appDConfig.AppDNSRule = append(appDConfig.AppDNSRule, *operation.Value)
+	case meputil.RuleOperationReplace:
+		if index == -1 {
+			return fmt.Errorf("dns rule(%s) not found", operation.DNSRuleID)
+		}
+		appDConfig.AppDNSRule[index] = *operation.Value
+	case meputil.RuleOperationRemove:
+		if index == -1 {
+			return fmt.Errorf("dns rule(%s) not found", operation.DNSRuleID)
+		}
+		appDConfig.AppDNSRule = append(appDConfig.AppDNSRule[:index], appDConfig.AppDNSRule[index+1:]...)
+	}
+	return nil
+}
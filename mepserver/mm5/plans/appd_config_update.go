@@ -18,6 +18,7 @@ package plans
 
 import (
 	"context"
+	"mepserver/common/extif/dataplane"
 	"mepserver/common/models"
 	meputil "mepserver/common/util"
 	"mepserver/mm5/task"
@@ -33,8 +34,11 @@ type UpdateAppDConfig struct {
 	W             http.ResponseWriter `json:"w,in"`
 	AppInstanceId string              `json:"appInstanceId,in"`
 	RestBody      interface{}         `json:"restBody,in"`
+	DryRun        bool                `json:"dryRun,in"`
+	CallbackUri   string              `json:"callbackUri,in"`
 	HttpRsp       interface{}         `json:"httpRsp,out"`
 	worker        *task.Worker
+	capabilities  *dataplane.Capabilities
 }
 
 func (t *UpdateAppDConfig) WithWorker(w *task.Worker) *UpdateAppDConfig {
@@ -42,6 +46,11 @@ func (t *UpdateAppDConfig) WithWorker(w *task.Worker) *UpdateAppDConfig {
 	return t
 }
 
+func (t *UpdateAppDConfig) WithCapabilities(capabilities *dataplane.Capabilities) *UpdateAppDConfig {
+	t.capabilities = capabilities
+	return t
+}
+
 func (t *UpdateAppDConfig) OnRequest(data string) workspace.TaskCode {
 
 	appDConfigInput, ok := t.RestBody.(*models.AppDConfig)
@@ -69,6 +78,25 @@ func (t *UpdateAppDConfig) OnRequest(data string) workspace.TaskCode {
 		return workspace.TaskFinish
 	}
 
+	appDConfigInput.CallbackUri = t.CallbackUri
+	var errCode workspace.ErrCode
+	var msg string
+	t.HttpRsp, errCode, msg = startAppDConfigUpdate(t.AppInstanceId, appDConfigInput, t.worker, t.capabilities,
+		t.DryRun)
+	if errCode != 0 {
+		t.SetFirstErrorCode(errCode, msg)
+	}
+	return workspace.TaskFinish
+}
+
+// Validate the complete AppD configuration and start the update task, dry-run only returns the rule operations
+func startAppDConfigUpdate(appInstanceId string, appDConfigInput *models.AppDConfig, worker *task.Worker,
+	capabilities *dataplane.Capabilities, dryRun bool) (interface{}, workspace.ErrCode, string) {
+	if err := validateTrafficRulesCapability(capabilities, appDConfigInput.AppTrafficRule); err != nil {
+		log.Errorf(err, "traffic rule not supported by data-plane")
+		return nil, meputil.RequestParamErr, err.Error()
+	}
+
 	appDConfigInput.Operation = http.MethodPut
 	taskId := meputil.GenerateUniqueId()
 
@@ -81,14 +109,16 @@ func (t *UpdateAppDConfig) OnRequest(data string) workspace.TaskCode {
 		}
 	}
 
-	errCode, msg := UpdateProcessingDatabase(t.AppInstanceId, taskId, appDConfigInput)
+	if dryRun {
+		return dryRunAppDConfig(appInstanceId, appDConfigInput)
+	}
+
+	errCode, msg := UpdateProcessingDatabase(appInstanceId, taskId, appDConfigInput)
 	if errCode != 0 {
-		t.SetFirstErrorCode(errCode, msg)
-		return workspace.TaskFinish
+		return nil, errCode, msg
 	}
 
-	t.worker.StartNewTask(appDConfigInput.AppName, t.AppInstanceId, taskId)
+	worker.StartNewTask(appDConfigInput.AppName, appInstanceId, taskId)
 
-	t.HttpRsp = GenerateTaskResponse(taskId, t.AppInstanceId, "PROCESSING", "0", "Operation In progress")
-	return workspace.TaskFinish
+	return GenerateTaskResponse(taskId, appInstanceId, "PROCESSING", "0", "Operation In progress"), 0, ""
 }
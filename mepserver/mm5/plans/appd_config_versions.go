@@ -0,0 +1,196 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plans
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/apache/servicecomb-service-center/pkg/log"
+
+	"mepserver/common"
+	"mepserver/common/arch/workspace"
+	"mepserver/common/extif/dataplane"
+	"mepserver/common/models"
+	meputil "mepserver/common/util"
+	"mepserver/mm5/task"
+)
+
+type AppDConfigVersionsGet struct {
+	workspace.TaskBase
+	AppInstanceId string      `json:"appInstanceId,in"`
+	HttpRsp       interface{} `json:"httpRsp,out"`
+}
+
+func (t *AppDConfigVersionsGet) OnRequest(inputData string) workspace.TaskCode {
+	log.Debugf("query request arrived to fetch appD config versions for appId %s.", t.AppInstanceId)
+
+	if IsAppInstanceIdAlreadyExists(t.AppInstanceId) == false {
+		log.Errorf(nil, "app instance not found")
+		t.SetFirstErrorCode(meputil.SerInstanceNotFound, "app instance not found")
+		return workspace.TaskFinish
+	}
+
+	versions, errCode := common.GetAppDConfigVersions(t.AppInstanceId)
+	if errCode != 0 {
+		t.SetFirstErrorCode(workspace.ErrCode(errCode), "appD config versions retrieval failed")
+		return workspace.TaskFinish
+	}
+
+	// Latest version first
+	for i, j := 0, len(versions)-1; i < j; i, j = i+1, j-1 {
+		versions[i], versions[j] = versions[j], versions[i]
+	}
+	t.HttpRsp = versions
+	return workspace.TaskFinish
+}
+
+type AppDConfigVersionDiffGet struct {
+	workspace.TaskBase
+	AppInstanceId string      `json:"appInstanceId,in"`
+	QueryParam    url.Values  `json:"queryParam,in"`
+	HttpRsp       interface{} `json:"httpRsp,out"`
+}
+
+func (t *AppDConfigVersionDiffGet) OnRequest(inputData string) workspace.TaskCode {
+	fromVersion, err := parseQueryInt64(t.QueryParam, "from", 0)
+	if err != nil || fromVersion == 0 {
+		t.SetFirstErrorCode(meputil.RequestParamErr, "invalid from version")
+		return workspace.TaskFinish
+	}
+	toVersion, err := parseQueryInt64(t.QueryParam, "to", 0)
+	if err != nil || toVersion == 0 {
+		t.SetFirstErrorCode(meputil.RequestParamErr, "invalid to version")
+		return workspace.TaskFinish
+	}
+
+	if IsAppInstanceIdAlreadyExists(t.AppInstanceId) == false {
+		log.Errorf(nil, "app instance not found")
+		t.SetFirstErrorCode(meputil.SerInstanceNotFound, "app instance not found")
+		return workspace.TaskFinish
+	}
+
+	versions, errCode := common.GetAppDConfigVersions(t.AppInstanceId)
+	if errCode != 0 {
+		t.SetFirstErrorCode(workspace.ErrCode(errCode), "appD config versions retrieval failed")
+		return workspace.TaskFinish
+	}
+	from := findAppDConfigVersion(versions, int(fromVersion))
+	to := findAppDConfigVersion(versions, int(toVersion))
+	if from == nil || to == nil {
+		log.Errorf(nil, "appD config version not found")
+		t.SetFirstErrorCode(meputil.SubscriptionNotFound, "appD config version not found")
+		return workspace.TaskFinish
+	}
+
+	// Diff is the rule operations of an update from the old version to the new one
+	fromConfig := from.AppDConfig
+	toConfig := to.AppDConfig
+	toConfig.Operation = http.MethodPut
+	plan := BuildAppDConfigPlan(t.AppInstanceId, buildTaskStatus(&toConfig, &fromConfig))
+
+	t.HttpRsp = models.AppDConfigVersionDiff{
+		AppInstanceId: t.AppInstanceId,
+		FromVersion:   from.Version,
+		ToVersion:     to.Version,
+		TrafficRules:  plan.TrafficRules,
+		DNSRules:      plan.DNSRules,
+	}
+	return workspace.TaskFinish
+}
+
+func findAppDConfigVersion(versions []models.AppDConfigVersion, version int) *models.AppDConfigVersion {
+	for i := range versions {
+		if versions[i].Version == version {
+			return &versions[i]
+		}
+	}
+	return nil
+}
+
+// Roll back to an earlier version, it is processed as a complete update with the configuration of the version
+type RollbackAppDConfig struct {
+	workspace.TaskBase
+	Ctx           context.Context     `json:"ctx,in"`
+	W             http.ResponseWriter `json:"w,in"`
+	AppInstanceId string              `json:"appInstanceId,in"`
+	RestBody      interface{}         `json:"restBody,in"`
+	DryRun        bool                `json:"dryRun,in"`
+	CallbackUri   string              `json:"callbackUri,in"`
+	HttpRsp       interface{}         `json:"httpRsp,out"`
+	worker        *task.Worker
+	capabilities  *dataplane.Capabilities
+}
+
+func (t *RollbackAppDConfig) WithWorker(w *task.Worker) *RollbackAppDConfig {
+	t.worker = w
+	return t
+}
+
+func (t *RollbackAppDConfig) WithCapabilities(capabilities *dataplane.Capabilities) *RollbackAppDConfig {
+	t.capabilities = capabilities
+	return t
+}
+
+func (t *RollbackAppDConfig) OnRequest(data string) workspace.TaskCode {
+
+	rollback, ok := t.RestBody.(*models.AppDConfigRollback)
+	if !ok {
+		t.SetFirstErrorCode(1, "input body parse failed")
+		t.SetSerErrInfo(&workspace.SerErrInfo{ErrCode: http.StatusBadRequest, Message: "Parse body error!"})
+		return workspace.TaskFinish
+	}
+
+	if IsAppInstanceIdAlreadyExists(t.AppInstanceId) == false {
+		log.Errorf(nil, "app instance not found")
+		t.SetFirstErrorCode(meputil.SerInstanceNotFound, "app instance not found")
+		return workspace.TaskFinish
+	}
+
+	// Check if any other ongoing operation for this AppInstance Id in the system.
+	if IsAnyOngoingOperationExist(t.AppInstanceId) == true {
+		log.Errorf(nil, "app instance has other operation in progress")
+		t.SetFirstErrorCode(meputil.ForbiddenOperation, "app instance has other operation in progress")
+		return workspace.TaskFinish
+	}
+
+	versions, errCode := common.GetAppDConfigVersions(t.AppInstanceId)
+	if errCode != 0 {
+		t.SetFirstErrorCode(workspace.ErrCode(errCode), "appD config versions retrieval failed")
+		return workspace.TaskFinish
+	}
+	version := findAppDConfigVersion(versions, rollback.Version)
+	if version == nil {
+		log.Errorf(nil, "appD config version %d not found", rollback.Version)
+		t.SetFirstErrorCode(meputil.SubscriptionNotFound, fmt.Sprintf("version(%d) not found", rollback.Version))
+		return workspace.TaskFinish
+	}
+	log.Infof("AppD config rollback requested(appId: %s, version: %d)", t.AppInstanceId, version.Version)
+
+	appDConfigInput := version.AppDConfig
+	appDConfigInput.CallbackUri = t.CallbackUri
+	httpRsp, updateErrCode, msg := startAppDConfigUpdate(t.AppInstanceId, &appDConfigInput, t.worker,
+		t.capabilities, t.DryRun)
+	if updateErrCode != 0 {
+		t.SetFirstErrorCode(updateErrCode, msg)
+		return workspace.TaskFinish
+	}
+	t.HttpRsp = httpRsp
+	return workspace.TaskFinish
+}
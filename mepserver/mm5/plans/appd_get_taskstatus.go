@@ -25,15 +25,17 @@ import (
 	"mepserver/common/extif/backend"
 	"mepserver/common/models"
 	meputil "mepserver/common/util"
+	"mepserver/mm5/task"
 	"net/http"
-	"strconv"
+	"net/url"
 )
 
 type DecodeTaskRestReq struct {
 	workspace.TaskBase
-	R      *http.Request   `json:"r,in"`
-	Ctx    context.Context `json:"ctx,out"`
-	TaskId string          `json:"taskId,out"`
+	R          *http.Request   `json:"r,in"`
+	Ctx        context.Context `json:"ctx,out"`
+	TaskId     string          `json:"taskId,out"`
+	QueryParam url.Values      `json:"queryParam,out"`
 }
 
 func (t *DecodeTaskRestReq) OnRequest(data string) workspace.TaskCode {
@@ -49,6 +51,7 @@ func (t *DecodeTaskRestReq) getParam(r *http.Request) error {
 	queryReq, _ := meputil.GetHTTPTags(r)
 
 	t.TaskId = queryReq.Get(":taskId")
+	t.QueryParam = queryReq
 
 	t.Ctx = util.SetTargetDomainProject(r.Context(), r.Header.Get("X-Domain-Name"), queryReq.Get(":project"))
 	return nil
@@ -89,20 +92,7 @@ func (t *TaskStatusGet) OnRequest(inputData string) workspace.TaskCode {
 		return workspace.TaskFinish
 	}
 
-	progress := (taskStatusInStore.Progress * 100) / (len(taskStatusInStore.TrafficRuleStatusLst) + len(taskStatusInStore.DNSRuleStatusLst))
-
-	var state string
-	if taskStatusInStore.Progress == (len(taskStatusInStore.TrafficRuleStatusLst) + len(taskStatusInStore.DNSRuleStatusLst)) {
-		state = meputil.TASK_STATE_SUCCESS
-	} else if taskStatusInStore.Progress >= 0 {
-		state = meputil.TASK_STATE_PROCESSING
-	} else {
-		state = meputil.TASK_STATE_FAILURE
-		progress = 0
-	}
-
-	t.HttpRsp = GenerateTaskResponse(t.TaskId, appInstInStore, state,
-		strconv.Itoa(progress), taskStatusInStore.Details)
+	t.HttpRsp = task.BuildTaskProgress(t.TaskId, appInstInStore, taskStatusInStore)
 
 	return workspace.TaskFinish
 }
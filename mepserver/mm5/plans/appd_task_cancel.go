@@ -0,0 +1,88 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package path implements mep server api plans
+package plans
+
+import (
+	"encoding/json"
+
+	"github.com/apache/servicecomb-service-center/pkg/log"
+
+	"mepserver/common/arch/workspace"
+	"mepserver/common/extif/backend"
+	"mepserver/common/models"
+	meputil "mepserver/common/util"
+	"mepserver/mm5/task"
+)
+
+type TaskCancel struct {
+	workspace.TaskBase
+	TaskId  string      `json:"taskId,in"`
+	HttpRsp interface{} `json:"httpRsp,out"`
+	worker  *task.Worker
+}
+
+func (t *TaskCancel) WithWorker(w *task.Worker) *TaskCancel {
+	t.worker = w
+	return t
+}
+
+func (t *TaskCancel) OnRequest(data string) workspace.TaskCode {
+	log.Debugf("cancel request arrived for the task %s.", t.TaskId)
+
+	taskEntry, errCode := backend.GetRecord(meputil.AppDLCMTasksPath + t.TaskId)
+	if errCode != 0 {
+		log.Errorf(nil, "get task rule from data-store failed")
+		t.SetFirstErrorCode(workspace.ErrCode(errCode), "task rule retrieval failed")
+		return workspace.TaskFinish
+	}
+	appInstanceId := string(taskEntry)
+
+	// Jobs DB holds the entry of the app till its ongoing task is finished
+	jobEntry, errCode := backend.GetRecord(meputil.AppDLCMJobsPath + appInstanceId)
+	if errCode == meputil.SubscriptionNotFound {
+		log.Errorf(nil, "task %s is already finished", t.TaskId)
+		t.SetFirstErrorCode(meputil.TaskFinishedErr, "task is already finished")
+		return workspace.TaskFinish
+	} else if errCode != 0 {
+		log.Errorf(nil, "get job from data-store failed")
+		t.SetFirstErrorCode(workspace.ErrCode(errCode), "job retrieval failed")
+		return workspace.TaskFinish
+	}
+	appDConfig := &models.AppDConfig{}
+	if err := json.Unmarshal(jobEntry, appDConfig); err != nil {
+		log.Errorf(nil, "failed to parse the job from data-store")
+		t.SetFirstErrorCode(meputil.OperateDataWithEtcdErr, "parse job from data-store failed")
+		return workspace.TaskFinish
+	}
+	if appDConfig.TaskId != t.TaskId {
+		log.Errorf(nil, "task %s is already finished", t.TaskId)
+		t.SetFirstErrorCode(meputil.TaskFinishedErr, "task is already finished")
+		return workspace.TaskFinish
+	}
+
+	// Task may finish after the job is read, cancel is accepted only while the task is not finished
+	if !t.worker.CancelTask(t.TaskId) {
+		log.Errorf(nil, "task %s is already finished", t.TaskId)
+		t.SetFirstErrorCode(meputil.TaskFinishedErr, "task is already finished")
+		return workspace.TaskFinish
+	}
+
+	t.HttpRsp = GenerateTaskResponse(t.TaskId, appInstanceId, meputil.TASK_STATE_PROCESSING, "0",
+		"Cancellation in progress")
+	return workspace.TaskFinish
+}
@@ -0,0 +1,178 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package path implements mep server api plans
+package plans
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apache/servicecomb-service-center/pkg/log"
+
+	"mepserver/common/arch/workspace"
+	"mepserver/common/extif/backend"
+	"mepserver/common/models"
+	meputil "mepserver/common/util"
+	"mepserver/mm5/task"
+)
+
+// Filters and paging of the task list query
+type taskListFilter struct {
+	appInstanceId string
+	state         string
+	startTime     int64
+	endTime       int64
+	offset        int
+	limit         int
+}
+
+type TaskListGet struct {
+	workspace.TaskBase
+	QueryParam url.Values  `json:"queryParam,in"`
+	HttpRsp    interface{} `json:"httpRsp,out"`
+}
+
+func (t *TaskListGet) OnRequest(inputData string) workspace.TaskCode {
+	log.Debug("query request arrived to fetch the task list.")
+
+	filter, err := parseTaskListFilter(t.QueryParam)
+	if err != nil {
+		log.Errorf(err, "invalid task list query")
+		t.SetFirstErrorCode(meputil.RequestParamErr, err.Error())
+		return workspace.TaskFinish
+	}
+
+	path := meputil.AppDLCMTaskStatusPath
+	if len(filter.appInstanceId) != 0 {
+		path += filter.appInstanceId + "/"
+	}
+	records, errCode := backend.GetRecordsWithCompleteKeyPath(path)
+	if errCode != 0 {
+		log.Errorf(nil, "get task status list from data-store failed")
+		t.SetFirstErrorCode(workspace.ErrCode(errCode), "task status list retrieval failed")
+		return workspace.TaskFinish
+	}
+
+	tasks := make([]models.TaskProgress, 0)
+	for key, record := range records {
+		// Key format is <task status path><app instance id>/<task id>
+		ids := strings.Split(strings.TrimPrefix(key, meputil.AppDLCMTaskStatusPath), "/")
+		if len(ids) != 2 {
+			continue
+		}
+		taskStatus := &models.TaskStatus{}
+		if jsonErr := json.Unmarshal(record, taskStatus); jsonErr != nil {
+			log.Warnf("failed to parse the task status(%s) from data-store", ids[1])
+			continue
+		}
+		taskProgress := task.BuildTaskProgress(ids[1], ids[0], taskStatus)
+		if filter.match(&taskProgress) {
+			tasks = append(tasks, taskProgress)
+		}
+	}
+
+	// Latest tasks first, task id keeps the paging order stable for the same time
+	sort.Slice(tasks, func(i, j int) bool {
+		if tasks[i].CreateTime != tasks[j].CreateTime {
+			return tasks[i].CreateTime > tasks[j].CreateTime
+		}
+		return tasks[i].TaskId < tasks[j].TaskId
+	})
+
+	taskList := models.TaskProgressList{TotalCount: len(tasks), Tasks: make([]models.TaskProgress, 0)}
+	if filter.offset < len(tasks) {
+		end := filter.offset + filter.limit
+		if end > len(tasks) {
+			end = len(tasks)
+		}
+		taskList.Tasks = tasks[filter.offset:end]
+	}
+	t.HttpRsp = taskList
+	return workspace.TaskFinish
+}
+
+func parseTaskListFilter(query url.Values) (*taskListFilter, error) {
+	filter := &taskListFilter{
+		appInstanceId: query.Get("appInstanceId"),
+		state:         query.Get("state"),
+		limit:         meputil.DefaultTaskListLimit,
+	}
+	if len(filter.appInstanceId) != 0 && strings.Contains(filter.appInstanceId, "/") {
+		return nil, fmt.Errorf("invalid app instance id")
+	}
+	if len(filter.state) != 0 && filter.state != meputil.TASK_STATE_SUCCESS &&
+		filter.state != meputil.TASK_STATE_PROCESSING && filter.state != meputil.TASK_STATE_FAILURE &&
+		filter.state != meputil.TASK_STATE_CANCELLED {
+		return nil, fmt.Errorf("invalid task state")
+	}
+
+	var err error
+	if filter.startTime, err = parseQueryInt64(query, "startTime", 0); err != nil {
+		return nil, err
+	}
+	if filter.endTime, err = parseQueryInt64(query, "endTime", 0); err != nil {
+		return nil, err
+	}
+	if filter.endTime != 0 && filter.endTime < filter.startTime {
+		return nil, fmt.Errorf("end time is before the start time")
+	}
+
+	offset, err := parseQueryInt64(query, "offset", 0)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := parseQueryInt64(query, "limit", meputil.DefaultTaskListLimit)
+	if err != nil {
+		return nil, err
+	}
+	if limit == 0 || limit > meputil.MaxTaskListLimit {
+		return nil, fmt.Errorf("limit must be within 1 and %d", meputil.MaxTaskListLimit)
+	}
+	filter.offset = int(offset)
+	filter.limit = int(limit)
+	return filter, nil
+}
+
+// Read a non negative integer query parameter
+func parseQueryInt64(query url.Values, key string, defaultValue int64) (int64, error) {
+	value := query.Get(key)
+	if len(value) == 0 {
+		return defaultValue, nil
+	}
+	result, err := strconv.ParseInt(value, meputil.FormatIntBase, 64)
+	if err != nil || result < 0 {
+		return 0, fmt.Errorf("invalid %s", key)
+	}
+	return result, nil
+}
+
+func (f *taskListFilter) match(taskProgress *models.TaskProgress) bool {
+	if len(f.state) != 0 && taskProgress.ConfigResult != f.state {
+		return false
+	}
+	if f.startTime != 0 && taskProgress.CreateTime < f.startTime {
+		return false
+	}
+	if f.endTime != 0 && taskProgress.CreateTime > f.endTime {
+		return false
+	}
+	return true
+}
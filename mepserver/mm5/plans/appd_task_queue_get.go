@@ -0,0 +1,42 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package path implements mep server api plans
+package plans
+
+import (
+	"github.com/apache/servicecomb-service-center/pkg/log"
+
+	"mepserver/common/arch/workspace"
+	"mepserver/mm5/task"
+)
+
+type TaskQueueStatsGet struct {
+	workspace.TaskBase
+	HttpRsp interface{} `json:"httpRsp,out"`
+	worker  *task.Worker
+}
+
+func (t *TaskQueueStatsGet) WithWorker(w *task.Worker) *TaskQueueStatsGet {
+	t.worker = w
+	return t
+}
+
+func (t *TaskQueueStatsGet) OnRequest(data string) workspace.TaskCode {
+	log.Debug("query request arrived to fetch the task queue statistics.")
+	t.HttpRsp = t.worker.QueueStats()
+	return workspace.TaskFinish
+}
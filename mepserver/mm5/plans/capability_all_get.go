@@ -20,9 +20,12 @@ package plans
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"mepserver/common/models"
 	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/apache/servicecomb-service-center/pkg/log"
@@ -30,6 +33,7 @@ import (
 	"github.com/apache/servicecomb-service-center/server/core/proto"
 
 	"mepserver/common/extif/backend"
+	"mepserver/common/extif/dataplane"
 
 	"mepserver/common/arch/workspace"
 	meputil "mepserver/common/util"
@@ -62,26 +66,64 @@ func (t *DecodeCapabilityQueryReq) getParam(r *http.Request) error {
 	return nil
 }
 
+// Optional data included in the capabilities on request
+type capabilityInclude struct {
+	metadata      bool
+	transportInfo bool
+	liveness      bool
+	dataPlane     bool
+}
+
+// Filters and paging of the capability list query
+type capabilityFilter struct {
+	category        string
+	state           string
+	serializer      string
+	scopeOfLocality string
+	consumer        string
+	offset          int
+	limit           int
+	include         capabilityInclude
+}
+
 type CapabilitiesGet struct {
 	workspace.TaskBase
-	Ctx                    context.Context `json:"ctx,in"`
-	QueryParam             url.Values      `json:"queryParam,in"`
-	HttpRsp                interface{}     `json:"httpRsp,out"`
-	HttpErrInf             *proto.Response `json:"httpErrInf,out"`
+	Ctx                    context.Context     `json:"ctx,in"`
+	W                      http.ResponseWriter `json:"w,in"`
+	QueryParam             url.Values          `json:"queryParam,in"`
+	HttpRsp                interface{}         `json:"httpRsp,out"`
+	HttpErrInf             *proto.Response     `json:"httpErrInf,out"`
 	consumerList           map[string][]models.Consumer
 	serviceNameMapping     map[string]string
 	serviceCategoryMapping map[models.CategoryRef]string
+	dpCapabilities         *dataplane.Capabilities
+}
+
+func (t *CapabilitiesGet) WithCapabilities(capabilities *dataplane.Capabilities) *CapabilitiesGet {
+	t.dpCapabilities = capabilities
+	return t
 }
 
 func (t *CapabilitiesGet) OnRequest(dataInput string) workspace.TaskCode {
 	log.Debug("query request arrived to fetch all capabilities.")
 
+	filter, err := parseCapabilityFilter(t.QueryParam)
+	if err != nil {
+		log.Errorf(err, "invalid capability query")
+		t.SetFirstErrorCode(meputil.RequestParamErr, err.Error())
+		return workspace.TaskFinish
+	}
+
 	capabilities := make([]models.PlatformCapability, 0)
+	if filter.include.dataPlane && t.dpCapabilities != nil {
+		capabilities = append(capabilities, buildDataPlaneCapability(t.dpCapabilities))
+	}
 
 	resp, err := meputil.FindInstanceByKey(t.QueryParam)
 	if err != nil {
 		if err.Error() == "null" {
 			log.Info("the service is empty")
+			t.setTotalCount(len(capabilities))
 			t.HttpRsp = capabilities
 			return workspace.TaskFinish
 		}
@@ -101,24 +143,163 @@ func (t *CapabilitiesGet) OnRequest(dataInput string) workspace.TaskCode {
 	}
 
 	for _, instance := range resp.Instances {
-
 		capabilityId := instance.GetServiceId() + instance.GetInstanceId()
-		capabilityState := instance.Properties["mecState"]
-
-		// Build the capability structure
-		capability := models.PlatformCapability{CapabilityId: capabilityId,
-			CapabilityName: instance.Properties["serName"], Status: capabilityState, Version: instance.GetVersion(),
-			Consumers: t.consumerList[capabilityId]}
-		if capability.Consumers == nil {
-			capability.Consumers = make([]models.Consumer, 0)
+		if filter.match(instance, t.consumerList[capabilityId]) {
+			capabilities = append(capabilities,
+				buildPlatformCapability(instance, t.consumerList[capabilityId], filter.include))
 		}
-		capabilities = append(capabilities, capability)
 	}
 
-	t.HttpRsp = capabilities
+	// Capability id keeps the paging order stable
+	sort.Slice(capabilities, func(i, j int) bool {
+		return capabilities[i].CapabilityId < capabilities[j].CapabilityId
+	})
+	t.setTotalCount(len(capabilities))
+
+	if filter.offset >= len(capabilities) {
+		t.HttpRsp = make([]models.PlatformCapability, 0)
+		return workspace.TaskFinish
+	}
+	end := filter.offset + filter.limit
+	if end > len(capabilities) {
+		end = len(capabilities)
+	}
+	t.HttpRsp = capabilities[filter.offset:end]
 	return workspace.TaskFinish
 }
 
+// Total number of the capabilities matching the filters, before the paging is applied
+func (t *CapabilitiesGet) setTotalCount(totalCount int) {
+	if t.W != nil {
+		t.W.Header().Set(meputil.TotalCountHeader, strconv.Itoa(totalCount))
+	}
+}
+
+func parseCapabilityFilter(query url.Values) (*capabilityFilter, error) {
+	filter := &capabilityFilter{
+		category:        query.Get("category"),
+		state:           query.Get("state"),
+		serializer:      query.Get("serializer"),
+		scopeOfLocality: query.Get("scope_of_locality"),
+		consumer:        query.Get("consumer"),
+	}
+	if len(filter.state) != 0 && filter.state != meputil.ActiveState && filter.state != meputil.InactiveState {
+		return nil, fmt.Errorf("invalid state")
+	}
+	if len(filter.consumer) != 0 && meputil.ValidateUUID(filter.consumer) != nil {
+		return nil, fmt.Errorf("invalid consumer app instance id")
+	}
+
+	var err error
+	if filter.include, err = parseCapabilityInclude(query); err != nil {
+		return nil, err
+	}
+	offset, err := parseQueryInt64(query, "offset", 0)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := parseQueryInt64(query, "limit", meputil.DefaultCapabilityListLimit)
+	if err != nil {
+		return nil, err
+	}
+	if limit == 0 || limit > meputil.MaxCapabilityListLimit {
+		return nil, fmt.Errorf("limit must be within 1 and %d", meputil.MaxCapabilityListLimit)
+	}
+	filter.offset = int(offset)
+	filter.limit = int(limit)
+	return filter, nil
+}
+
+// Read the comma separated list of the optional data to include in the capabilities
+func parseCapabilityInclude(query url.Values) (capabilityInclude, error) {
+	include := capabilityInclude{}
+	value := query.Get("include")
+	if len(value) == 0 {
+		return include, nil
+	}
+	for _, item := range strings.Split(value, ",") {
+		switch strings.TrimSpace(item) {
+		case meputil.CapabilityIncludeMetadata:
+			include.metadata = true
+		case meputil.CapabilityIncludeTransportInfo:
+			include.transportInfo = true
+		case meputil.CapabilityIncludeLiveness:
+			include.liveness = true
+		case meputil.CapabilityIncludeDataPlane:
+			include.dataPlane = true
+		default:
+			return include, fmt.Errorf("invalid include value %s", item)
+		}
+	}
+	return include, nil
+}
+
+func (f *capabilityFilter) match(instance *proto.MicroServiceInstance, consumers []models.Consumer) bool {
+	if len(f.category) != 0 && !strings.EqualFold(instance.Properties["serCategory/id"], f.category) &&
+		!strings.EqualFold(instance.Properties["serCategory/name"], f.category) {
+		return false
+	}
+	if len(f.state) != 0 && instance.Properties["mecState"] != f.state {
+		return false
+	}
+	if len(f.serializer) != 0 && !strings.EqualFold(instance.Properties["serializer"], f.serializer) {
+		return false
+	}
+	if len(f.scopeOfLocality) != 0 && !strings.EqualFold(instance.Properties["ScopeOfLocality"], f.scopeOfLocality) {
+		return false
+	}
+	if len(f.consumer) != 0 {
+		for _, consumer := range consumers {
+			if consumer.AppInstanceId == f.consumer {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Build the capability of a service instance along with the optional data requested
+func buildPlatformCapability(instance *proto.MicroServiceInstance, consumers []models.Consumer,
+	include capabilityInclude) models.PlatformCapability {
+	capability := models.PlatformCapability{CapabilityId: instance.GetServiceId() + instance.GetInstanceId(),
+		CapabilityName: instance.Properties["serName"], Status: instance.Properties["mecState"],
+		Version: instance.GetVersion(), Consumers: consumers}
+	if capability.Consumers == nil {
+		capability.Consumers = make([]models.Consumer, 0)
+	}
+
+	if include.metadata {
+		capability.SerCategory = &models.CategoryRef{
+			Href:    instance.Properties["serCategory/href"],
+			ID:      instance.Properties["serCategory/id"],
+			Name:    instance.Properties["serCategory/name"],
+			Version: instance.Properties["serCategory/version"],
+		}
+		capability.Serializer = instance.Properties["serializer"]
+		capability.ScopeOfLocality = instance.Properties["ScopeOfLocality"]
+	}
+
+	if include.transportInfo {
+		serviceInfo := models.ServiceInfo{}
+		serviceInfo.FromServiceInstance(instance)
+		capability.TransportInfo = &serviceInfo.TransportInfo
+	}
+	if include.liveness && len(instance.Properties["livenessInterval"]) != 0 {
+		liveness := models.ServiceLivenessInfo{}
+		liveness.FromServiceInstance(instance)
+		capability.Liveness = &liveness
+	}
+	return capability
+}
+
+// Data-plane is reported as a capability of the platform without any consumer
+func buildDataPlaneCapability(capabilities *dataplane.Capabilities) models.PlatformCapability {
+	return models.PlatformCapability{CapabilityId: meputil.DataPlaneCapabilityId,
+		CapabilityName: meputil.DataPlaneCapabilityId, Status: meputil.ActiveState,
+		Consumers: make([]models.Consumer, 0), DataPlane: capabilities}
+}
+
 // Read and build a mapping of service ids to applications it is using
 func (t *CapabilitiesGet) buildConsumerList() int {
 	t.serviceNameMapping, t.serviceCategoryMapping = getServiceMapping()
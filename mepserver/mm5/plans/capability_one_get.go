@@ -31,6 +31,7 @@ import (
 
 	"mepserver/common/arch/workspace"
 	"mepserver/common/extif/backend"
+	"mepserver/common/extif/dataplane"
 	meputil "mepserver/common/util"
 )
 
@@ -45,6 +46,12 @@ type CapabilityGet struct {
 	consumerList           []models.Consumer
 	serviceNameMapping     map[string]string
 	serviceCategoryMapping map[models.CategoryRef]string
+	dpCapabilities         *dataplane.Capabilities
+}
+
+func (t *CapabilityGet) WithCapabilities(capabilities *dataplane.Capabilities) *CapabilityGet {
+	t.dpCapabilities = capabilities
+	return t
 }
 
 func (t *CapabilityGet) OnRequest(dataInput string) workspace.TaskCode {
@@ -52,12 +59,28 @@ func (t *CapabilityGet) OnRequest(dataInput string) workspace.TaskCode {
 
 	_, ids := meputil.GetHTTPTags(t.R)
 
+	if t.CapabilityId == meputil.DataPlaneCapabilityId {
+		if t.dpCapabilities == nil {
+			log.Error("data-plane capabilities are not available", nil)
+			t.SetFirstErrorCode(meputil.RemoteServerErr, "data-plane capabilities not available")
+			return workspace.TaskFinish
+		}
+		t.HttpRsp = buildDataPlaneCapability(t.dpCapabilities)
+		return workspace.TaskFinish
+	}
+
 	var err = meputil.ValidateServiceID(t.CapabilityId)
 	if err != nil {
 		log.Error("Invalid service ID", err)
 		t.SetFirstErrorCode(meputil.SerErrFailBase, "Invalid service ID")
 		return workspace.TaskFinish
 	}
+	include, err := parseCapabilityInclude(t.QueryParam)
+	if err != nil {
+		log.Errorf(err, "invalid capability query")
+		t.SetFirstErrorCode(meputil.RequestParamErr, err.Error())
+		return workspace.TaskFinish
+	}
 
 	serviceId := t.CapabilityId[:len(t.CapabilityId)/2]
 	instanceId := t.CapabilityId[len(t.CapabilityId)/2:]
@@ -83,7 +106,6 @@ func (t *CapabilityGet) OnRequest(dataInput string) workspace.TaskCode {
 		t.SetFirstErrorCode(meputil.SerInstanceNotFound, "capability id miss-match")
 		return workspace.TaskFinish
 	}
-	capabilityState := resp.Instance.Properties["mecState"]
 
 	// Build a complete list of service to its consumers applications
 	errCode := t.buildConsumerList()
@@ -93,14 +115,7 @@ func (t *CapabilityGet) OnRequest(dataInput string) workspace.TaskCode {
 	}
 
 	// Build the capability structure
-	capability := models.PlatformCapability{CapabilityId: capabilityId,
-		CapabilityName: resp.Instance.Properties["serName"], Status: capabilityState, Version: resp.Instance.GetVersion(),
-		Consumers: t.consumerList}
-	if capability.Consumers == nil {
-		capability.Consumers = make([]models.Consumer, 0)
-	}
-
-	t.HttpRsp = capability
+	t.HttpRsp = buildPlatformCapability(resp.Instance, t.consumerList, include)
 	return workspace.TaskFinish
 }
 
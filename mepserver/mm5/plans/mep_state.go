@@ -0,0 +1,367 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package path implements mep server api plans
+package plans
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apache/servicecomb-service-center/pkg/log"
+	"github.com/apache/servicecomb-service-center/pkg/util"
+	"github.com/apache/servicecomb-service-center/server/core"
+	"github.com/apache/servicecomb-service-center/server/core/proto"
+
+	"mepserver/common/arch/workspace"
+	"mepserver/common/extif/backend"
+	"mepserver/common/models"
+	meputil "mepserver/common/util"
+	"mepserver/mm5/task"
+)
+
+// Records of the ongoing operations are not restored, AppD configurations are re-programmed through new tasks
+var mepStateTransientPaths = []string{
+	meputil.AppDConfigKeyPath,
+	meputil.AppDLCMJobsPath,
+	meputil.AppDLCMTasksPath,
+	meputil.AppTerminationKeyPath,
+}
+
+type MepStateExport struct {
+	workspace.TaskBase
+	Ctx     context.Context `json:"ctx,in"`
+	HttpRsp interface{}     `json:"httpRsp,out"`
+}
+
+func (t *MepStateExport) OnRequest(data string) workspace.TaskCode {
+	log.Info("mep state export request arrived.")
+
+	// Snapshot is consistent only when no AppD configuration is being processed
+	jobs, errCode := backend.GetRecords(meputil.AppDLCMJobsPath)
+	if errCode != 0 {
+		t.SetFirstErrorCode(workspace.ErrCode(errCode), "ongoing operation check failed")
+		return workspace.TaskFinish
+	}
+	if len(jobs) != 0 {
+		log.Errorf(nil, "mep state export is not allowed while appd configurations are processed")
+		t.SetFirstErrorCode(meputil.ForbiddenOperation, "appd configuration operation in progress")
+		return workspace.TaskFinish
+	}
+
+	snapshot := models.MepStateSnapshot{Version: meputil.MepStateSnapshotVersion, CreateTime: time.Now().Unix(),
+		Records: make([]models.MepStateRecord, 0), Services: make([]models.MepStateService, 0)}
+
+	records, errCode := backend.GetRecordsWithCompleteKeyPath(meputil.DBRootPath)
+	if errCode != 0 {
+		log.Errorf(nil, "get mep state from data-store failed")
+		t.SetFirstErrorCode(workspace.ErrCode(errCode), "mep state retrieval failed")
+		return workspace.TaskFinish
+	}
+	for key, value := range records {
+		snapshot.Records = append(snapshot.Records,
+			models.MepStateRecord{Key: strings.TrimPrefix(key, meputil.DBRootPath), Value: value})
+	}
+	sort.Slice(snapshot.Records, func(i, j int) bool { return snapshot.Records[i].Key < snapshot.Records[j].Key })
+
+	var err error
+	if snapshot.Services, err = exportMepServices(t.Ctx); err != nil {
+		log.Errorf(err, "get services from service center failed")
+		t.SetFirstErrorCode(meputil.SerErrServiceNotFound, err.Error())
+		return workspace.TaskFinish
+	}
+
+	t.HttpRsp = snapshot
+	return workspace.TaskFinish
+}
+
+// Services registered by the apps, instances without app instance id are not created by mep
+func exportMepServices(ctx context.Context) ([]models.MepStateService, error) {
+	services := make([]models.MepStateService, 0)
+	resp, err := meputil.FindInstanceByKey(url.Values{})
+	if err != nil {
+		if err.Error() == "null" {
+			return services, nil
+		}
+		return nil, errors.New("find instances failed")
+	}
+
+	instances := make(map[string][]*proto.MicroServiceInstance)
+	for _, instance := range resp.Instances {
+		if len(instance.Properties["appInstanceId"]) == 0 {
+			continue
+		}
+		instances[instance.ServiceId] = append(instances[instance.ServiceId], instance)
+	}
+	for serviceId, serviceInstances := range instances {
+		serviceResp, err := core.ServiceAPI.GetOne(ctx, &proto.GetServiceRequest{ServiceId: serviceId})
+		if err != nil || serviceResp.Service == nil {
+			return nil, fmt.Errorf("get service(%s) failed", serviceId)
+		}
+		services = append(services, models.MepStateService{Service: serviceResp.Service,
+			Instances: serviceInstances})
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Service.ServiceId < services[j].Service.ServiceId })
+	return services, nil
+}
+
+type DecodeMepStateReq struct {
+	workspace.TaskBase
+	R        *http.Request   `json:"r,in"`
+	Ctx      context.Context `json:"ctx,out"`
+	RestBody interface{}     `json:"restBody,out"`
+}
+
+func (t *DecodeMepStateReq) OnRequest(data string) workspace.TaskCode {
+	queryReq, _ := meputil.GetHTTPTags(t.R)
+	t.Ctx = util.SetTargetDomainProject(t.R.Context(), t.R.Header.Get("X-Domain-Name"), queryReq.Get(":project"))
+
+	if err := t.parseBody(t.R); err != nil {
+		log.Error("parse rest body failed", err)
+	}
+	return workspace.TaskFinish
+}
+
+func (t *DecodeMepStateReq) WithBody(body interface{}) *DecodeMepStateReq {
+	t.RestBody = body
+	return t
+}
+
+func (t *DecodeMepStateReq) parseBody(r *http.Request) error {
+	snapshot, ok := t.RestBody.(*models.MepStateSnapshot)
+	if !ok {
+		return nil
+	}
+	// One byte more than the limit is read to find an oversized body without reading all of it
+	msg, err := ioutil.ReadAll(io.LimitReader(r.Body, meputil.MaxMepStateSnapshotLength+1))
+	if err != nil {
+		t.SetFirstErrorCode(meputil.SerErrFailBase, "read request body error")
+		return err
+	}
+	if len(msg) > meputil.MaxMepStateSnapshotLength {
+		t.SetFirstErrorCode(meputil.RequestParamErr, "request body too large")
+		return errors.New("mep state snapshot too large")
+	}
+
+	if err = json.Unmarshal(msg, snapshot); err != nil {
+		t.SetFirstErrorCode(meputil.ParseInfoErr, "unmarshal request body error")
+		return err
+	}
+	if err = meputil.ValidateRestBody(snapshot); err != nil {
+		t.SetFirstErrorCode(meputil.RequestParamErr, "request param validation failed")
+		return err
+	}
+	if snapshot.Version != meputil.MepStateSnapshotVersion {
+		t.SetFirstErrorCode(meputil.RequestParamErr, "unsupported snapshot version")
+		return errors.New("unsupported snapshot version")
+	}
+	for _, record := range snapshot.Records {
+		if strings.HasPrefix(record.Key, "/") || strings.Contains(record.Key, "..") {
+			t.SetFirstErrorCode(meputil.RequestParamErr, "invalid record key")
+			return errors.New("invalid record key")
+		}
+	}
+	return nil
+}
+
+type MepStateImport struct {
+	workspace.TaskBase
+	Ctx      context.Context `json:"ctx,in"`
+	RestBody interface{}     `json:"restBody,in"`
+	HttpRsp  interface{}     `json:"httpRsp,out"`
+	worker   *task.Worker
+}
+
+func (t *MepStateImport) WithWorker(w *task.Worker) *MepStateImport {
+	t.worker = w
+	return t
+}
+
+func (t *MepStateImport) OnRequest(data string) workspace.TaskCode {
+	log.Info("mep state import request arrived.")
+
+	snapshot, ok := t.RestBody.(*models.MepStateSnapshot)
+	if !ok {
+		t.SetFirstErrorCode(meputil.ParseInfoErr, "input body parse failed")
+		return workspace.TaskFinish
+	}
+
+	// Snapshot is restored only into an empty mep, merging with an existing state is not supported
+	if empty, errCode, msg := isMepStateEmpty(); errCode != 0 || !empty {
+		if errCode == 0 {
+			errCode, msg = meputil.ForbiddenOperation, "mep state is not empty"
+		}
+		log.Errorf(nil, "mep state import failed: %s", msg)
+		t.SetFirstErrorCode(errCode, msg)
+		return workspace.TaskFinish
+	}
+
+	// Everything written by a failed import is removed, so that the mep stays empty and the import can be retried
+	undo := &mepStateImportUndo{ctx: t.Ctx}
+	result, errCode, msg := t.importSnapshot(snapshot, undo)
+	if errCode != 0 {
+		log.Errorf(nil, "mep state import failed: %s", msg)
+		undo.rollback()
+		t.SetFirstErrorCode(errCode, msg)
+		return workspace.TaskFinish
+	}
+
+	// Tasks are started only after the whole snapshot is restored, a started task can not be rolled back
+	for _, taskProgress := range result.Tasks {
+		t.worker.StartNewTask(undo.appNames[taskProgress.AppInstanceId], taskProgress.AppInstanceId,
+			taskProgress.TaskId)
+	}
+
+	t.HttpRsp = result
+	return workspace.TaskFinish
+}
+
+func (t *MepStateImport) importSnapshot(snapshot *models.MepStateSnapshot,
+	undo *mepStateImportUndo) (*models.MepStateImportResult, workspace.ErrCode, string) {
+	result := &models.MepStateImportResult{Tasks: make([]models.TaskProgress, 0)}
+	for i := range snapshot.Services {
+		instances, err := importMepService(t.Ctx, &snapshot.Services[i], undo)
+		if err != nil {
+			log.Errorf(err, "service restore failed")
+			return nil, meputil.SerErrServiceRegFailed, err.Error()
+		}
+		result.Services++
+		result.Instances += instances
+	}
+
+	appDConfigs := make(map[string]*models.AppDConfig)
+	for _, record := range snapshot.Records {
+		key := meputil.DBRootPath + record.Key
+		if strings.HasPrefix(key, meputil.AppDConfigKeyPath) {
+			appDConfig := &models.AppDConfig{}
+			if err := json.Unmarshal(record.Value, appDConfig); err != nil {
+				log.Warnf("failed to parse the appd config(%s) in the snapshot", record.Key)
+				continue
+			}
+			appDConfigs[strings.TrimPrefix(key, meputil.AppDConfigKeyPath)] = appDConfig
+			continue
+		}
+		if isMepStateTransientPath(key) {
+			continue
+		}
+		if errCode := backend.PutRecord(key, record.Value); errCode != 0 {
+			log.Errorf(nil, "record(%s) restore failed", record.Key)
+			return nil, workspace.ErrCode(errCode), "record restore failed"
+		}
+		undo.paths = append(undo.paths, key)
+		result.Records++
+	}
+
+	// Data-plane and dns agent are programmed by processing each configuration as a new one
+	undo.appNames = make(map[string]string)
+	for appInstanceId, appDConfig := range appDConfigs {
+		appDConfig.Operation = http.MethodPost
+		appDConfig.TaskId = ""
+		appDConfig.CallbackUri = ""
+		taskId := meputil.GenerateUniqueId()
+		if errCode, msg := UpdateProcessingDatabase(appInstanceId, taskId, appDConfig); errCode != 0 {
+			log.Errorf(nil, "appd config(app-id: %s) restore failed: %s", appInstanceId, msg)
+			return nil, errCode, msg
+		}
+		undo.paths = append(undo.paths, meputil.AppDLCMJobsPath+appInstanceId, meputil.AppDLCMTasksPath+taskId,
+			meputil.AppDLCMTaskStatusPath+appInstanceId+"/"+taskId)
+		undo.appNames[appInstanceId] = appDConfig.AppName
+		result.Tasks = append(result.Tasks,
+			GenerateTaskResponse(taskId, appInstanceId, "PROCESSING", "0", "Operation In progress"))
+	}
+	return result, 0, ""
+}
+
+// Records and services created by an import
+type mepStateImportUndo struct {
+	ctx        context.Context
+	paths      []string
+	serviceIds []string
+	appNames   map[string]string
+}
+
+// Remove the records and the services, the instances are removed along with the services
+func (u *mepStateImportUndo) rollback() {
+	_ = backend.DeletePaths(u.paths, true)
+	for _, serviceId := range u.serviceIds {
+		resp, err := core.ServiceAPI.Delete(u.ctx, &proto.DeleteServiceRequest{ServiceId: serviceId, Force: true})
+		if err != nil || (resp != nil && resp.Response != nil && resp.Response.Code != proto.Response_SUCCESS) {
+			log.Errorf(err, "service(%s) delete failed on the import rollback, "+
+				"this might lead to data inconsistency!", serviceId)
+		}
+	}
+}
+
+// Check there is no record under the mep root path and no service registered by the apps
+func isMepStateEmpty() (bool, workspace.ErrCode, string) {
+	records, errCode := backend.GetRecords(meputil.DBRootPath)
+	if errCode != 0 {
+		return false, workspace.ErrCode(errCode), "mep state retrieval failed"
+	}
+	if len(records) != 0 {
+		return false, 0, ""
+	}
+	resp, err := meputil.FindInstanceByKey(url.Values{})
+	if err != nil {
+		if err.Error() == "null" {
+			return true, 0, ""
+		}
+		return false, meputil.SerErrServiceNotFound, "find instances failed"
+	}
+	for _, instance := range resp.Instances {
+		if len(instance.Properties["appInstanceId"]) != 0 {
+			return false, 0, ""
+		}
+	}
+	return true, 0, ""
+}
+
+func isMepStateTransientPath(key string) bool {
+	for _, path := range mepStateTransientPaths {
+		if strings.HasPrefix(key, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// Create the service and its instances with the ids in the snapshot, the capability ids are kept unchanged
+func importMepService(ctx context.Context, service *models.MepStateService, undo *mepStateImportUndo) (int, error) {
+	serviceResp, err := core.ServiceAPI.Create(ctx, &proto.CreateServiceRequest{Service: service.Service})
+	if err == nil && len(serviceResp.ServiceId) != 0 {
+		undo.serviceIds = append(undo.serviceIds, serviceResp.ServiceId)
+	}
+	if err != nil || serviceResp.ServiceId != service.Service.ServiceId {
+		return 0, fmt.Errorf("service(%s) creation failed", service.Service.ServiceId)
+	}
+	for i, instance := range service.Instances {
+		instance.ServiceId = service.Service.ServiceId
+		instanceResp, err := core.InstanceAPI.Register(ctx, &proto.RegisterInstanceRequest{Instance: instance})
+		if err != nil || instanceResp.InstanceId != instance.InstanceId {
+			return i, fmt.Errorf("service instance(%s) registration failed", instance.InstanceId)
+		}
+	}
+	return len(service.Instances), nil
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package task
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/apache/servicecomb-service-center/pkg/log"
+	"io"
+	"io/ioutil"
+	"mepserver/common/models"
+	"mepserver/common/util"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+// Interval before the first retry of the callback delivery
+var callbackRetryInterval = time.Duration(util.TaskCallbackRetryInterval) * time.Second
+
+// Post the final task progress to the callback uri of the task in the background
+func notifyTaskCompletion(appInstanceId, taskId string, status *models.TaskStatus) {
+	if len(status.CallbackUri) == 0 {
+		return
+	}
+	body, err := json.Marshal(BuildTaskProgress(taskId, appInstanceId, status))
+	if err != nil {
+		log.Errorf(err, "can not marshal the task progress for the callback(task-id: %s)", taskId)
+		return
+	}
+	go deliverTaskCallback(status.CallbackUri, taskId, body)
+}
+
+// Deliver the callback retrying with exponential backoff till the max attempts
+func deliverTaskCallback(callbackUri, taskId string, body []byte) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf(nil, "Task callback panic: %v \n %s", r, string(debug.Stack()))
+		}
+	}()
+
+	tlsConfig, err := util.TLSConfig(util.TaskCallbackCaCert, false)
+	if err != nil {
+		log.Errorf(err, "task callback tls configuration failed(task-id: %s)", taskId)
+		return
+	}
+	// Server certificate is verified against the host of the callback uri
+	tlsConfig.ServerName = ""
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		Timeout:   util.TaskCallbackTimeout * time.Second,
+	}
+
+	interval := callbackRetryInterval
+	for attempt := 1; ; attempt++ {
+		retry, err := postTaskCallback(client, callbackUri, body)
+		if err == nil {
+			log.Infof("Task callback delivered(task-id: %s)", taskId)
+			return
+		}
+		if !retry || attempt >= util.TaskCallbackMaxAttempts {
+			log.Errorf(err, "task callback delivery failed after %d attempts(task-id: %s)", attempt, taskId)
+			return
+		}
+		log.Warnf("task callback delivery attempt %d failed(task-id: %s): %s", attempt, taskId, err.Error())
+		time.Sleep(interval)
+		interval *= 2
+	}
+}
+
+// Post the task progress, client errors other than timeout and throttling are not retried
+func postTaskCallback(client *http.Client, callbackUri string, body []byte) (bool, error) {
+	resp, err := client.Post(callbackUri, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return false, nil
+	}
+	retry := resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusRequestTimeout ||
+		resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("callback responded with status %d", resp.StatusCode)
+}
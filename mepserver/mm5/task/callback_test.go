@@ -0,0 +1,86 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package task
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"github.com/agiledragon/gomonkey"
+	"github.com/stretchr/testify/assert"
+	"mepserver/common/models"
+	"mepserver/common/util"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const callbackTaskId = "e0b5bb6a-7bd5-4d37-bf8e-2e1e4d4a7c01"
+
+var callbackRootCAs *x509.CertPool
+
+func TestDeliverTaskCallback(t *testing.T) {
+	attempts := 0
+	var received models.TaskProgress
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	callbackRootCAs = x509.NewCertPool()
+	callbackRootCAs.AddCert(server.Certificate())
+	patch := gomonkey.ApplyFunc(util.TLSConfig, func(string, bool) (*tls.Config, error) {
+		return &tls.Config{RootCAs: callbackRootCAs, ServerName: "edgegallery"}, nil
+	})
+	defer patch.Reset()
+	callbackRetryInterval = time.Millisecond
+
+	status := &models.TaskStatus{Progress: 1, CallbackUri: server.URL,
+		TrafficRuleStatusLst: []models.RuleStatus{{Id: ruleId, State: util.WaitConfigDBWrite}}}
+	body, _ := json.Marshal(BuildTaskProgress(callbackTaskId, defaultAppInstanceId, status))
+	deliverTaskCallback(server.URL, callbackTaskId, body)
+
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, util.TASK_STATE_SUCCESS, received.ConfigResult)
+	assert.Equal(t, "100", received.ConfigPhase)
+	assert.Equal(t, callbackTaskId, received.TaskId)
+}
+
+func TestDeliverTaskCallbackUntrustedServer(t *testing.T) {
+	attempts := 0
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+	}))
+	defer server.Close()
+
+	patch := gomonkey.ApplyFunc(util.TLSConfig, func(string, bool) (*tls.Config, error) {
+		return &tls.Config{RootCAs: x509.NewCertPool()}, nil
+	})
+	defer patch.Reset()
+	callbackRetryInterval = time.Millisecond
+
+	deliverTaskCallback(server.URL, callbackTaskId, []byte("{}"))
+
+	assert.Equal(t, 0, attempts)
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package task
+
+import (
+	"encoding/json"
+	"github.com/apache/servicecomb-service-center/pkg/log"
+	"mepserver/common/config"
+	"mepserver/common/extif/backend"
+	"mepserver/common/models"
+	"mepserver/common/util"
+	"strings"
+	"time"
+)
+
+// Periodically purge the finished task status records older than the retention period
+func PurgeFinishedTasks(retention config.TaskRetention) {
+	if retention.Period == 0 {
+		log.Info("Task status retention is not configured, purge disabled")
+		return
+	}
+	interval := retention.Interval
+	if interval == 0 {
+		interval = util.DefaultTaskPurgeInterval
+	}
+
+	backend.WaitForReady()
+	ticker := time.NewTicker(time.Duration(interval) * time.Minute)
+	defer ticker.Stop()
+	for {
+		purgeFinishedTasks(time.Now().Add(-time.Duration(retention.Period) * time.Hour).Unix())
+		<-ticker.C
+	}
+}
+
+// Delete the finished task status records which were last updated before the expiry time
+func purgeFinishedTasks(expiryTime int64) {
+	records, errCode := backend.GetRecordsWithCompleteKeyPath(util.AppDLCMTaskStatusPath)
+	if errCode != 0 {
+		log.Errorf(nil, "retrieve task status from data-store failed")
+		return
+	}
+
+	purgeCount := 0
+	for key, record := range records {
+		// Key format is <task status path><app instance id>/<task id>
+		ids := strings.Split(strings.TrimPrefix(key, util.AppDLCMTaskStatusPath), "/")
+		if len(ids) != 2 {
+			continue
+		}
+		taskStatus := &models.TaskStatus{}
+		if err := json.Unmarshal(record, taskStatus); err != nil {
+			continue
+		}
+		if !isTaskFinished(ids[0], ids[1], taskStatus) || taskStatus.UpdateTime > expiryTime {
+			continue
+		}
+		if errCode = backend.DeletePaths([]string{key, util.AppDLCMTasksPath + ids[1]}, true); errCode != 0 {
+			log.Errorf(nil, "purge task(app-id: %s, task-id: %s) failed", ids[0], ids[1])
+			continue
+		}
+		purgeCount++
+	}
+	if purgeCount != 0 {
+		log.Infof("Purged %d finished task status records", purgeCount)
+	}
+}
+
+// Task is finished if it is either failed, cancelled or completed and it is not being processed anymore
+func isTaskFinished(appInstanceId, taskId string, taskStatus *models.TaskStatus) bool {
+	ruleCount := len(taskStatus.TrafficRuleStatusLst) + len(taskStatus.DNSRuleStatusLst)
+	if taskStatus.Progress >= 0 && taskStatus.Progress != ruleCount {
+		return false
+	}
+
+	// Failed task could still be reverting and completed task could be writing the config db
+	jobEntry, errCode := backend.GetRecord(util.AppDLCMJobsPath + appInstanceId)
+	if errCode == util.SubscriptionNotFound {
+		return true
+	} else if errCode != 0 {
+		return false
+	}
+	appDConfig := &models.AppDConfig{}
+	if err := json.Unmarshal(jobEntry, appDConfig); err != nil {
+		return true
+	}
+	return appDConfig.TaskId != taskId
+}
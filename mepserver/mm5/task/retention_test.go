@@ -0,0 +1,83 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package task
+
+import (
+	"github.com/agiledragon/gomonkey"
+	"mepserver/common/extif/backend"
+	"mepserver/common/models"
+	"mepserver/common/util"
+	"strings"
+	"testing"
+)
+
+func TestPurgeFinishedTasks(t *testing.T) {
+	const expiryTime = 1000
+	ruleStatus := []models.RuleStatus{{Id: ruleId, State: util.WaitConfigDBWrite, Method: util.OperCreate}}
+	tasks := map[string]*models.TaskStatus{
+		"expiredSuccess":   {Progress: 1, TrafficRuleStatusLst: ruleStatus, UpdateTime: expiryTime - 1},
+		"expiredFailure":   {Progress: util.TaskProgressFailure, TrafficRuleStatusLst: ruleStatus, UpdateTime: 10},
+		"recentSuccess":    {Progress: 1, TrafficRuleStatusLst: ruleStatus, UpdateTime: expiryTime + 1},
+		"expiredInProcess": {Progress: 0, TrafficRuleStatusLst: ruleStatus, UpdateTime: 10},
+		"expiredReverting": {Progress: util.TaskProgressFailure, TrafficRuleStatusLst: ruleStatus, UpdateTime: 10},
+	}
+
+	recoveryStore = make(map[string][]byte)
+	for taskId, status := range tasks {
+		appId := taskId + "App"
+		putRecoveryEntry(util.AppDLCMTaskStatusPath+appId+"/"+taskId, status)
+		recoveryStore[util.AppDLCMTasksPath+taskId] = []byte(appId)
+	}
+	// Revert of this task is still pending
+	putRecoveryEntry(util.AppDLCMJobsPath+"expiredRevertingApp", &models.AppDConfig{TaskId: "expiredReverting"})
+
+	patch1 := gomonkey.ApplyFunc(backend.GetRecordsWithCompleteKeyPath, func(path string) (map[string][]byte, int) {
+		records := make(map[string][]byte)
+		for key, value := range recoveryStore {
+			if strings.HasPrefix(key, path) {
+				records[key] = value
+			}
+		}
+		return records, 0
+	})
+	patch2 := gomonkey.ApplyFunc(backend.GetRecord, func(path string) ([]byte, int) {
+		if value, found := recoveryStore[path]; found {
+			return value, 0
+		}
+		return nil, util.SubscriptionNotFound
+	})
+	patch3 := gomonkey.ApplyFunc(backend.DeletePaths, func(paths []string, continueOnFailure bool) int {
+		for _, path := range paths {
+			delete(recoveryStore, path)
+		}
+		return 0
+	})
+	defer patch1.Reset()
+	defer patch2.Reset()
+	defer patch3.Reset()
+
+	purgeFinishedTasks(expiryTime)
+
+	for taskId := range tasks {
+		_, statusFound := recoveryStore[util.AppDLCMTaskStatusPath+taskId+"App/"+taskId]
+		_, taskFound := recoveryStore[util.AppDLCMTasksPath+taskId]
+		expectPurge := taskId == "expiredSuccess" || taskId == "expiredFailure"
+		if statusFound == expectPurge || taskFound == expectPurge {
+			t.Errorf("task(%s) purge expected: %v", taskId, expectPurge)
+		}
+	}
+}
@@ -18,8 +18,10 @@ package task
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/apache/servicecomb-service-center/pkg/log"
+	"mepserver/common"
 	"mepserver/common/extif/backend"
 	"mepserver/common/extif/dataplane"
 	"mepserver/common/extif/dns"
@@ -35,11 +37,21 @@ type Worker struct {
 	dnsTypeConfig    string
 	dataPlane        dataplane.DataPlane
 	dnsAgent         dns.DNSAgent
+	// Task ids with a pending cancel request
+	cancelRequests sync.Map
+	// Task ids submitted and not yet finished, a cancel is accepted only for them
+	activeTasks map[string]bool
+	taskLock    sync.Mutex
+	poolSize    int
+	pool        *taskPool
+	poolOnce    sync.Once
 }
 
 const dataInconsisError = "failed to revert the data, this will lead to data inconsistency"
 const ExistRuleError = "existig rule expected"
 
+var errTaskCancelled = errors.New("task cancelled")
+
 func (w *Worker) InitializeWorker(dataPlane dataplane.DataPlane, dnsAgent dns.DNSAgent, dnsType string) *Worker {
 	w.dataPlane = dataPlane
 	w.dnsAgent = dnsAgent
@@ -47,15 +59,64 @@ func (w *Worker) InitializeWorker(dataPlane dataplane.DataPlane, dnsAgent dns.DN
 	return w
 }
 
+// Number of tasks processed in parallel, default size is used if it is not set
+func (w *Worker) WithPoolSize(size int) *Worker {
+	w.poolSize = size
+	return w
+}
+
 func (w *Worker) StartNewTask(appName, appInstanceId, taskId string) {
 	log.Infof("New task created(app-name: %s, app-id: %s, task-id: %s)", appName, appInstanceId, taskId)
-	w.waitWorkerFinish.Add(1)
-	go w.ProcessDataPlane(appName, appInstanceId, taskId)
-	return
+	w.enqueueTask(&queuedTask{AppName: appName, AppInstanceId: appInstanceId, TaskId: taskId})
+}
+
+// Request to cancel a queued or running task, the task stops before processing the next rule and reverts the applied
+// rules. Returns false if the task is already finished or is writing its config.
+func (w *Worker) CancelTask(taskId string) bool {
+	w.taskLock.Lock()
+	defer w.taskLock.Unlock()
+	if !w.activeTasks[taskId] {
+		return false
+	}
+	log.Infof("Cancel requested for the task(task-id: %s)", taskId)
+	w.cancelRequests.Store(taskId, struct{}{})
+	return true
+}
+
+func (w *Worker) setTaskActive(taskId string) {
+	w.taskLock.Lock()
+	defer w.taskLock.Unlock()
+	if w.activeTasks == nil {
+		w.activeTasks = make(map[string]bool)
+	}
+	w.activeTasks[taskId] = true
+}
+
+// Clear the task and its cancel request together, so that a cancel is never left over for a finished task
+func (w *Worker) setTaskFinished(taskId string) {
+	w.taskLock.Lock()
+	defer w.taskLock.Unlock()
+	delete(w.activeTasks, taskId)
+	w.cancelRequests.Delete(taskId)
+}
+
+func (w *Worker) isCancelRequested(taskId string) bool {
+	_, found := w.cancelRequests.Load(taskId)
+	return found
+}
+
+// Stop accepting the cancel for the task once all its rules are applied, returns true if a cancel was requested till
+// then. Check and close are done together, so that a cancel accepted for the task is never missed.
+func (w *Worker) closeTaskCancel(taskId string) bool {
+	w.taskLock.Lock()
+	defer w.taskLock.Unlock()
+	delete(w.activeTasks, taskId)
+	return w.isCancelRequested(taskId)
 }
 
 func (w *Worker) ProcessDataPlane(appName, appInstanceId, taskId string) {
 	defer w.waitWorkerFinish.Done()
+	defer w.setTaskFinished(taskId)
 	defer func() {
 		if r := recover(); r != nil {
 			log.Errorf(nil, "Sync process panic: %v \n %s", r, string(debug.Stack()))
@@ -69,18 +130,18 @@ func (w *Worker) ProcessDataPlaneSync(appName, appInstanceId, taskId string) {
 
 	syncJob := newTask(appName, appInstanceId, taskId, w.dataPlane, w.dnsAgent, w.dnsTypeConfig)
 	if syncJob == nil {
-		log.Error("failed to process the task, something went wrong", nil)
-		_ = backend.DeletePaths([]string{util.AppDLCMJobsPath + appInstanceId}, true)
-		taskStatus := newStatusDB(appInstanceId, taskId)
-		if taskStatus != nil {
-			taskStatus.status.Progress = util.TaskProgressFailure
-			taskStatus.setFailureReason("Unexpected error in processing.")
-			_ = taskStatus.pushDB()
-		}
+		handleTaskCreationFailure(appInstanceId, taskId)
 		return
 	}
+	syncJob.isCancelled = func() bool {
+		return w.isCancelRequested(taskId)
+	}
+
 	err := syncJob.handleDNSRules(util.ApplyFunc)
-	if err != nil {
+	if err == errTaskCancelled {
+		syncJob.handleCancelOnProcessing()
+		return
+	} else if err != nil {
 		log.Error("failed to process the task in dns rules", err)
 		syncJob.statusDb.setFailureReason("Internal error(failed to configure dns rules).")
 		err = syncJob.handleErrorOnProcessing()
@@ -90,7 +151,10 @@ func (w *Worker) ProcessDataPlaneSync(appName, appInstanceId, taskId string) {
 		return
 	}
 	err = syncJob.handleTrafficRules(util.ApplyFunc)
-	if err != nil {
+	if err == errTaskCancelled {
+		syncJob.handleCancelOnProcessing()
+		return
+	} else if err != nil {
 		log.Error("failed to process the task in traffic rules", err)
 		syncJob.statusDb.setFailureReason("Internal error(failed to configure traffic rules).")
 		err = syncJob.handleErrorOnProcessing()
@@ -99,6 +163,11 @@ func (w *Worker) ProcessDataPlaneSync(appName, appInstanceId, taskId string) {
 		}
 		return
 	}
+	// Applied rules can still be reverted, once the config is written the task can't be cancelled anymore
+	if w.closeTaskCancel(taskId) {
+		syncJob.handleCancelOnProcessing()
+		return
+	}
 	err = syncJob.handleConfigDBWriteOnSuccess()
 	if err != nil {
 		log.Error("failed to save appd config", err)
@@ -112,6 +181,101 @@ func (w *Worker) ProcessDataPlaneSync(appName, appInstanceId, taskId string) {
 
 	_ = syncJob.cleanProcessingCache()
 
+	notifyTaskCompletion(appInstanceId, taskId, syncJob.statusDb.status)
+}
+
+// Go Routine function to revert a task which was failed and left unfinished
+func (w *Worker) ProcessDataPlaneRevert(appName, appInstanceId, taskId string) {
+	defer w.waitWorkerFinish.Done()
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf(nil, "Sync revert process panic: %v \n %s", r, string(debug.Stack()))
+		}
+	}()
+
+	syncJob := newTask(appName, appInstanceId, taskId, w.dataPlane, w.dnsAgent, w.dnsTypeConfig)
+	if syncJob == nil {
+		handleTaskCreationFailure(appInstanceId, taskId)
+		return
+	}
+	// Keep the cancelled progress of a cancelled task
+	progress := syncJob.statusDb.status.Progress
+	if syncJob.statusDb.status.Reverting != 0 {
+		progress = syncJob.statusDb.status.Reverting
+	}
+	if err := syncJob.handleRevertOnProcessing(progress); err != nil {
+		log.Error(dataInconsisError, err)
+	}
+}
+
+// Resume or roll back the tasks left unfinished by a previous run of the mep-server. Jobs DB holds the entry till
+// the task completes, hence any entry in it on startup is an interrupted task.
+func (w *Worker) RecoverUnfinishedTasks() {
+	// Wait for the data-store to be available
+	backend.WaitForReady()
+	w.recoverUnfinishedTasks()
+}
+
+func (w *Worker) recoverUnfinishedTasks() {
+	records, errCode := backend.GetRecords(util.AppDLCMJobsPath)
+	if errCode != 0 {
+		log.Errorf(nil, "retrieve unfinished jobs from data-store failed")
+		return
+	}
+
+	// Each job belongs to a different app instance, hence the tasks are independent of each other
+	for appInstanceId, record := range records {
+		if queued := w.recoverTask(appInstanceId, record); queued != nil {
+			w.enqueueTask(queued)
+		}
+	}
+}
+
+func (w *Worker) recoverTask(appInstanceId string, jobEntry []byte) *queuedTask {
+	appDConfig := &models.AppDConfig{}
+	if err := json.Unmarshal(jobEntry, appDConfig); err != nil || len(appDConfig.TaskId) == 0 {
+		// Task can't be identified, remove the job to unblock the further operations on this app
+		log.Errorf(nil, "unfinished job(app-id: %s) without a valid task, removing it", appInstanceId)
+		_ = backend.DeletePaths([]string{util.AppDLCMJobsPath + appInstanceId}, true)
+		return nil
+	}
+	taskId := appDConfig.TaskId
+
+	taskStatus := newStatusDB(appInstanceId, taskId)
+	if taskStatus == nil {
+		handleTaskCreationFailure(appInstanceId, taskId)
+		return nil
+	}
+
+	// A failed or cancelled task was interrupted during the revert, hence continue the revert. Otherwise the task is resumed
+	// from the recorded state of each rule.
+	rollback := taskStatus.status.Reverting != 0 || taskStatus.status.Progress == util.TaskProgressFailure ||
+		taskStatus.status.Progress == util.TaskProgressCancelled
+	if rollback {
+		taskStatus.status.Recovery = util.TaskRecoveryRolledBack
+	} else {
+		taskStatus.status.Recovery = util.TaskRecoveryResumed
+	}
+	if err := taskStatus.pushDB(); err != nil {
+		log.Errorf(err, "task(app-id: %s, task-id: %s) recovery status update failed", appInstanceId, taskId)
+		return nil
+	}
+
+	log.Infof("Recovering unfinished task(app-name: %s, app-id: %s, task-id: %s, recovery: %s)",
+		appDConfig.AppName, appInstanceId, taskId, taskStatus.status.Recovery)
+	return &queuedTask{AppName: appDConfig.AppName, AppInstanceId: appInstanceId, TaskId: taskId, Revert: rollback}
+}
+
+// Clean the job and mark the task as failed, if the task could not be loaded for processing
+func handleTaskCreationFailure(appInstanceId, taskId string) {
+	log.Error("failed to process the task, something went wrong", nil)
+	_ = backend.DeletePaths([]string{util.AppDLCMJobsPath + appInstanceId}, true)
+	taskStatus := newStatusDB(appInstanceId, taskId)
+	if taskStatus != nil {
+		taskStatus.status.Progress = util.TaskProgressFailure
+		taskStatus.setFailureReason("Unexpected error in processing.")
+		_ = taskStatus.pushDB()
+	}
 }
 
 type ruleOperation struct {
@@ -131,6 +295,8 @@ type task struct {
 	dnsAgent        dns.DNSAgent
 	dnsStateMachine [][]*ruleOperation
 	trfStateMachine [][]*ruleOperation
+	localDnsBatch   *dns.RecordBatch
+	isCancelled     func() bool
 }
 
 func newTask(appName, appInstanceId, taskId string, dataPlane dataplane.DataPlane, dnsAgent dns.DNSAgent,
@@ -178,7 +344,7 @@ func newTask(appName, appInstanceId, taskId string, dataPlane dataplane.DataPlan
 	}
 
 	// Cleanup states as per mep-server config
-	if dnsType == util.DnsAgentTypeLocal {
+	if dnsType == util.DnsAgentTypeLocal || dnsType == util.DnsAgentTypeRFC2136 {
 		for oper := util.OperCreate; oper <= util.OperDelete; oper++ {
 			j.dnsStateMachine[oper][util.WaitMp2] = nil
 		}
@@ -290,6 +456,10 @@ func (t *task) handleTrafficRules(funcType util.FuncType) error {
 	var trfNewRuleMap, trfOldRuleMap = t.generateTrafficRuleMap(funcType)
 
 	for _, trRuleStatus := range t.statusDb.status.TrafficRuleStatusLst {
+		// Cancellation is checked between the rules, revert is never interrupted
+		if funcType == util.ApplyFunc && t.cancelRequested() {
+			return errTaskCancelled
+		}
 		if funcType == util.RevertFunc {
 			err = t.processTrfEntryRevert(trfNewRuleMap[trRuleStatus.Id], trfOldRuleMap[trRuleStatus.Id], trRuleStatus)
 		} else {
@@ -357,12 +527,8 @@ func (t *task) processTrfEntryRevert(trfNewRule *dataplane.TrafficRule, trfOldRu
 			return err
 		}
 
-		// No need to set the state if it already reached the first one
-		if state == util.WaitMp2 {
-			return nil
-		}
-		// Set next state on db
-		err = t.statusDb.setStateAndProgress(util.RuleTypeTraffic, ruleStatus.Id, state-1)
+		// Set the reverted state on db, the operations below it are still to be reverted
+		err = t.statusDb.setStateAndProgress(util.RuleTypeTraffic, ruleStatus.Id, state)
 		if err != nil {
 			log.Errorf(err, "Traffic revert(method:%v, state: %v) failed in setting status DB.",
 				ruleStatus.Method, state)
@@ -379,7 +545,19 @@ func (t *task) handleDNSRules(funcType util.FuncType) error {
 	var err error
 	var dnsNewRuleMap, dnsOldRuleMap = t.generateDnsRuleMap(funcType)
 
+	// Local dns-server is updated last on apply, hence reverted first
+	if funcType == util.RevertFunc {
+		err = t.processDNSLocalBatch(funcType, dnsNewRuleMap, dnsOldRuleMap)
+		if err != nil {
+			return err
+		}
+	}
+
 	for _, dnsRuleStatus := range t.statusDb.status.DNSRuleStatusLst {
+		// Cancellation is checked between the rules, revert is never interrupted
+		if funcType == util.ApplyFunc && t.cancelRequested() {
+			return errTaskCancelled
+		}
 		if funcType == util.RevertFunc {
 			err = t.processDNSEntryRevert(dnsNewRuleMap[dnsRuleStatus.Id], dnsOldRuleMap[dnsRuleStatus.Id],
 				dnsRuleStatus)
@@ -392,13 +570,94 @@ func (t *task) handleDNSRules(funcType util.FuncType) error {
 		}
 	}
 
+	if funcType == util.ApplyFunc {
+		if t.cancelRequested() {
+			return errTaskCancelled
+		}
+		return t.processDNSLocalBatch(funcType, dnsNewRuleMap, dnsOldRuleMap)
+	}
 	return nil
 }
 
+func (t *task) cancelRequested() bool {
+	return t.isCancelled != nil && t.isCancelled()
+}
+
+// Process the local dns-server state of all the dns rules together, so that the dns-server gets a single
+// atomic update instead of one request per rule
+func (t *task) processDNSLocalBatch(funcType util.FuncType, dnsNewRuleMap map[string]*dataplane.DNSRule,
+	dnsOldRuleMap map[string]*dataplane.DNSRule) error {
+
+	t.localDnsBatch = &dns.RecordBatch{}
+	var batchRuleIds []string
+	for _, ruleStatus := range t.statusDb.status.DNSRuleStatusLst {
+		// On revert only the rules which have completed the local state need to be processed
+		if (funcType == util.ApplyFunc && ruleStatus.State != util.WaitLocal) ||
+			(funcType == util.RevertFunc && ruleStatus.State <= util.WaitLocal) {
+			continue
+		}
+		batchRuleIds = append(batchRuleIds, ruleStatus.Id)
+
+		operation := t.dnsStateMachine[ruleStatus.Method][util.WaitLocal]
+		if operation == nil {
+			continue
+		}
+		var err error
+		if funcType == util.ApplyFunc && operation.apply != nil {
+			err = operation.apply(ruleStatus.Id, dnsNewRuleMap[ruleStatus.Id], dnsOldRuleMap[ruleStatus.Id])
+		} else if funcType == util.RevertFunc && operation.revert != nil {
+			err = operation.revert(ruleStatus.Id, dnsNewRuleMap[ruleStatus.Id], dnsOldRuleMap[ruleStatus.Id])
+		}
+		if err != nil {
+			log.Errorf(err, "DNS batch(method:%v, func: %v) failed in preparing the rule(%s).",
+				ruleStatus.Method, funcType, ruleStatus.Id)
+			t.statusDb.setFailureReason("Failed in configuring dns rule on remote dns-server/data-plane.")
+			return err
+		}
+	}
+
+	if !t.localDnsBatch.IsEmpty() {
+		log.Debugf("DNS batch(func: %v, set: %d, delete: %d)", funcType, len(t.localDnsBatch.Set),
+			len(t.localDnsBatch.Delete))
+		if err := t.dnsAgent.UpdateResourceRecords(t.localDnsBatch); err != nil {
+			log.Errorf(err, "DNS batch(func: %v) failed in configuration.", funcType)
+			if funcType == util.ApplyFunc {
+				t.statusDb.setFailureReason("Failed in configuring dns rule on remote dns-server/data-plane.")
+			} else {
+				t.statusDb.setFailureReason("Failed in reverting dns rule on remote dns-server/data-plane.")
+			}
+			return err
+		}
+	}
+
+	// Set next state on db for all the rules in the batch
+	nextState := util.WaitConfigDBWrite
+	if funcType == util.RevertFunc {
+		nextState = util.WaitLocal
+	}
+	for _, ruleId := range batchRuleIds {
+		if err := t.statusDb.setStateAndProgress(util.RuleTypeDns, ruleId, nextState); err != nil {
+			log.Errorf(err, "DNS batch(func: %v) failed in setting dns status.", funcType)
+			t.statusDb.setFailureReason("Failed in setting status for dns rule.")
+			return err
+		}
+	}
+	return nil
+}
+
+// Local dns-server modifications are collected and sent together
+func (t *task) getLocalDnsBatch() *dns.RecordBatch {
+	if t.localDnsBatch == nil {
+		t.localDnsBatch = &dns.RecordBatch{}
+	}
+	return t.localDnsBatch
+}
+
 func (t *task) processDNSEntryApply(dnsNewRule *dataplane.DNSRule, dnsOldRule *dataplane.DNSRule,
 	ruleStatus models.RuleStatus) error {
 
-	for state := util.WaitMp2; state < util.WaitConfigDBWrite; state++ {
+	// Local state is processed for all the rules together in a batch
+	for state := util.WaitMp2; state < util.WaitLocal; state++ {
 		operation := t.dnsStateMachine[ruleStatus.Method][state]
 		if state < ruleStatus.State {
 			continue
@@ -446,12 +705,8 @@ func (t *task) processDNSEntryRevert(dnsNewRule *dataplane.DNSRule, dnsOldRule *
 			return err
 		}
 
-		// No need to set the state if it already reached the first one
-		if state == util.WaitMp2 {
-			return nil
-		}
-		// Set next state on db
-		err = t.statusDb.setStateAndProgress(util.RuleTypeDns, ruleStatus.Id, state-1)
+		// Set the reverted state on db, the operations below it are still to be reverted
+		err = t.statusDb.setStateAndProgress(util.RuleTypeDns, ruleStatus.Id, state)
 		if err != nil {
 			log.Errorf(err, "DNS revert(method:%v, state: %v) failed in setting dns revert status.",
 				ruleStatus.Method, state)
@@ -506,6 +761,9 @@ func (t *task) setDNSOnMp2(ruleId string, newRule interface{}, existingRule inte
 	} else if dnsExistingRule.State == util.ActiveState && dnsRule.State == util.InactiveState {
 		// Delete rule
 		return t.dataPlane.DeleteDNSRule(appInfo, ruleId)
+	} else if dnsRule.State == util.InactiveState {
+		// Inactive rule is not available in the Mp2, only the stored data changes
+		return nil
 	}
 
 	return t.dataPlane.SetDNSRule(appInfo, ruleId, dnsRule.DomainName, dnsRule.IPAddressType,
@@ -546,17 +804,30 @@ func (t *task) addDNSOnLocalDns(ruleId string, newRule interface{}, existingRule
 	if dnsRule.IPAddressType == util.IPv6Type {
 		rrType = util.RRTypeAAAA
 	}
-	err := t.dnsAgent.SetResourceRecordTypeA(
-		dnsRule.DomainName, rrType, util.RRClassIN, []string{dnsRule.IPAddress},
+	t.getLocalDnsBatch().AddSet(dnsRule.DomainName, rrType, util.RRClassIN, []string{dnsRule.IPAddress},
 		dnsRule.TTL)
-	if err != nil {
-		return err
-	}
-
-	return err
+	return nil
 }
 
 func (t *task) setDNSOnLocalDns(ruleId string, newRule interface{}, existingRule interface{}) error {
+	if existingRule == nil {
+		return fmt.Errorf(ExistRuleError)
+	}
+	dnsRule := newRule.(*dataplane.DNSRule)
+	dnsExistingRule := existingRule.(*dataplane.DNSRule)
+
+	if dnsRule.State == "" {
+		dnsRule.State = util.ActiveState
+	}
+	if dnsExistingRule.State == "" {
+		dnsExistingRule.State = util.ActiveState
+	}
+
+	if dnsExistingRule.State == util.ActiveState && dnsRule.State == util.InactiveState {
+		// Delete the record which was added while the rule was active
+		return t.deleteDNSOnLocalDns(ruleId, existingRule, existingRule)
+	}
+	// Add or update the record, inactive to inactive is skipped inside the add
 	return t.addDNSOnLocalDns(ruleId, newRule, existingRule)
 }
 
@@ -578,12 +849,8 @@ func (t *task) deleteDNSOnLocalDns(ruleId string, newRule interface{}, existingR
 	if dnsRule.IPAddressType == util.IPv6Type {
 		rrType = util.RRTypeAAAA
 	}
-	err := t.dnsAgent.DeleteResourceRecordTypeA(dnsRule.DomainName, rrType)
-	if err != nil {
-		return err
-	}
-
-	return err
+	t.getLocalDnsBatch().AddDelete(dnsRule.DomainName, rrType)
+	return nil
 }
 
 func (t *task) addTrafficOnMp2(ruleId string, newRule interface{}, existingRule interface{}) error {
@@ -609,7 +876,7 @@ func (t *task) setTrafficOnMp2(ruleId string, newRule interface{}, existingRule
 	if existingRule == nil {
 		return fmt.Errorf(ExistRuleError)
 	}
-	trExistingRule := existingRule.(*dataplane.DNSRule)
+	trExistingRule := existingRule.(*dataplane.TrafficRule)
 
 	appInfo := dataplane.ApplicationInfo{
 		ApplicationId:   t.appInstanceId,
@@ -629,6 +896,9 @@ func (t *task) setTrafficOnMp2(ruleId string, newRule interface{}, existingRule
 	} else if trExistingRule.State == util.ActiveState && trRule.State == util.InactiveState {
 		// Delete rule
 		return t.dataPlane.DeleteTrafficRule(appInfo, ruleId)
+	} else if trRule.State == util.InactiveState {
+		// Inactive rule is not available in the Mp2, only the stored data changes
+		return nil
 	}
 
 	return t.dataPlane.SetTrafficRule(appInfo, ruleId, trRule.FilterType, trRule.Action,
@@ -674,8 +944,9 @@ func (t *task) handleConfigDBWriteOnSuccess() error {
 
 	operation := t.appDJobDb.appDConfig.Operation
 
-	// Cleaning the operation field to avoid it in save
+	// Cleaning the operation and task fields to avoid it in save
 	t.appDJobDb.appDConfig.Operation = ""
+	t.appDJobDb.appDConfig.TaskId = ""
 
 	appDConfigBytes, err := json.Marshal(t.appDJobDb.appDConfig)
 	if err != nil {
@@ -696,12 +967,41 @@ func (t *task) handleConfigDBWriteOnSuccess() error {
 		return err
 	}
 
+	// History is best effort, failing to record it doesn't fail the applied configuration
+	if operation == http.MethodPost || operation == http.MethodPut {
+		common.SaveAppDConfigVersion(t.appInstanceId, t.taskId, t.appDJobDb.appDConfig)
+	} else if operation == http.MethodDelete {
+		common.DeleteAppDConfigVersions(t.appInstanceId)
+	}
+
 	return nil
 }
 
 // Handle any error cases during the process
 func (t *task) handleErrorOnProcessing() error {
-	err := t.handleDNSRules(util.RevertFunc)
+	return t.handleRevertOnProcessing(util.TaskProgressFailure)
+}
+
+// Handle the cancel request during the process by reverting the rules applied so far
+func (t *task) handleCancelOnProcessing() {
+	log.Infof("Task cancelled(app-id: %s, task-id: %s), reverting the applied rules", t.appInstanceId, t.taskId)
+	t.statusDb.setFailureReason("Task cancelled by the user.")
+	if err := t.handleRevertOnProcessing(util.TaskProgressCancelled); err != nil {
+		log.Error(dataInconsisError, err)
+	}
+}
+
+// Revert the applied rules and finish the task with the given progress
+func (t *task) handleRevertOnProcessing(progress int) error {
+	// Record the revert before the first rule is reverted, so that a restart continues the revert
+	t.statusDb.status.Reverting = progress
+	err := t.statusDb.pushDB()
+	if err != nil {
+		log.Errorf(nil, "couldn't update reverting status, this will lead to data inconsistency")
+		return err
+	}
+
+	err = t.handleDNSRules(util.RevertFunc)
 	if err != nil {
 		log.Error("failed to revert dns rules", err)
 		return err
@@ -713,12 +1013,14 @@ func (t *task) handleErrorOnProcessing() error {
 		return err
 	}
 
-	t.statusDb.status.Progress = util.TaskProgressFailure
+	t.statusDb.status.Progress = progress
+	t.statusDb.status.Reverting = 0
 	err = t.statusDb.pushDB()
 	if err != nil {
 		log.Errorf(nil, "couldn't update progress failure status, this will lead to data inconsistency")
 		return err
 	}
+	notifyTaskCompletion(t.appInstanceId, t.taskId, t.statusDb.status)
 
 	return t.cleanProcessingCache()
 }
@@ -17,6 +17,7 @@ package task
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/agiledragon/gomonkey"
 	uuid "github.com/satori/go.uuid"
@@ -28,6 +29,8 @@ import (
 	"mepserver/common/extif/dns"
 	"mepserver/common/models"
 	"mepserver/common/util"
+	"reflect"
+	"strings"
 	"sync"
 	"testing"
 )
@@ -304,3 +307,498 @@ func TestProcessTrfEntryRevert(t *testing.T) {
 	defer patch2.Reset()
 	j.processTrfEntryRevert(&trafficRule, &trafficRule, ruleStatus)
 }
+
+func TestSetTrafficOnMp2ActiveToInactive(t *testing.T) {
+	existingRule := dataplane.TrafficRule{TrafficRuleID: ruleId, FilterType: "FLOW", State: "ACTIVE"}
+	newRule := dataplane.TrafficRule{TrafficRuleID: ruleId, FilterType: "FLOW", State: "INACTIVE"}
+
+	noneDataPlane := &none.NoneDataPlane{}
+	j := &task{appInstanceId: defaultAppInstanceId, taskId: ruleId, dataPlane: noneDataPlane}
+
+	deleted := false
+	patch1 := gomonkey.ApplyMethod(reflect.TypeOf(noneDataPlane), "DeleteTrafficRule",
+		func(*none.NoneDataPlane, dataplane.ApplicationInfo, string) error {
+			deleted = true
+			return nil
+		})
+	defer patch1.Reset()
+
+	if err := j.setTrafficOnMp2(ruleId, &newRule, &existingRule); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !deleted {
+		t.Errorf("traffic rule must be deleted from the data-plane on deactivation")
+	}
+
+	// Inactive to inactive must not reach the data-plane
+	deleted = false
+	existingRule.State = "INACTIVE"
+	if err := j.setTrafficOnMp2(ruleId, &newRule, &existingRule); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if deleted {
+		t.Errorf("inactive traffic rule must not be sent to the data-plane")
+	}
+}
+
+// Records the dns-server batch operations to verify the rule handling
+type recordDNSAgent struct {
+	batches []*dns.RecordBatch
+}
+
+func (d *recordDNSAgent) SetResourceRecordTypeA(host, rrtype, class string, pointTo []string, ttl uint32) error {
+	return fmt.Errorf("single record update not expected")
+}
+
+func (d *recordDNSAgent) DeleteResourceRecordTypeA(host, rrtype string) error {
+	return fmt.Errorf("single record delete not expected")
+}
+
+func (d *recordDNSAgent) UpdateResourceRecords(batch *dns.RecordBatch) error {
+	d.batches = append(d.batches, batch)
+	return nil
+}
+
+func TestSetDNSOnLocalDnsActiveToInactive(t *testing.T) {
+	existingRule := dataplane.DNSRule{DNSRuleID: ruleId, DomainName: "www.example.com", IPAddressType: "IP_V4",
+		IPAddress: exampleIPAddress, State: "ACTIVE"}
+	newRule := existingRule
+	newRule.State = "INACTIVE"
+
+	j := &task{appInstanceId: defaultAppInstanceId, taskId: ruleId, dnsAgent: &recordDNSAgent{}}
+
+	if err := j.setDNSOnLocalDns(ruleId, &newRule, &existingRule); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(j.localDnsBatch.Delete) != 1 || len(j.localDnsBatch.Set) != 0 {
+		t.Errorf("dns record must be deleted from the dns-server on deactivation")
+	}
+
+	// Inactive to active must add the record back
+	j.localDnsBatch = nil
+	if err := j.setDNSOnLocalDns(ruleId, &existingRule, &newRule); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(j.localDnsBatch.Set) != 1 || len(j.localDnsBatch.Delete) != 0 {
+		t.Errorf("dns record must be added to the dns-server on activation")
+	}
+}
+
+func TestHandleDNSRulesInBatch(t *testing.T) {
+	var ruleList []models.RuleStatus
+	var dnsRules []dataplane.DNSRule
+	for i := 0; i < 3; i++ {
+		id := fmt.Sprintf("dnsRule%d", i)
+		ruleList = append(ruleList, models.RuleStatus{Id: id, State: util.WaitMp2, Method: util.OperCreate})
+		dnsRules = append(dnsRules, dataplane.DNSRule{DNSRuleID: id, DomainName: fmt.Sprintf("www.example%d.com", i),
+			IPAddressType: "IP_V4", IPAddress: exampleIPAddress, State: "ACTIVE"})
+	}
+	dnsAgent := &recordDNSAgent{}
+	j := &task{appInstanceId: defaultAppInstanceId, taskId: ruleId, appDJobDb: &appDJobDB{appInstanceId: defaultAppInstanceId,
+		appDConfig: &models.AppDConfig{AppName: "AppName", AppDNSRule: dnsRules}},
+		statusDb: &statusDB{appInstanceId: defaultAppInstanceId,
+			status: &models.TaskStatus{DNSRuleStatusLst: ruleList}},
+		dataPlane: &none.NoneDataPlane{}, dnsAgent: dnsAgent}
+	j.dnsStateMachine = [][]*ruleOperation{
+		util.OperCreate: {
+			util.WaitMp2:           &ruleOperation{j.addDNSOnMp2, j.deleteDNSOnMp2, util.WaitLocal},
+			util.WaitLocal:         &ruleOperation{j.addDNSOnLocalDns, j.deleteDNSOnLocalDns, util.WaitConfigDBWrite},
+			util.WaitConfigDBWrite: &ruleOperation{nil, nil, 0},
+		},
+	}
+
+	patch1 := gomonkey.ApplyFunc(backend.PutRecord, func(path string, value []byte) int {
+		return 0
+	})
+	defer patch1.Reset()
+
+	if err := j.handleDNSRules(util.ApplyFunc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(dnsAgent.batches) != 1 || len(dnsAgent.batches[0].Set) != 3 {
+		t.Errorf("all the dns rules must be sent in a single batch")
+	}
+	for _, ruleStatus := range j.statusDb.status.DNSRuleStatusLst {
+		if ruleStatus.State != util.WaitConfigDBWrite {
+			t.Errorf("dns rule(%s) must be in config db write state", ruleStatus.Id)
+		}
+	}
+	if j.statusDb.status.Progress != 3 {
+		t.Errorf("progress must be updated for all the dns rules")
+	}
+
+	// Revert must remove all the records in a single batch
+	if err := j.handleDNSRules(util.RevertFunc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(dnsAgent.batches) != 2 || len(dnsAgent.batches[1].Delete) != 3 {
+		t.Errorf("all the dns rules must be reverted in a single batch")
+	}
+}
+
+// In-memory data-store for the recovery test
+var recoveryStore map[string][]byte
+
+func putRecoveryEntry(path string, value interface{}) {
+	entryBytes, _ := json.Marshal(value)
+	recoveryStore[path] = entryBytes
+}
+
+// Records of the in-memory data-store under the path, keyed by the complete path
+func getRecoveryRecords(path string) (map[string][]byte, int) {
+	records := make(map[string][]byte)
+	for key, value := range recoveryStore {
+		if strings.HasPrefix(key, path) {
+			records[key] = value
+		}
+	}
+	return records, 0
+}
+
+func TestRecoverUnfinishedTasks(t *testing.T) {
+	const resumeAppId = "e0f0a6bd-5c2b-4e0c-9d3c-36b0d9f2e7a1"
+	const rollbackAppId = "8a7c1d2e-4f5b-4a6c-8d9e-0f1a2b3c4d5e"
+	const legacyAppId = "3c2b1a0f-9e8d-4c7b-a6f5-e4d3c2b1a0f9"
+	const resumeTaskId = "resume-task"
+	const rollbackTaskId = "rollback-task"
+
+	trafficRules := []dataplane.TrafficRule{{TrafficRuleID: ruleId, FilterType: "FLOW", State: "ACTIVE"}}
+	recoveryStore = make(map[string][]byte)
+	store := recoveryStore
+	putRecoveryEntry(util.AppDLCMJobsPath+resumeAppId, &models.AppDConfig{AppName: "ResumeApp", AppTrafficRule: trafficRules,
+		Operation: "POST", TaskId: resumeTaskId})
+	putRecoveryEntry(util.AppDLCMTaskStatusPath+resumeAppId+"/"+resumeTaskId, &models.TaskStatus{Progress: 0,
+		TrafficRuleStatusLst: []models.RuleStatus{{Id: ruleId, State: util.WaitMp2, Method: util.OperCreate}}})
+	putRecoveryEntry(util.AppDLCMJobsPath+rollbackAppId, &models.AppDConfig{AppName: "RollbackApp",
+		AppTrafficRule: trafficRules, Operation: "POST", TaskId: rollbackTaskId})
+	putRecoveryEntry(util.AppDLCMTaskStatusPath+rollbackAppId+"/"+rollbackTaskId, &models.TaskStatus{
+		Progress: util.TaskProgressFailure, Details: "Failed in configuring traffic rule on remote data-plane.",
+		TrafficRuleStatusLst: []models.RuleStatus{{Id: ruleId, State: util.WaitConfigDBWrite, Method: util.OperCreate}}})
+	putRecoveryEntry(util.AppDLCMJobsPath+legacyAppId, &models.AppDConfig{AppName: "LegacyApp", Operation: "POST"})
+
+	patch1 := gomonkey.ApplyFunc(backend.GetRecords, func(path string) (map[string][]byte, int) {
+		records := make(map[string][]byte)
+		for key, value := range recoveryStore {
+			if len(key) > len(path) && key[:len(path)] == path {
+				records[key[len(path):]] = value
+			}
+		}
+		return records, 0
+	})
+	patch2 := gomonkey.ApplyFunc(backend.GetRecord, func(path string) ([]byte, int) {
+		if value, found := recoveryStore[path]; found {
+			return value, 0
+		}
+		return nil, util.SubscriptionNotFound
+	})
+	patch3 := gomonkey.ApplyFunc(backend.PutRecord, func(path string, value []byte) int {
+		recoveryStore[path] = value
+		return 0
+	})
+	patch4 := gomonkey.ApplyFunc(backend.DeletePaths, func(paths []string, continueOnFailure bool) int {
+		for _, path := range paths {
+			delete(recoveryStore, path)
+		}
+		return 0
+	})
+	patch5 := gomonkey.ApplyFunc(backend.GetRecordsWithCompleteKeyPath, getRecoveryRecords)
+	defer patch1.Reset()
+	defer patch2.Reset()
+	defer patch3.Reset()
+	defer patch4.Reset()
+	defer patch5.Reset()
+
+	worker := Worker{dataPlane: &none.NoneDataPlane{}, dnsTypeConfig: util.DnsAgentTypeDataPlane}
+	worker.recoverUnfinishedTasks()
+	worker.waitWorkerFinish.Wait()
+
+	for _, appId := range []string{resumeAppId, rollbackAppId, legacyAppId} {
+		if _, found := store[util.AppDLCMJobsPath+appId]; found {
+			t.Errorf("unfinished job(%s) must be cleared after recovery", appId)
+		}
+	}
+
+	resumeStatus := &models.TaskStatus{}
+	_ = json.Unmarshal(store[util.AppDLCMTaskStatusPath+resumeAppId+"/"+resumeTaskId], resumeStatus)
+	if resumeStatus.Recovery != util.TaskRecoveryResumed || resumeStatus.Progress != 1 {
+		t.Errorf("task must be resumed to completion, recovery: %s, progress: %d", resumeStatus.Recovery,
+			resumeStatus.Progress)
+	}
+	appDConfig := &models.AppDConfig{}
+	if err := json.Unmarshal(store[util.AppDConfigKeyPath+resumeAppId], appDConfig); err != nil {
+		t.Fatalf("resumed task must store the appd config")
+	}
+	if len(appDConfig.TaskId) != 0 || len(appDConfig.Operation) != 0 {
+		t.Errorf("processing fields must not be stored in the appd config")
+	}
+
+	rollbackStatus := &models.TaskStatus{}
+	_ = json.Unmarshal(store[util.AppDLCMTaskStatusPath+rollbackAppId+"/"+rollbackTaskId], rollbackStatus)
+	if rollbackStatus.Recovery != util.TaskRecoveryRolledBack || rollbackStatus.Progress != util.TaskProgressFailure {
+		t.Errorf("failed task must be rolled back, recovery: %s, progress: %d", rollbackStatus.Recovery,
+			rollbackStatus.Progress)
+	}
+	if _, found := store[util.AppDConfigKeyPath+rollbackAppId]; found {
+		t.Errorf("rolled back task must not store the appd config")
+	}
+}
+
+// Data-plane which requests the task cancel once the first traffic rule is added
+type cancellingDataPlane struct {
+	none.NoneDataPlane
+	worker  *Worker
+	taskId  string
+	applied map[string]bool
+}
+
+func (d *cancellingDataPlane) AddTrafficRule(appInfo dataplane.ApplicationInfo, trafficRuleId, filterType,
+	action string, priority int, filter []dataplane.TrafficFilter) error {
+	d.applied[trafficRuleId] = true
+	d.worker.CancelTask(d.taskId)
+	return nil
+}
+
+func (d *cancellingDataPlane) DeleteTrafficRule(appInfo dataplane.ApplicationInfo, trafficRuleId string) error {
+	delete(d.applied, trafficRuleId)
+	return nil
+}
+
+func TestProcessDataPlaneCancel(t *testing.T) {
+	const cancelTaskId = "cancel-task"
+	trafficRules := []dataplane.TrafficRule{
+		{TrafficRuleID: "rule1", FilterType: "FLOW", State: "ACTIVE"},
+		{TrafficRuleID: "rule2", FilterType: "FLOW", State: "ACTIVE"},
+	}
+
+	recoveryStore = make(map[string][]byte)
+	putRecoveryEntry(util.AppDLCMJobsPath+defaultAppInstanceId, &models.AppDConfig{AppName: "AppName",
+		AppTrafficRule: trafficRules, Operation: "POST", TaskId: cancelTaskId})
+	putRecoveryEntry(util.AppDLCMTaskStatusPath+defaultAppInstanceId+"/"+cancelTaskId, &models.TaskStatus{
+		TrafficRuleStatusLst: []models.RuleStatus{
+			{Id: "rule1", State: util.WaitMp2, Method: util.OperCreate},
+			{Id: "rule2", State: util.WaitMp2, Method: util.OperCreate}}})
+
+	patch1 := gomonkey.ApplyFunc(backend.GetRecord, func(path string) ([]byte, int) {
+		if value, found := recoveryStore[path]; found {
+			return value, 0
+		}
+		return nil, util.SubscriptionNotFound
+	})
+	patch2 := gomonkey.ApplyFunc(backend.PutRecord, func(path string, value []byte) int {
+		recoveryStore[path] = value
+		return 0
+	})
+	patch3 := gomonkey.ApplyFunc(backend.DeletePaths, func(paths []string, continueOnFailure bool) int {
+		for _, path := range paths {
+			delete(recoveryStore, path)
+		}
+		return 0
+	})
+	defer patch1.Reset()
+	defer patch2.Reset()
+	defer patch3.Reset()
+
+	worker := &Worker{dnsTypeConfig: util.DnsAgentTypeDataPlane}
+	dataPlane := &cancellingDataPlane{worker: worker, taskId: cancelTaskId, applied: make(map[string]bool)}
+	worker.dataPlane = dataPlane
+	worker.setTaskActive(cancelTaskId)
+	worker.waitWorkerFinish.Add(1)
+	worker.ProcessDataPlane("AppName", defaultAppInstanceId, cancelTaskId)
+
+	if len(dataPlane.applied) != 0 {
+		t.Errorf("applied rules must be reverted on cancel, pending: %v", dataPlane.applied)
+	}
+	taskStatus := &models.TaskStatus{}
+	_ = json.Unmarshal(recoveryStore[util.AppDLCMTaskStatusPath+defaultAppInstanceId+"/"+cancelTaskId], taskStatus)
+	if taskStatus.Progress != util.TaskProgressCancelled {
+		t.Errorf("task must end with the cancelled progress, progress: %d", taskStatus.Progress)
+	}
+	for _, ruleStatus := range taskStatus.TrafficRuleStatusLst {
+		if ruleStatus.Id == "rule2" && ruleStatus.State != util.WaitMp2 {
+			t.Errorf("rule after the cancel must not be processed")
+		}
+	}
+	if _, found := recoveryStore[util.AppDLCMJobsPath+defaultAppInstanceId]; found {
+		t.Errorf("job must be cleared after the cancel")
+	}
+	if worker.isCancelRequested(cancelTaskId) {
+		t.Errorf("cancel request must be cleared after the task is finished")
+	}
+	if worker.CancelTask(cancelTaskId) || worker.isCancelRequested(cancelTaskId) {
+		t.Errorf("cancel must not be accepted for a finished task")
+	}
+}
+
+var lastRuleWorker *Worker
+var lastRuleCancelAccepted bool
+
+func putLastRuleRecord(path string, value []byte) int {
+	// Cancel arriving while the config is written is rejected
+	if path == util.AppDConfigKeyPath+defaultAppInstanceId {
+		lastRuleCancelAccepted = lastRuleWorker.CancelTask("last-rule-task")
+	}
+	recoveryStore[path] = value
+	return 0
+}
+
+// Cancel requested on the last rule reverts the task, while a cancel after the last rule is rejected
+func TestProcessDataPlaneCancelOnLastRule(t *testing.T) {
+	const lastRuleTaskId = "last-rule-task"
+	putLastRuleTask := func() {
+		recoveryStore = make(map[string][]byte)
+		putRecoveryEntry(util.AppDLCMJobsPath+defaultAppInstanceId, &models.AppDConfig{AppName: "AppName",
+			Operation: "POST", TaskId: lastRuleTaskId,
+			AppTrafficRule: []dataplane.TrafficRule{{TrafficRuleID: "rule1", FilterType: "FLOW", State: "ACTIVE"}}})
+		putRecoveryEntry(util.AppDLCMTaskStatusPath+defaultAppInstanceId+"/"+lastRuleTaskId, &models.TaskStatus{
+			TrafficRuleStatusLst: []models.RuleStatus{{Id: "rule1", State: util.WaitMp2, Method: util.OperCreate}}})
+	}
+
+	patches := gomonkey.ApplyFunc(backend.GetRecord, func(path string) ([]byte, int) {
+		if value, found := recoveryStore[path]; found {
+			return value, 0
+		}
+		return nil, util.SubscriptionNotFound
+	})
+	defer patches.Reset()
+	patches.ApplyFunc(backend.PutRecord, putLastRuleRecord)
+	patches.ApplyFunc(backend.DeletePaths, func(paths []string, continueOnFailure bool) int {
+		for _, path := range paths {
+			delete(recoveryStore, path)
+		}
+		return 0
+	})
+
+	putLastRuleTask()
+	worker := &Worker{dnsTypeConfig: util.DnsAgentTypeDataPlane}
+	lastRuleWorker = worker
+	dataPlane := &cancellingDataPlane{worker: worker, taskId: lastRuleTaskId, applied: make(map[string]bool)}
+	worker.dataPlane = dataPlane
+	worker.setTaskActive(lastRuleTaskId)
+	worker.waitWorkerFinish.Add(1)
+	worker.ProcessDataPlane("AppName", defaultAppInstanceId, lastRuleTaskId)
+
+	if len(dataPlane.applied) != 0 {
+		t.Errorf("last rule must be reverted on cancel, pending: %v", dataPlane.applied)
+	}
+	if _, found := recoveryStore[util.AppDConfigKeyPath+defaultAppInstanceId]; found {
+		t.Errorf("cancelled task must not store the appd config")
+	}
+	taskStatus := &models.TaskStatus{}
+	_ = json.Unmarshal(recoveryStore[util.AppDLCMTaskStatusPath+defaultAppInstanceId+"/"+lastRuleTaskId], taskStatus)
+	if taskStatus.Progress != util.TaskProgressCancelled {
+		t.Errorf("task must end with the cancelled progress, progress: %d", taskStatus.Progress)
+	}
+
+	putLastRuleTask()
+	worker = &Worker{dnsTypeConfig: util.DnsAgentTypeDataPlane, dataPlane: &none.NoneDataPlane{}}
+	lastRuleWorker = worker
+	lastRuleCancelAccepted = true
+	worker.setTaskActive(lastRuleTaskId)
+	worker.waitWorkerFinish.Add(1)
+	worker.ProcessDataPlane("AppName", defaultAppInstanceId, lastRuleTaskId)
+
+	if lastRuleCancelAccepted {
+		t.Errorf("cancel must not be accepted once the config is written")
+	}
+	if _, found := recoveryStore[util.AppDConfigKeyPath+defaultAppInstanceId]; !found {
+		t.Errorf("task must store the appd config")
+	}
+}
+
+// Data-plane which cancels the task on the first rule and fails to revert it
+type failingRevertDataPlane struct {
+	cancellingDataPlane
+}
+
+func (d *failingRevertDataPlane) DeleteTrafficRule(appInfo dataplane.ApplicationInfo, trafficRuleId string) error {
+	return errors.New("data-plane not reachable")
+}
+
+func TestRecoverInterruptedRevert(t *testing.T) {
+	const revertTaskId = "revert-task"
+	trafficRules := []dataplane.TrafficRule{
+		{TrafficRuleID: "rule1", FilterType: "FLOW", State: "ACTIVE"},
+		{TrafficRuleID: "rule2", FilterType: "FLOW", State: "ACTIVE"},
+	}
+
+	recoveryStore = make(map[string][]byte)
+	statusPath := util.AppDLCMTaskStatusPath + defaultAppInstanceId + "/" + revertTaskId
+	putRecoveryEntry(util.AppDLCMJobsPath+defaultAppInstanceId, &models.AppDConfig{AppName: "AppName",
+		AppTrafficRule: trafficRules, Operation: "POST", TaskId: revertTaskId})
+	putRecoveryEntry(statusPath, &models.TaskStatus{
+		TrafficRuleStatusLst: []models.RuleStatus{
+			{Id: "rule1", State: util.WaitMp2, Method: util.OperCreate},
+			{Id: "rule2", State: util.WaitMp2, Method: util.OperCreate}}})
+
+	patch1 := gomonkey.ApplyFunc(backend.GetRecord, func(path string) ([]byte, int) {
+		if value, found := recoveryStore[path]; found {
+			return value, 0
+		}
+		return nil, util.SubscriptionNotFound
+	})
+	patch2 := gomonkey.ApplyFunc(backend.PutRecord, func(path string, value []byte) int {
+		recoveryStore[path] = value
+		return 0
+	})
+	patch3 := gomonkey.ApplyFunc(backend.DeletePaths, func(paths []string, continueOnFailure bool) int {
+		for _, path := range paths {
+			delete(recoveryStore, path)
+		}
+		return 0
+	})
+	patch4 := gomonkey.ApplyFunc(backend.GetRecords, func(path string) (map[string][]byte, int) {
+		records := make(map[string][]byte)
+		for key, value := range recoveryStore {
+			if strings.HasPrefix(key, path) {
+				records[key[len(path):]] = value
+			}
+		}
+		return records, 0
+	})
+	patch5 := gomonkey.ApplyFunc(backend.GetRecordsWithCompleteKeyPath, getRecoveryRecords)
+	defer patch1.Reset()
+	defer patch2.Reset()
+	defer patch3.Reset()
+	defer patch4.Reset()
+	defer patch5.Reset()
+
+	// Revert of the cancelled task is interrupted by the data-plane failure
+	worker := &Worker{dnsTypeConfig: util.DnsAgentTypeDataPlane}
+	failingDataPlane := &failingRevertDataPlane{cancellingDataPlane{worker: worker, taskId: revertTaskId,
+		applied: make(map[string]bool)}}
+	worker.dataPlane = failingDataPlane
+	worker.setTaskActive(revertTaskId)
+	worker.waitWorkerFinish.Add(1)
+	worker.ProcessDataPlane("AppName", defaultAppInstanceId, revertTaskId)
+
+	taskStatus := &models.TaskStatus{}
+	_ = json.Unmarshal(recoveryStore[statusPath], taskStatus)
+	if taskStatus.Reverting != util.TaskProgressCancelled || taskStatus.Progress < 0 {
+		t.Fatalf("interrupted revert must be recorded, reverting: %d, progress: %d", taskStatus.Reverting,
+			taskStatus.Progress)
+	}
+
+	// Recovery after the restart must continue the revert instead of applying the task
+	restarted := &Worker{dnsTypeConfig: util.DnsAgentTypeDataPlane}
+	dataPlane := &cancellingDataPlane{worker: restarted, taskId: revertTaskId, applied: failingDataPlane.applied}
+	restarted.dataPlane = dataPlane
+	restarted.recoverUnfinishedTasks()
+	restarted.waitWorkerFinish.Wait()
+
+	if len(dataPlane.applied) != 0 {
+		t.Errorf("applied rules must be reverted on recovery, pending: %v", dataPlane.applied)
+	}
+	taskStatus = &models.TaskStatus{}
+	_ = json.Unmarshal(recoveryStore[statusPath], taskStatus)
+	if taskStatus.Recovery != util.TaskRecoveryRolledBack || taskStatus.Progress != util.TaskProgressCancelled ||
+		taskStatus.Reverting != 0 {
+		t.Errorf("revert must be continued on recovery, recovery: %s, progress: %d, reverting: %d",
+			taskStatus.Recovery, taskStatus.Progress, taskStatus.Reverting)
+	}
+	if _, found := recoveryStore[util.AppDConfigKeyPath+defaultAppInstanceId]; found {
+		t.Errorf("reverted task must not store the appd config")
+	}
+	if _, found := recoveryStore[util.AppDLCMJobsPath+defaultAppInstanceId]; found {
+		t.Errorf("job must be cleared after the recovery")
+	}
+}
@@ -23,6 +23,8 @@ import (
 	"mepserver/common/extif/backend"
 	"mepserver/common/models"
 	"mepserver/common/util"
+	"strconv"
+	"time"
 )
 
 type statusDB struct {
@@ -103,6 +105,7 @@ func (s *statusDB) setStateAndProgress(ruleType util.AppDRuleType, ruleId string
 func (s *statusDB) pushDB() error {
 	path := util.AppDLCMTaskStatusPath + s.appInstanceId + "/" + s.taskId
 
+	s.status.UpdateTime = time.Now().Unix()
 	statusBytes, err := json.Marshal(s.status)
 	if err != nil {
 		log.Errorf(nil, "can not marshal task statusDb info")
@@ -124,6 +127,36 @@ func (s *statusDB) setFailureReason(reason string) {
 	}
 }
 
+// Build the task progress response from the task status
+func BuildTaskProgress(taskId string, appInstanceId string, taskStatus *models.TaskStatus) models.TaskProgress {
+	ruleCount := len(taskStatus.TrafficRuleStatusLst) + len(taskStatus.DNSRuleStatusLst)
+
+	var state string
+	progress := 0
+	if taskStatus.Progress == ruleCount {
+		state = util.TASK_STATE_SUCCESS
+		progress = 100
+	} else if taskStatus.Progress >= 0 {
+		state = util.TASK_STATE_PROCESSING
+		progress = (taskStatus.Progress * 100) / ruleCount
+	} else if taskStatus.Progress == util.TaskProgressCancelled {
+		state = util.TASK_STATE_CANCELLED
+	} else {
+		state = util.TASK_STATE_FAILURE
+	}
+
+	return models.TaskProgress{
+		TaskId:        taskId,
+		AppInstanceId: appInstanceId,
+		ConfigResult:  state,
+		ConfigPhase:   strconv.Itoa(progress),
+		Details:       taskStatus.Details,
+		Recovery:      taskStatus.Recovery,
+		CreateTime:    taskStatus.CreateTime,
+		UpdateTime:    taskStatus.UpdateTime,
+	}
+}
+
 func CheckErrorInDB(appInstanceId string, taskId string) error {
 	path := util.AppDLCMTaskStatusPath + appInstanceId + "/" + taskId
 
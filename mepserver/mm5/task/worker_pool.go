@@ -0,0 +1,176 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package task
+
+import (
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/apache/servicecomb-service-center/pkg/log"
+
+	"mepserver/common/models"
+	"mepserver/common/util"
+)
+
+// Task waiting for a worker
+type queuedTask struct {
+	AppName       string
+	AppInstanceId string
+	TaskId        string
+	Revert        bool
+	SubmitTime    int64 // in nanoseconds
+}
+
+// Bounded pool of workers, tasks of an app instance are processed one at a time in the submission order and the
+// tasks of different app instances in parallel. Queued tasks are recovered from their task records on restart.
+type taskPool struct {
+	mutex   sync.Mutex
+	cond    *sync.Cond
+	size    int
+	busy    int
+	depth   int
+	pending map[string][]*queuedTask // queued tasks of each app instance in the submission order
+	running map[string]bool          // app instances with a task in a worker
+	ready   []string                 // app instances with a queued task and no running task
+	started uint64
+	waited  time.Duration
+	maxWait time.Duration
+}
+
+func newTaskPool(size int) *taskPool {
+	if size <= 0 {
+		size = util.DefaultWorkerPoolSize
+	}
+	pool := &taskPool{size: size, pending: make(map[string][]*queuedTask), running: make(map[string]bool)}
+	pool.cond = sync.NewCond(&pool.mutex)
+	return pool
+}
+
+func (p *taskPool) push(queued *queuedTask) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.pending[queued.AppInstanceId] = append(p.pending[queued.AppInstanceId], queued)
+	p.depth++
+	if !p.running[queued.AppInstanceId] && len(p.pending[queued.AppInstanceId]) == 1 {
+		p.ready = append(p.ready, queued.AppInstanceId)
+		p.cond.Signal()
+	}
+}
+
+// Wait for the next task of an app instance without a running task
+func (p *taskPool) next() *queuedTask {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for len(p.ready) == 0 {
+		p.cond.Wait()
+	}
+	appInstanceId := p.ready[0]
+	p.ready = p.ready[1:]
+	queued := p.pending[appInstanceId][0]
+	p.pending[appInstanceId] = p.pending[appInstanceId][1:]
+	if len(p.pending[appInstanceId]) == 0 {
+		delete(p.pending, appInstanceId)
+	}
+	p.running[appInstanceId] = true
+	p.depth--
+	p.busy++
+
+	wait := time.Since(time.Unix(0, queued.SubmitTime))
+	p.started++
+	p.waited += wait
+	if wait > p.maxWait {
+		p.maxWait = wait
+	}
+	return queued
+}
+
+// Release the app instance of a finished task, its next task is ready for a worker
+func (p *taskPool) done(appInstanceId string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	delete(p.running, appInstanceId)
+	p.busy--
+	if len(p.pending[appInstanceId]) != 0 {
+		p.ready = append(p.ready, appInstanceId)
+		p.cond.Signal()
+	}
+}
+
+func (p *taskPool) stats() models.TaskQueueStats {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	stats := models.TaskQueueStats{Workers: p.size, BusyWorkers: p.busy, QueueDepth: p.depth,
+		MaxWaitTime: p.maxWait.Milliseconds(), StartedTasks: p.started}
+	if p.started != 0 {
+		stats.AverageWaitTime = (p.waited / time.Duration(p.started)).Milliseconds()
+	}
+	for _, tasks := range p.pending {
+		if wait := time.Since(time.Unix(0, tasks[0].SubmitTime)).Milliseconds(); wait > stats.OldestWaitTime {
+			stats.OldestWaitTime = wait
+		}
+	}
+	return stats
+}
+
+// Pool is started on the first task, with the configured size
+func (w *Worker) getPool() *taskPool {
+	w.poolOnce.Do(func() {
+		w.pool = newTaskPool(w.poolSize)
+		for i := 0; i < w.pool.size; i++ {
+			go w.runPoolWorker()
+		}
+	})
+	return w.pool
+}
+
+func (w *Worker) runPoolWorker() {
+	for {
+		queued := w.getPool().next()
+		w.processQueuedTask(queued)
+	}
+}
+
+func (w *Worker) processQueuedTask(queued *queuedTask) {
+	defer w.getPool().done(queued.AppInstanceId)
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf(nil, "Task queue panic: %v \n %s", r, string(debug.Stack()))
+		}
+	}()
+	if queued.Revert {
+		w.ProcessDataPlaneRevert(queued.AppName, queued.AppInstanceId, queued.TaskId)
+	} else {
+		w.ProcessDataPlane(queued.AppName, queued.AppInstanceId, queued.TaskId)
+	}
+}
+
+// Hand over the task to the pool
+func (w *Worker) enqueueTask(queued *queuedTask) {
+	queued.SubmitTime = time.Now().UnixNano()
+	// Revert can't be cancelled
+	if !queued.Revert {
+		w.setTaskActive(queued.TaskId)
+	}
+	w.waitWorkerFinish.Add(1)
+	w.getPool().push(queued)
+}
+
+// Queue depth and wait times of the tasks for monitoring
+func (w *Worker) QueueStats() models.TaskQueueStats {
+	return w.getPool().stats()
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package task
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+
+	"mepserver/common/util"
+)
+
+func TestTaskPoolPerAppOrdering(t *testing.T) {
+	pool := newTaskPool(2)
+	submitTime := time.Now().UnixNano()
+	pool.push(&queuedTask{AppInstanceId: "app1", TaskId: "task1", SubmitTime: submitTime})
+	pool.push(&queuedTask{AppInstanceId: "app1", TaskId: "task2", SubmitTime: submitTime})
+	pool.push(&queuedTask{AppInstanceId: "app2", TaskId: "task3", SubmitTime: submitTime})
+
+	if queued := pool.next(); queued.TaskId != "task1" {
+		t.Fatalf("expected task1, got %s", queued.TaskId)
+	}
+	// Second task of app1 must wait for the first one, the task of app2 runs in parallel
+	if queued := pool.next(); queued.TaskId != "task3" {
+		t.Fatalf("expected task3, got %s", queued.TaskId)
+	}
+	if len(pool.ready) != 0 {
+		t.Fatalf("no app instance must be ready while its task is running")
+	}
+
+	stats := pool.stats()
+	if stats.Workers != 2 || stats.BusyWorkers != 2 || stats.QueueDepth != 1 || stats.StartedTasks != 2 {
+		t.Fatalf("unexpected queue stats: %+v", stats)
+	}
+
+	pool.done("app1")
+	if queued := pool.next(); queued.TaskId != "task2" {
+		t.Fatalf("expected task2, got %s", queued.TaskId)
+	}
+	pool.done("app1")
+	pool.done("app2")
+
+	stats = pool.stats()
+	if stats.BusyWorkers != 0 || stats.QueueDepth != 0 || stats.StartedTasks != 3 {
+		t.Fatalf("unexpected queue stats: %+v", stats)
+	}
+}
+
+var poolTaskMutex sync.Mutex
+var poolTaskRunning = make(map[string]int)
+var poolTaskOverlap bool
+var poolTaskOrder []string
+
+// Task processing of a while, records whether another task of the same app instance runs meanwhile
+func processPoolTask(w *Worker, appName, appInstanceId, taskId string) {
+	defer w.waitWorkerFinish.Done()
+	poolTaskMutex.Lock()
+	poolTaskRunning[appInstanceId]++
+	if poolTaskRunning[appInstanceId] > 1 {
+		poolTaskOverlap = true
+	}
+	poolTaskOrder = append(poolTaskOrder, taskId)
+	poolTaskMutex.Unlock()
+
+	time.Sleep(50 * time.Millisecond)
+
+	poolTaskMutex.Lock()
+	poolTaskRunning[appInstanceId]--
+	poolTaskMutex.Unlock()
+}
+
+func TestTaskPoolSameAppNoOverlap(t *testing.T) {
+	patches := gomonkey.ApplyMethod(reflect.TypeOf(&Worker{}), "ProcessDataPlane", processPoolTask)
+	defer patches.Reset()
+
+	worker := (&Worker{}).WithPoolSize(4)
+	worker.StartNewTask("app", "app1", "task1")
+	worker.StartNewTask("app", "app1", "task2")
+	worker.StartNewTask("app", "app2", "task3")
+	worker.waitWorkerFinish.Wait()
+
+	if poolTaskOverlap {
+		t.Fatalf("tasks of the same app instance must not run in parallel")
+	}
+	first, second := -1, -1
+	for i, taskId := range poolTaskOrder {
+		if taskId == "task1" {
+			first = i
+		} else if taskId == "task2" {
+			second = i
+		}
+	}
+	if first == -1 || second == -1 || first > second {
+		t.Fatalf("tasks of the same app instance must run in the submission order, got %v", poolTaskOrder)
+	}
+}
+
+func TestTaskPoolDefaultSize(t *testing.T) {
+	if pool := newTaskPool(0); pool.size != util.DefaultWorkerPoolSize {
+		t.Fatalf("expected default pool size %d, got %d", util.DefaultWorkerPoolSize, pool.size)
+	}
+}
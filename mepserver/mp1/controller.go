@@ -26,12 +26,14 @@ import (
 	"mepserver/common/extif/dataplane"
 	dpCommon "mepserver/common/extif/dataplane/common"
 	"mepserver/common/extif/dns"
+	"mepserver/common/extif/timing"
 	"mepserver/common/models"
 	"net/http"
 
 	"mepserver/common"
 	"mepserver/common/arch/workspace"
 	meputil "mepserver/common/util"
+	"mepserver/mp1/event"
 	"mepserver/mp1/plans"
 )
 
@@ -63,9 +65,14 @@ func initRouter() {
 
 type Mp1Service struct {
 	v4.MicroServiceService
-	config    *config.MepServerConfig
-	dnsAgent  dns.DNSAgent
-	dataPlane dataplane.DataPlane
+	config         *config.MepServerConfig
+	dnsAgent       dns.DNSAgent
+	dnsType        string
+	dataPlane      dataplane.DataPlane
+	dpCapabilities *dataplane.Capabilities
+	transports     []models.TransportInfo
+	timeSource     timing.TimeSource
+	timingCaps     models.TimingCaps
 }
 
 func (m *Mp1Service) Init() error {
@@ -74,13 +81,32 @@ func (m *Mp1Service) Init() error {
 		return fmt.Errorf("error: reading configuration failed")
 	}
 	m.config = mepConfig
+	m.transports = plans.NewTransportCatalogue(mepConfig.Transports)
+	m.timingCaps = plans.NewTimingCaps(mepConfig.Timing)
+	timeSource, err := timing.CreateTimeSource(mepConfig)
+	if err != nil {
+		log.Errorf(err, "Time source creation failed.")
+		return fmt.Errorf("error: unsupported time source")
+	}
+	// Clock status is read periodically, the timing api reports the last status read
+	if timeSource != nil {
+		statusCache := timing.NewStatusCache(timeSource, mepConfig.Timing.PollInterval)
+		go statusCache.Poll()
+		m.timeSource = statusCache
+	}
+
+	// Resume the delivery of the notifications pending from the previous run
+	go event.RecoverNotificationOutbox()
+	go event.SweepExpiredSubscriptions()
 
-	// Checking if local or both is configured
-	var dnsAgent dns.DNSAgent
-	if m.config.DNSAgent.Type != meputil.DnsAgentTypeDataPlane {
-		dnsAgent = dns.NewRestDNSAgent(mepConfig)
+	// Select the dns agent as per configuration, no agent for data-plane only type
+	dnsAgent, err := dns.CreateDNSAgent(mepConfig)
+	if err != nil {
+		log.Errorf(err, "Dns agent creation failed.")
+		return fmt.Errorf("error: unsupported dns agent")
 	}
 	m.dnsAgent = dnsAgent
+	m.dnsType = mepConfig.DNSAgent.Type
 	// select data plane as per configuration
 	dataPlane := dpCommon.CreateDataPlane(mepConfig)
 	if dataPlane == nil {
@@ -91,6 +117,12 @@ func (m *Mp1Service) Init() error {
 		return err
 	}
 	m.dataPlane = dataPlane
+
+	// Cache the data-plane capabilities, rule requests are validated against it
+	m.dpCapabilities, err = dataPlane.GetCapabilities()
+	if err != nil {
+		return fmt.Errorf("error: data-plane capability query failed: %w", err)
+	}
 	log.Infof("Data plane initialized to %s", m.config.DataPlane.Type)
 
 	return nil
@@ -140,6 +172,32 @@ func (m *Mp1Service) URLPatterns() []rest.Route {
 		{Method: rest.HTTP_METHOD_GET, Path: meputil.TrafficRulesPath, Func: m.getTrafficRules},
 		{Method: rest.HTTP_METHOD_GET, Path: meputil.TrafficRulesPath + meputil.TrafficRuleIdPath, Func: m.getTrafficRule},
 		{Method: rest.HTTP_METHOD_PUT, Path: meputil.TrafficRulesPath + meputil.TrafficRuleIdPath, Func: m.trafficRuleUpdate},
+		// MEC Application Support API - confirmTermination
+		{Method: rest.HTTP_METHOD_POST, Path: meputil.ConfirmTerminationPath, Func: m.confirmTermination},
+		// appSubscriptions - notification delivery status
+		{Method: rest.HTTP_METHOD_GET, Path: meputil.AppSubscribePath + meputil.SubscriptionIdPath +
+			meputil.DeliveryStatusPath, Func: m.getAppSubscribeDeliveryStatus},
+		// appSubscriptions - update, extends the expiry deadline
+		{Method: rest.HTTP_METHOD_PUT, Path: meputil.AppSubscribePath + meputil.SubscriptionIdPath,
+			Func: m.updateAppSubscribe},
+		{Method: rest.HTTP_METHOD_PUT, Path: meputil.EndAppSubscribePath + meputil.SubscriptionIdPath,
+			Func: m.updateEndAppOneSubscribe},
+		// appSubscriptions - notification websocket
+		{Method: rest.HTTP_METHOD_GET, Path: meputil.AppSubscribePath + meputil.SubscriptionIdPath +
+			meputil.WebsocketPath, Func: m.appSubscribeWebsocket},
+		{Method: rest.HTTP_METHOD_GET, Path: meputil.EndAppSubscribePath + meputil.SubscriptionIdPath +
+			meputil.WebsocketPath, Func: m.endAppSubscribeWebsocket},
+		// DNS and traffic rules - create and delete, allowed by the AppD config of the app
+		{Method: rest.HTTP_METHOD_POST, Path: meputil.DNSRulesPath, Func: m.dnsRuleCreate},
+		{Method: rest.HTTP_METHOD_DELETE, Path: meputil.DNSRulesPath + meputil.DNSRuleIdPath, Func: m.dnsRuleDelete},
+		{Method: rest.HTTP_METHOD_POST, Path: meputil.TrafficRulesPath, Func: m.trafficRuleCreate},
+		{Method: rest.HTTP_METHOD_DELETE, Path: meputil.TrafficRulesPath + meputil.TrafficRuleIdPath,
+			Func: m.trafficRuleDelete},
+		// transports
+		{Method: rest.HTTP_METHOD_GET, Path: meputil.TransportsPath, Func: m.getTransports},
+		// MEC Application Support API - timing
+		{Method: rest.HTTP_METHOD_GET, Path: meputil.CurrentTimePath, Func: m.getCurrentTime},
+		{Method: rest.HTTP_METHOD_GET, Path: meputil.TimingCapsPath, Func: m.getTimingCaps},
 	}
 }
 
@@ -187,6 +245,27 @@ func (m *Mp1Service) delEndAppOneSubscribe(w http.ResponseWriter, r *http.Reques
 	workspace.WkRun(workPlan)
 }
 
+func (m *Mp1Service) updateEndAppOneSubscribe(w http.ResponseWriter, r *http.Request) {
+
+	workPlan := NewWorkSpace(w, r)
+	workPlan.Try(
+		(&plans.DecodeRestReq{}).WithBody(&models.AppTerminationNotificationSubscription{}),
+		(&plans.UpdateOneSubscribe{}).WithType(meputil.AppTerminationNotificationSubscription))
+	workPlan.Finally(&common.SendHttpRsp{})
+
+	workspace.WkRun(workPlan)
+}
+
+func (m *Mp1Service) confirmTermination(w http.ResponseWriter, r *http.Request) {
+
+	workPlan := NewWorkSpace(w, r)
+	workPlan.Try((&plans.DecodeRestReq{}).WithBody(&models.AppTerminationConfirmation{}),
+		&plans.ConfirmTermination{})
+	workPlan.Finally(&common.SendHttpRsp{StatusCode: http.StatusNoContent})
+
+	workspace.WkRun(workPlan)
+}
+
 func (m *Mp1Service) doAppSubscribe(w http.ResponseWriter, r *http.Request) {
 
 	workPlan := NewWorkSpace(w, r)
@@ -204,8 +283,8 @@ func (m *Mp1Service) getAppSubscribes(w http.ResponseWriter, r *http.Request) {
 	workPlan := NewWorkSpace(w, r)
 	workPlan.Try(
 		&plans.DecodeRestReq{},
-		(&plans.GetSubscribes{}).WithType(meputil.SerAvailabilityNotificationSubscription))
-	workPlan.Finally(&common.SendHttpRsp{})
+		(&plans.GetSubscribes{}).WithType(meputil.SerAvailabilityNotificationSubscription).WithPaging())
+	workPlan.Finally((&common.SendHttpRsp{}).WithPage("href", "_links", "subscriptions"))
 
 	workspace.WkRun(workPlan)
 }
@@ -232,11 +311,56 @@ func (m *Mp1Service) delOneAppSubscribe(w http.ResponseWriter, r *http.Request)
 	workspace.WkRun(workPlan)
 }
 
+func (m *Mp1Service) updateAppSubscribe(w http.ResponseWriter, r *http.Request) {
+
+	workPlan := NewWorkSpace(w, r)
+	workPlan.Try(
+		(&plans.DecodeRestReq{}).WithBody(&models.SerAvailabilityNotificationSubscription{}),
+		(&plans.UpdateOneSubscribe{}).WithType(meputil.SerAvailabilityNotificationSubscription))
+	workPlan.Finally(&common.SendHttpRsp{})
+
+	workspace.WkRun(workPlan)
+}
+
+func (m *Mp1Service) getAppSubscribeDeliveryStatus(w http.ResponseWriter, r *http.Request) {
+
+	workPlan := NewWorkSpace(w, r)
+	workPlan.Try(
+		&plans.DecodeRestReq{},
+		&plans.GetSubscriptionDeliveryStatus{})
+	workPlan.Finally(&common.SendHttpRsp{})
+
+	workspace.WkRun(workPlan)
+}
+
+func (m *Mp1Service) appSubscribeWebsocket(w http.ResponseWriter, r *http.Request) {
+
+	workPlan := NewWorkSpace(w, r)
+	workPlan.Try(
+		&plans.DecodeRestReq{},
+		(&plans.CheckSubscriptionWebsocket{}).WithType(meputil.SerAvailabilityNotificationSubscription))
+	workPlan.Finally((&plans.ServeSubscriptionWebsocket{}).WithType(meputil.SerAvailabilityNotificationSubscription))
+
+	workspace.WkRun(workPlan)
+}
+
+func (m *Mp1Service) endAppSubscribeWebsocket(w http.ResponseWriter, r *http.Request) {
+
+	workPlan := NewWorkSpace(w, r)
+	workPlan.Try(
+		&plans.DecodeRestReq{},
+		(&plans.CheckSubscriptionWebsocket{}).WithType(meputil.AppTerminationNotificationSubscription))
+	workPlan.Finally((&plans.ServeSubscriptionWebsocket{}).WithType(meputil.AppTerminationNotificationSubscription))
+
+	workspace.WkRun(workPlan)
+}
+
 func (m *Mp1Service) serviceRegister(w http.ResponseWriter, r *http.Request) {
 
 	workPlan := NewWorkSpace(w, r)
 	workPlan.Try(
 		(&plans.DecodeRestReq{}).WithBody(&models.ServiceInfo{}),
+		(&plans.SelectTransport{}).WithTransports(m.transports),
 		&plans.RegisterLimit{},
 		&plans.RegisterServiceId{},
 		&plans.RegisterServiceInst{})
@@ -250,10 +374,10 @@ func (m *Mp1Service) serviceDiscover(w http.ResponseWriter, r *http.Request) {
 	workPlan := NewWorkSpace(w, r)
 	workPlan.Try(
 		&DiscoverDecode{},
-		&DiscoverService{},
+		(&DiscoverService{}).WithPaging(),
 		&ToStrDiscover{},
 		&RspHook{})
-	workPlan.Finally(&common.SendHttpRsp{})
+	workPlan.Finally((&common.SendHttpRsp{}).WithPage("serInstanceId"))
 
 	workspace.WkRun(workPlan)
 }
@@ -263,12 +387,40 @@ func (m *Mp1Service) serviceUpdate(w http.ResponseWriter, r *http.Request) {
 	workPlan := NewWorkSpace(w, r)
 	workPlan.Try(
 		(&plans.DecodeRestReq{}).WithBody(&models.ServiceInfo{}),
+		(&plans.SelectTransport{}).WithTransports(m.transports),
 		&plans.UpdateInstance{})
 	workPlan.Finally(&common.SendHttpRsp{})
 
 	workspace.WkRun(workPlan)
 }
 
+func (m *Mp1Service) getTransports(w http.ResponseWriter, r *http.Request) {
+	workPlan := NewWorkSpace(w, r)
+	workPlan.Try(
+		(&plans.TransportsGet{}).WithTransports(m.transports))
+	workPlan.Finally((&common.SendHttpRsp{}).WithPage("id"))
+
+	workspace.WkRun(workPlan)
+}
+
+func (m *Mp1Service) getCurrentTime(w http.ResponseWriter, r *http.Request) {
+	workPlan := NewWorkSpace(w, r)
+	workPlan.Try(
+		(&plans.CurrentTimeGet{}).WithTimeSource(m.timeSource))
+	workPlan.Finally(&common.SendHttpRsp{})
+
+	workspace.WkRun(workPlan)
+}
+
+func (m *Mp1Service) getTimingCaps(w http.ResponseWriter, r *http.Request) {
+	workPlan := NewWorkSpace(w, r)
+	workPlan.Try(
+		(&plans.TimingCapsGet{}).WithTimingCaps(m.timingCaps))
+	workPlan.Finally(&common.SendHttpRsp{})
+
+	workspace.WkRun(workPlan)
+}
+
 func (m *Mp1Service) getOneService(w http.ResponseWriter, r *http.Request) {
 
 	workPlan := NewWorkSpace(w, r)
@@ -298,7 +450,7 @@ func (m *Mp1Service) getDnsRules(w http.ResponseWriter, r *http.Request) {
 	workPlan.Try(
 		&plans.DecodeDnsRestReq{},
 		&plans.DNSRulesGet{})
-	workPlan.Finally(&common.SendHttpRsp{})
+	workPlan.Finally((&common.SendHttpRsp{}).WithPage("dnsRuleId"))
 
 	workspace.WkRun(workPlan)
 }
@@ -318,12 +470,32 @@ func (m *Mp1Service) dnsRuleUpdate(w http.ResponseWriter, r *http.Request) {
 	workPlan := NewWorkSpace(w, r)
 	workPlan.Try(
 		(&plans.DecodeDnsRestReq{}).WithBody(&dataplane.DNSRule{}),
-		(&plans.DNSRuleUpdate{}).WithDNSAgent(m.dnsAgent).WithDataPlane(m.dataPlane))
+		(&plans.DNSRuleUpdate{}).WithDNSAgent(m.dnsAgent, m.dnsType).WithDataPlane(m.dataPlane))
 	workPlan.Finally(&common.SendHttpRsp{})
 
 	workspace.WkRun(workPlan)
 }
 
+func (m *Mp1Service) dnsRuleCreate(w http.ResponseWriter, r *http.Request) {
+	workPlan := NewWorkSpace(w, r)
+	workPlan.Try(
+		(&plans.DecodeDnsRestReq{}).WithBody(&dataplane.DNSRule{}),
+		(&plans.DNSRuleCreate{}).WithDNSAgent(m.dnsAgent, m.dnsType).WithDataPlane(m.dataPlane))
+	workPlan.Finally(&common.SendHttpRsp{StatusCode: http.StatusCreated})
+
+	workspace.WkRun(workPlan)
+}
+
+func (m *Mp1Service) dnsRuleDelete(w http.ResponseWriter, r *http.Request) {
+	workPlan := NewWorkSpace(w, r)
+	workPlan.Try(
+		&plans.DecodeDnsRestReq{},
+		(&plans.DNSRuleDelete{}).WithDNSAgent(m.dnsAgent, m.dnsType).WithDataPlane(m.dataPlane))
+	workPlan.Finally(&common.SendHttpRsp{StatusCode: http.StatusNoContent})
+
+	workspace.WkRun(workPlan)
+}
+
 func (m *Mp1Service) getHeartbeat(w http.ResponseWriter, r *http.Request) {
 	workPlan := NewWorkSpace(w, r)
 	workPlan.Try(
@@ -350,7 +522,7 @@ func (m *Mp1Service) getTrafficRules(w http.ResponseWriter, r *http.Request) {
 	workPlan.Try(
 		&plans.DecodeTrafficRestReq{},
 		&plans.TrafficRulesGet{})
-	workPlan.Finally(&common.SendHttpRsp{})
+	workPlan.Finally((&common.SendHttpRsp{}).WithPage("trafficRuleId"))
 
 	workspace.WkRun(workPlan)
 }
@@ -370,8 +542,28 @@ func (m *Mp1Service) trafficRuleUpdate(w http.ResponseWriter, r *http.Request) {
 	workPlan := NewWorkSpace(w, r)
 	workPlan.Try(
 		(&plans.DecodeTrafficRestReq{}).WithBody(&dataplane.TrafficRule{}),
-		(&plans.TrafficRuleUpdate{}).WithDataPlane(m.dataPlane))
+		(&plans.TrafficRuleUpdate{}).WithDataPlane(m.dataPlane).WithCapabilities(m.dpCapabilities))
 	workPlan.Finally(&common.SendHttpRsp{})
 
 	workspace.WkRun(workPlan)
 }
+
+func (m *Mp1Service) trafficRuleCreate(w http.ResponseWriter, r *http.Request) {
+	workPlan := NewWorkSpace(w, r)
+	workPlan.Try(
+		(&plans.DecodeTrafficRestReq{}).WithBody(&dataplane.TrafficRule{}),
+		(&plans.TrafficRuleCreate{}).WithDataPlane(m.dataPlane).WithCapabilities(m.dpCapabilities))
+	workPlan.Finally(&common.SendHttpRsp{StatusCode: http.StatusCreated})
+
+	workspace.WkRun(workPlan)
+}
+
+func (m *Mp1Service) trafficRuleDelete(w http.ResponseWriter, r *http.Request) {
+	workPlan := NewWorkSpace(w, r)
+	workPlan.Try(
+		&plans.DecodeTrafficRestReq{},
+		(&plans.TrafficRuleDelete{}).WithDataPlane(m.dataPlane))
+	workPlan.Finally(&common.SendHttpRsp{StatusCode: http.StatusNoContent})
+
+	workspace.WkRun(workPlan)
+}
@@ -19,6 +19,7 @@ package mp1
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -26,11 +27,13 @@ import (
 	"math/rand"
 	"mepserver/common/config"
 	"mepserver/common/extif/dataplane"
+	"mepserver/common/extif/dataplane/none"
 	"mepserver/common/models"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -40,6 +43,9 @@ import (
 	_ "github.com/apache/servicecomb-service-center/server/bootstrap"
 	scerr "github.com/apache/servicecomb-service-center/server/error"
 	srv "github.com/apache/servicecomb-service-center/server/service"
+	scbackend "github.com/apache/servicecomb-service-center/server/core/backend"
+	"github.com/apache/servicecomb-service-center/server/plugin/pkg/registry"
+	"github.com/coreos/etcd/mvcc/mvccpb"
 	svcutil "github.com/apache/servicecomb-service-center/server/service/util"
 	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
@@ -49,6 +55,7 @@ import (
 	pb "github.com/apache/servicecomb-service-center/server/core/proto"
 	"mepserver/common/extif/backend"
 	"mepserver/common/extif/dns"
+	"mepserver/mp1/plans"
 	"mepserver/common/util"
 )
 
@@ -380,6 +387,51 @@ func TestGetDnsRules(t *testing.T) {
 
 }
 
+// Query a page of the dns rules with the selected attributes in mp1 interface
+func TestGetDnsRulesPaged(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mp1Service{}
+
+	// Create http get request
+	getRequest, _ := http.NewRequest("GET",
+		fmt.Sprintf(getDnsRulesUrlFormat, defaultAppInstanceId),
+		bytes.NewReader([]byte("")))
+	getRequest.URL.RawQuery = fmt.Sprintf(":appInstanceId=%s&limit=1&fields=dnsRuleId", defaultAppInstanceId)
+	getRequest.Header.Set(appInstanceIdHeader, defaultAppInstanceId)
+
+	// Mock the response writer
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 200)
+
+	patches := gomonkey.ApplyFunc(backend.GetRecord, func(path string) ([]byte, int) {
+		entry := models.AppDConfig{AppDNSRule: []dataplane.DNSRule{
+			{DNSRuleID: "dnsrule2", DomainName: exampleDomainName, IPAddressType: "IP_V4", IPAddress: exampleIPAddress,
+				TTL: defaultTTL, State: util.InactiveState},
+			{DNSRuleID: "dnsrule1", DomainName: exampleDomainName, IPAddressType: "IP_V4", IPAddress: exampleIPAddress,
+				TTL: defaultTTL, State: util.InactiveState},
+		}}
+		outBytes, _ := json.Marshal(&entry)
+		return outBytes, 0
+	})
+	defer patches.Reset()
+
+	// 13 is the order of the DNS get all handler in the URLPattern
+	service.URLPatterns()[13].Func(mockWriter, getRequest)
+
+	mockWriter.AssertExpectations(t)
+	assert.Equal(t, "[{\"dnsRuleId\":\"dnsrule1\"}]\n", string(mockWriter.response))
+	assert.Equal(t, fmt.Sprintf("<%s?fields=dnsRuleId&limit=1&next_page=ZG5zcnVsZTE>; rel=\"next\"",
+		fmt.Sprintf(getDnsRulesUrlFormat, defaultAppInstanceId)), responseHeader.Get("Link"))
+}
+
 // Query an empty dns rules request in mp1 interface
 func TestGetEmptyDnsRules(t *testing.T) {
 	defer func() {
@@ -1232,6 +1284,356 @@ func TestPutSingleDnsRuleOverLengthBody(t *testing.T) {
 }
 
 //============================APP SERVICE AVAILABILITY SUBSCRIPTION=========================================
+var ruleMgmtAppDConfig []byte
+var ruleMgmtVersionRecorded bool
+var ruleMgmtPutRevision int64
+var ruleMgmtPutAbsentPath string
+var ruleMgmtJobStarted bool
+
+const ruleMgmtRevision int64 = 7
+
+func getRuleMgmtAppDConfig(path string) ([]byte, int64, int) {
+	return ruleMgmtAppDConfigRecord(true), ruleMgmtRevision, 0
+}
+
+func putRuleMgmtAppDConfig(path string, value []byte, revision int64, absentPath string) int {
+	ruleMgmtPutRevision = revision
+	ruleMgmtPutAbsentPath = absentPath
+	ruleMgmtAppDConfig = value
+	return 0
+}
+
+func putRuleMgmtAppDConfigModified(path string, value []byte, revision int64, absentPath string) int {
+	return util.RecordModifiedErr
+}
+
+// Mm5 job is created after the rule management checked the jobs
+func putRuleMgmtAppDConfigJobStarted(path string, value []byte, revision int64, absentPath string) int {
+	ruleMgmtJobStarted = true
+	return util.RecordModifiedErr
+}
+
+func getRuleMgmtJobs(path string) (map[string][]byte, int) {
+	if ruleMgmtJobStarted && path == util.AppDLCMJobsPath+defaultAppInstanceId {
+		return map[string][]byte{defaultAppInstanceId: ruleMgmtAppDConfigRecord(true)}, 0
+	}
+	return map[string][]byte{}, 0
+}
+
+var ruleMgmtDNSRuleAdded string
+
+func addRuleMgmtDNSRule(_ *none.NoneDataPlane, appInfo dataplane.ApplicationInfo, dnsRuleId, domainName,
+	ipAddressType, ipAddress string, ttl uint32) error {
+	ruleMgmtDNSRuleAdded = dnsRuleId
+	return nil
+}
+
+func ruleMgmtAppDConfigRecord(mp1RuleMgmt bool) []byte {
+	appDConfig := models.AppDConfig{
+		AppName:     "ruleMgmtApp",
+		Mp1RuleMgmt: mp1RuleMgmt,
+		AppTrafficRule: []dataplane.TrafficRule{{TrafficRuleID: trafficRuleId, FilterType: "FLOW", Priority: 5,
+			TrafficFilter: []dataplane.TrafficFilter{}, Action: "DROP", State: util.InactiveState}},
+	}
+	appDConfigBytes, _ := json.Marshal(&appDConfig)
+	return appDConfigBytes
+}
+
+// Create a dns rule over mp1 when the AppD config allows the rule management
+func TestPostDnsRule(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mp1Service{}
+	createRule := dataplane.DNSRule{
+		DNSRuleID:     dnsRuleId,
+		DomainName:    exampleDomainName,
+		IPAddressType: util.IPv4Type,
+		IPAddress:     exampleIPAddress,
+		TTL:           defaultTTL,
+		State:         util.InactiveState,
+	}
+	createRuleBytes, _ := json.Marshal(createRule)
+	postRequest, _ := http.NewRequest("POST", fmt.Sprintf(getDnsRulesUrlFormat, defaultAppInstanceId),
+		bytes.NewReader(createRuleBytes))
+	postRequest.URL.RawQuery = fmt.Sprintf(":appInstanceId=%s", defaultAppInstanceId)
+	postRequest.Header.Set(appInstanceIdHeader, defaultAppInstanceId)
+
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 201)
+
+	patches := gomonkey.ApplyFunc(backend.GetRecordWithRevision, getRuleMgmtAppDConfig)
+	defer patches.Reset()
+	patches.ApplyFunc(backend.GetRecords, func(path string) (map[string][]byte, int) {
+		return map[string][]byte{}, 0
+	})
+	ruleMgmtVersionRecorded = false
+	patches.ApplyFunc(backend.GetRecordsWithCompleteKeyPath, func(path string) (map[string][]byte, int) {
+		return map[string][]byte{}, 0
+	})
+	patches.ApplyFunc(backend.PutRecord, func(path string, value []byte) int {
+		if strings.HasPrefix(path, util.AppDConfigHistoryPath) {
+			ruleMgmtVersionRecorded = true
+		}
+		return 0
+	})
+	ruleMgmtPutRevision = 0
+	patches.ApplyFunc(backend.PutRecordIfRevisionAndAbsent, putRuleMgmtAppDConfig)
+
+	// 30 is the order of the DNS post handler in the URLPattern
+	service.URLPatterns()[30].Func(mockWriter, postRequest)
+
+	assert.Equal(t, "201", responseHeader.Get(responseStatusHeader), responseCheckFor201)
+	assert.Equal(t, fmt.Sprintf("/mec_app_support/v1/applications/%s/dns_rules/%s", defaultAppInstanceId,
+		dnsRuleId), responseHeader.Get("Location"))
+	appDConfig := models.AppDConfig{}
+	_ = json.Unmarshal(ruleMgmtAppDConfig, &appDConfig)
+	assert.Equal(t, []dataplane.DNSRule{createRule}, appDConfig.AppDNSRule, "Dns rule not stored")
+	assert.Equal(t, 1, len(appDConfig.AppTrafficRule), "Traffic rules modified")
+	assert.True(t, ruleMgmtVersionRecorded, "AppD config version not recorded")
+	assert.Equal(t, ruleMgmtRevision, ruleMgmtPutRevision, "AppD config not written on the revision read")
+	assert.Equal(t, util.AppDLCMJobsPath+defaultAppInstanceId, ruleMgmtPutAbsentPath,
+		"AppD config must be written only without a job")
+	mockWriter.AssertExpectations(t)
+}
+
+// Creating a dns rule over mp1 fails when another request modified the AppD config meanwhile
+func TestPostDnsRuleModifiedConcurrently(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mp1Service{}
+	createRule := dataplane.DNSRule{
+		DNSRuleID:     dnsRuleId,
+		DomainName:    exampleDomainName,
+		IPAddressType: util.IPv4Type,
+		IPAddress:     exampleIPAddress,
+		TTL:           defaultTTL,
+		State:         util.InactiveState,
+	}
+	createRuleBytes, _ := json.Marshal(createRule)
+	postRequest, _ := http.NewRequest("POST", fmt.Sprintf(getDnsRulesUrlFormat, defaultAppInstanceId),
+		bytes.NewReader(createRuleBytes))
+	postRequest.URL.RawQuery = fmt.Sprintf(":appInstanceId=%s", defaultAppInstanceId)
+	postRequest.Header.Set(appInstanceIdHeader, defaultAppInstanceId)
+
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 409)
+
+	patches := gomonkey.ApplyFunc(backend.GetRecordWithRevision, getRuleMgmtAppDConfig)
+	defer patches.Reset()
+	patches.ApplyFunc(backend.GetRecords, func(path string) (map[string][]byte, int) {
+		return map[string][]byte{}, 0
+	})
+	patches.ApplyFunc(backend.PutRecordIfRevisionAndAbsent, putRuleMgmtAppDConfigModified)
+
+	// 30 is the order of the DNS post handler in the URLPattern
+	service.URLPatterns()[30].Func(mockWriter, postRequest)
+
+	assert.Equal(t, "409", responseHeader.Get(responseStatusHeader), "Response status code must be 409")
+	mockWriter.AssertExpectations(t)
+}
+
+// Creating a dns rule over mp1 is not allowed when a mm5 operation started for the app meanwhile
+func TestPostDnsRuleJobStartedConcurrently(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mp1Service{}
+	createRule := dataplane.DNSRule{
+		DNSRuleID:     dnsRuleId,
+		DomainName:    exampleDomainName,
+		IPAddressType: util.IPv4Type,
+		IPAddress:     exampleIPAddress,
+		TTL:           defaultTTL,
+		State:         util.InactiveState,
+	}
+	createRuleBytes, _ := json.Marshal(createRule)
+	postRequest, _ := http.NewRequest("POST", fmt.Sprintf(getDnsRulesUrlFormat, defaultAppInstanceId),
+		bytes.NewReader(createRuleBytes))
+	postRequest.URL.RawQuery = fmt.Sprintf(":appInstanceId=%s", defaultAppInstanceId)
+	postRequest.Header.Set(appInstanceIdHeader, defaultAppInstanceId)
+
+	mockWriter := &mockHttpWriter{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write", []byte("{\"title\":\"Operation Not Allowed\",\"status\":20,"+
+		"\"detail\":\"app instance has other operation in progress\"}\n")).Return(0, nil)
+	mockWriter.On("WriteHeader", 403)
+
+	patches := gomonkey.ApplyFunc(backend.GetRecordWithRevision, getRuleMgmtAppDConfig)
+	defer patches.Reset()
+	ruleMgmtJobStarted = false
+	patches.ApplyFunc(backend.GetRecords, getRuleMgmtJobs)
+	patches.ApplyFunc(backend.PutRecordIfRevisionAndAbsent, putRuleMgmtAppDConfigJobStarted)
+
+	// 30 is the order of the DNS post handler in the URLPattern
+	service.URLPatterns()[30].Func(mockWriter, postRequest)
+
+	assert.Equal(t, "403", responseHeader.Get(responseStatusHeader), "Response status code must be 403")
+	mockWriter.AssertExpectations(t)
+}
+
+// Create an active dns rule over mp1 with the data-plane dns agent type, the rule is only on the data-plane
+func TestPostDnsRuleDataPlaneAgentType(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mp1Service{dnsType: util.DnsAgentTypeDataPlane, dataPlane: &none.NoneDataPlane{}}
+	createRule := dataplane.DNSRule{
+		DNSRuleID:     dnsRuleId,
+		DomainName:    exampleDomainName,
+		IPAddressType: util.IPv4Type,
+		IPAddress:     exampleIPAddress,
+		TTL:           defaultTTL,
+		State:         util.ActiveState,
+	}
+	createRuleBytes, _ := json.Marshal(createRule)
+	postRequest, _ := http.NewRequest("POST", fmt.Sprintf(getDnsRulesUrlFormat, defaultAppInstanceId),
+		bytes.NewReader(createRuleBytes))
+	postRequest.URL.RawQuery = fmt.Sprintf(":appInstanceId=%s", defaultAppInstanceId)
+	postRequest.Header.Set(appInstanceIdHeader, defaultAppInstanceId)
+
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 201)
+
+	patches := gomonkey.ApplyFunc(backend.GetRecordWithRevision, getRuleMgmtAppDConfig)
+	defer patches.Reset()
+	patches.ApplyFunc(backend.GetRecords, func(path string) (map[string][]byte, int) {
+		return map[string][]byte{}, 0
+	})
+	patches.ApplyFunc(backend.GetRecordsWithCompleteKeyPath, func(path string) (map[string][]byte, int) {
+		return map[string][]byte{}, 0
+	})
+	patches.ApplyFunc(backend.PutRecord, func(path string, value []byte) int {
+		return 0
+	})
+	patches.ApplyFunc(backend.PutRecordIfRevisionAndAbsent, putRuleMgmtAppDConfig)
+	ruleMgmtDNSRuleAdded = ""
+	patches.ApplyMethod(reflect.TypeOf(&none.NoneDataPlane{}), "AddDNSRule", addRuleMgmtDNSRule)
+
+	// 30 is the order of the DNS post handler in the URLPattern
+	service.URLPatterns()[30].Func(mockWriter, postRequest)
+
+	assert.Equal(t, "201", responseHeader.Get(responseStatusHeader), responseCheckFor201)
+	assert.Equal(t, dnsRuleId, ruleMgmtDNSRuleAdded, "Dns rule not added on the data-plane")
+	mockWriter.AssertExpectations(t)
+}
+
+// Creating a dns rule over mp1 is forbidden unless the AppD config allows the rule management
+func TestPostDnsRuleNotAllowed(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mp1Service{}
+	createRule := dataplane.DNSRule{
+		DNSRuleID:     dnsRuleId,
+		DomainName:    exampleDomainName,
+		IPAddressType: util.IPv4Type,
+		IPAddress:     exampleIPAddress,
+		TTL:           defaultTTL,
+	}
+	createRuleBytes, _ := json.Marshal(createRule)
+	postRequest, _ := http.NewRequest("POST", fmt.Sprintf(getDnsRulesUrlFormat, defaultAppInstanceId),
+		bytes.NewReader(createRuleBytes))
+	postRequest.URL.RawQuery = fmt.Sprintf(":appInstanceId=%s", defaultAppInstanceId)
+	postRequest.Header.Set(appInstanceIdHeader, defaultAppInstanceId)
+
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 403)
+
+	patches := gomonkey.ApplyFunc(backend.GetRecordWithRevision, func(path string) ([]byte, int64, int) {
+		return ruleMgmtAppDConfigRecord(false), ruleMgmtRevision, 0
+	})
+	defer patches.Reset()
+
+	// 30 is the order of the DNS post handler in the URLPattern
+	service.URLPatterns()[30].Func(mockWriter, postRequest)
+
+	assert.Equal(t, "403", responseHeader.Get(responseStatusHeader), "Response status code must be 403")
+	mockWriter.AssertExpectations(t)
+}
+
+// Delete a traffic rule over mp1 when the AppD config allows the rule management
+func TestDeleteTrafficRule(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mp1Service{}
+	delRequest, _ := http.NewRequest("DELETE", fmt.Sprintf(getOneTrafficRuleUrl, defaultAppInstanceId,
+		trafficRuleId), nil)
+	delRequest.URL.RawQuery = fmt.Sprintf(":appInstanceId=%s&:trafficRuleId=%s", defaultAppInstanceId,
+		trafficRuleId)
+	delRequest.Header.Set(appInstanceIdHeader, defaultAppInstanceId)
+
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 204)
+
+	patches := gomonkey.ApplyFunc(backend.GetRecordWithRevision, getRuleMgmtAppDConfig)
+	defer patches.Reset()
+	patches.ApplyFunc(backend.GetRecords, func(path string) (map[string][]byte, int) {
+		return map[string][]byte{}, 0
+	})
+	ruleMgmtVersionRecorded = false
+	patches.ApplyFunc(backend.GetRecordsWithCompleteKeyPath, func(path string) (map[string][]byte, int) {
+		return map[string][]byte{}, 0
+	})
+	patches.ApplyFunc(backend.PutRecord, func(path string, value []byte) int {
+		if strings.HasPrefix(path, util.AppDConfigHistoryPath) {
+			ruleMgmtVersionRecorded = true
+		}
+		return 0
+	})
+	ruleMgmtPutRevision = 0
+	patches.ApplyFunc(backend.PutRecordIfRevisionAndAbsent, putRuleMgmtAppDConfig)
+
+	// 33 is the order of the traffic rule delete handler in the URLPattern
+	service.URLPatterns()[33].Func(mockWriter, delRequest)
+
+	assert.Equal(t, "204", responseHeader.Get(responseStatusHeader), responseCheckFor204)
+	appDConfig := models.AppDConfig{}
+	_ = json.Unmarshal(ruleMgmtAppDConfig, &appDConfig)
+	assert.Equal(t, 0, len(appDConfig.AppTrafficRule), "Traffic rule not removed")
+	assert.True(t, ruleMgmtVersionRecorded, "AppD config version not recorded")
+	assert.Equal(t, ruleMgmtRevision, ruleMgmtPutRevision, "AppD config not written on the revision read")
+	assert.Equal(t, util.AppDLCMJobsPath+defaultAppInstanceId, ruleMgmtPutAbsentPath,
+		"AppD config must be written only without a job")
+	mockWriter.AssertExpectations(t)
+}
+
 // Post App service availability Notification
 func TestAppSubscribePost(t *testing.T) {
 	defer func() {
@@ -1248,17 +1650,6 @@ func TestAppSubscribePost(t *testing.T) {
 			SerInstanceIds: []string{
 				"f7e898d1c9ea9edd8a41295fc55c2373",
 			},
-			SerNames: []string{
-				"FaceRegService5",
-			},
-			SerCategories: []models.CategoryRef{
-				{
-					Href:    callBackRef,
-					ID:      "id12345",
-					Name:    "RNI",
-					Version: "1.2.2",
-				},
-			},
 			States: []string{
 				"ACTIVE",
 			},
@@ -1298,8 +1689,8 @@ func TestAppSubscribePost(t *testing.T) {
 	mockWriter.AssertExpectations(t)
 }
 
-// Post App service availability Notification With invalid json body
-func TestAppSubscribePostWrongJsonBody(t *testing.T) {
+// Post App service availability subscription selecting the services by more than one of the alternatives
+func TestAppSubscribePostMultipleSelections(t *testing.T) {
 	defer func() {
 		if r := recover(); r != nil {
 			t.Errorf(panicFormatString, r)
@@ -1307,17 +1698,19 @@ func TestAppSubscribePostWrongJsonBody(t *testing.T) {
 	}()
 
 	service := Mp1Service{}
-
-	var resp = &pb.GetOneInstanceResponse{}
-	n := &srv.InstanceService{}
-	patch1 := gomonkey.ApplyMethod(reflect.TypeOf(n), "GetOneInstance", func(*srv.InstanceService, context.Context, *pb.GetOneInstanceRequest) (*pb.GetOneInstanceResponse, error) {
-		return resp, nil
-	})
-	defer patch1.Reset()
-	// Create http get request
+	createSubscription := models.SerAvailabilityNotificationSubscription{
+		SubscriptionType:  "SerAvailabilityNotificationSubscription",
+		CallbackReference: callBackRef,
+		FilteringCriteria: models.FilteringCriteria{
+			SerNames:      []string{"FaceRegService5"},
+			SerCategories: []models.CategoryRef{{Href: callBackRef, ID: "id12345", Name: "RNI", Version: "1.2.2"}},
+		},
+	}
+	createSubscriptionBytes, _ := json.Marshal(createSubscription)
+	// Create http post request
 	postRequest, _ := http.NewRequest("POST",
 		fmt.Sprintf(postSubscribeUrl, defaultAppInstanceId),
-		bytes.NewReader([]byte("Hello")))
+		bytes.NewReader(createSubscriptionBytes))
 	postRequest.URL.RawQuery = fmt.Sprintf(appInstanceQueryFormat, defaultAppInstanceId)
 	postRequest.Header.Set(appInstanceIdHeader, defaultAppInstanceId)
 
@@ -1330,16 +1723,15 @@ func TestAppSubscribePostWrongJsonBody(t *testing.T) {
 
 	service.URLPatterns()[0].Func(mockWriter, postRequest)
 
-	assert.Equal(t, "400", responseHeader.Get(responseStatusHeader),
-		responseCheckFor400)
-	notification := models.SerAvailabilityNotificationSubscription{}
-	_ = json.Unmarshal(mockWriter.response, &notification)
-	assert.NotEqual(t, subtype1, notification.SubscriptionType, errorSubtypeMissMatch)
+	assert.Equal(t, "400", responseHeader.Get(responseStatusHeader), responseCheckFor400)
+	respError := models.ProblemDetails{}
+	_ = json.Unmarshal(mockWriter.response, &respError)
+	assert.Equal(t, "serInstanceIds, serNames and serCategories are mutually exclusive", respError.Detail)
 	mockWriter.AssertExpectations(t)
 }
 
-// Post App service availability Notification and json marshalling failed
-func TestAppSubscribePostJsonMarshallFail(t *testing.T) {
+// Post App service availability subscription requesting the websocket delivery
+func TestAppSubscribePostWebsocket(t *testing.T) {
 	defer func() {
 		if r := recover(); r != nil {
 			t.Errorf(panicFormatString, r)
@@ -1348,26 +1740,94 @@ func TestAppSubscribePostJsonMarshallFail(t *testing.T) {
 
 	service := Mp1Service{}
 	createSubscription := models.SerAvailabilityNotificationSubscription{
-		SubscriptionType:  "SerAvailabilityNotificationSubscription",
-		CallbackReference: callBackRef,
-		FilteringCriteria: models.FilteringCriteria{
-			SerInstanceIds: []string{
-				"f7e898d1c9ea9edd8a41295fc55c2373",
-			},
-			SerNames: []string{
-				"FaceRegService5",
-			},
-			SerCategories: []models.CategoryRef{
-				{
-					Href:    callBackRef,
-					ID:      "id12345",
-					Name:    "RNI",
-					Version: "1.2.2",
-				},
-			},
-			States: []string{
-				"ACTIVE",
-			},
+		SubscriptionType:   "SerAvailabilityNotificationSubscription",
+		WebsockNotifConfig: &models.WebsockNotifConfig{RequestWebsocketUri: true},
+	}
+	createSubscriptionBytes, _ := json.Marshal(createSubscription)
+	// Create http post request
+	postRequest, _ := http.NewRequest("POST",
+		fmt.Sprintf(postSubscribeUrl, defaultAppInstanceId),
+		bytes.NewReader(createSubscriptionBytes))
+	postRequest.URL.RawQuery = fmt.Sprintf(appInstanceQueryFormat, defaultAppInstanceId)
+	postRequest.Header.Set(appInstanceIdHeader, defaultAppInstanceId)
+	postRequest.Host = "mep.example.com"
+
+	// Mock the response writer
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 201)
+
+	service.URLPatterns()[0].Func(mockWriter, postRequest)
+
+	mockWriter.AssertExpectations(t)
+	subscription := models.SerAvailabilityNotificationSubscription{}
+	_ = json.Unmarshal(mockWriter.response, &subscription)
+	assert.NotNil(t, subscription.WebsockNotifConfig)
+	assert.Equal(t, "ws://mep.example.com"+fmt.Sprintf(postSubscribeUrl, defaultAppInstanceId)+"/"+
+		subscription.SubscriptionId+"/websocket", subscription.NotificationUri())
+}
+
+// Post App service availability Notification With invalid json body
+func TestAppSubscribePostWrongJsonBody(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mp1Service{}
+
+	var resp = &pb.GetOneInstanceResponse{}
+	n := &srv.InstanceService{}
+	patch1 := gomonkey.ApplyMethod(reflect.TypeOf(n), "GetOneInstance", func(*srv.InstanceService, context.Context, *pb.GetOneInstanceRequest) (*pb.GetOneInstanceResponse, error) {
+		return resp, nil
+	})
+	defer patch1.Reset()
+	// Create http get request
+	postRequest, _ := http.NewRequest("POST",
+		fmt.Sprintf(postSubscribeUrl, defaultAppInstanceId),
+		bytes.NewReader([]byte("Hello")))
+	postRequest.URL.RawQuery = fmt.Sprintf(appInstanceQueryFormat, defaultAppInstanceId)
+	postRequest.Header.Set(appInstanceIdHeader, defaultAppInstanceId)
+
+	// Mock the response writer
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 400)
+
+	service.URLPatterns()[0].Func(mockWriter, postRequest)
+
+	assert.Equal(t, "400", responseHeader.Get(responseStatusHeader),
+		responseCheckFor400)
+	notification := models.SerAvailabilityNotificationSubscription{}
+	_ = json.Unmarshal(mockWriter.response, &notification)
+	assert.NotEqual(t, subtype1, notification.SubscriptionType, errorSubtypeMissMatch)
+	mockWriter.AssertExpectations(t)
+}
+
+// Post App service availability Notification and json marshalling failed
+func TestAppSubscribePostJsonMarshallFail(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mp1Service{}
+	createSubscription := models.SerAvailabilityNotificationSubscription{
+		SubscriptionType:  "SerAvailabilityNotificationSubscription",
+		CallbackReference: callBackRef,
+		FilteringCriteria: models.FilteringCriteria{
+			SerInstanceIds: []string{
+				"f7e898d1c9ea9edd8a41295fc55c2373",
+			},
+			States: []string{
+				"ACTIVE",
+			},
 			IsLocal: true,
 		},
 	}
@@ -1440,6 +1900,69 @@ func TestAppSubscribeGetAll(t *testing.T) {
 	service.URLPatterns()[1].Func(mockWriterGet, getRequest)
 }
 
+// Registry serving the subscription page reads, the range read is recorded
+type subscriptionPageRegistry struct {
+	registry.Registry
+}
+
+var subscriptionPageOp registry.PluginOp
+
+func (r *subscriptionPageRegistry) Do(ctx context.Context,
+	opts ...registry.PluginOpOption) (*registry.PluginResponse, error) {
+	subscriptionPageOp = registry.OptionsToOp(opts...)
+	resp := &registry.PluginResponse{}
+	for _, subId := range []string{"sub1", "sub2", "sub3"} {
+		resp.Kvs = append(resp.Kvs, &mvccpb.KeyValue{
+			Key: []byte(util.AvailAppSubKeyPath + defaultAppInstanceId + "/" + subId)})
+	}
+	return resp, nil
+}
+
+func getSubscriptionPageRegistry() registry.Registry {
+	return &subscriptionPageRegistry{}
+}
+
+// Query a page of the app service availability Notification subscriptions
+func TestAppSubscribeGetPage(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mp1Service{}
+	subscriptionsUrl := fmt.Sprintf("/mep/mec_service_mgmt/v1/applications/%s/subscriptions", defaultAppInstanceId)
+	getRequest, _ := http.NewRequest("GET", subscriptionsUrl, nil)
+	getRequest.URL.RawQuery = fmt.Sprintf(":appInstanceId=%s&limit=2&next_page=%s", defaultAppInstanceId,
+		base64.RawURLEncoding.EncodeToString([]byte(subscriptionsUrl[len(util.RootPath):]+"/sub0")))
+	getRequest.Header.Set(appInstanceIdHeader, defaultAppInstanceId)
+
+	subscriptionPageOp = registry.PluginOp{}
+	patches := gomonkey.ApplyFunc(scbackend.Registry, getSubscriptionPageRegistry)
+	defer patches.Reset()
+
+	// Mock the response writer
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 200)
+
+	service.URLPatterns()[1].Func(mockWriter, getRequest)
+
+	mockWriter.AssertExpectations(t)
+	keyPath := util.AvailAppSubKeyPath + defaultAppInstanceId + "/"
+	assert.Equal(t, keyPath+"sub0\x00", string(subscriptionPageOp.Key))
+	assert.Equal(t, util.PrefixRangeEnd(keyPath), string(subscriptionPageOp.EndKey))
+	assert.Equal(t, int64(3), subscriptionPageOp.Limit, "one more subscription than the limit is read")
+	var subsResp models.MecServiceMgmtApiSubscriptionLinkList
+	_ = json.Unmarshal(mockWriter.response, &subsResp)
+	assert.Equal(t, 2, len(subsResp.Links.Subscriptions))
+	assert.Equal(t, subscriptionsUrl[len(util.RootPath):]+"/sub2", subsResp.Links.Subscriptions[1].Href)
+	assert.True(t, strings.Contains(responseHeader.Get("Link"), "next_page="+
+		base64.RawURLEncoding.EncodeToString([]byte(subscriptionsUrl[len(util.RootPath):]+"/sub2"))))
+}
+
 // Query One app service availability Notification subscriptions
 func TestGetOneAppSubscribe(t *testing.T) {
 	defer func() {
@@ -1618,6 +2141,271 @@ func TestDelOneAppTerminationSubscribe(t *testing.T) {
 	service.URLPatterns()[12].Func(mockWriterGet, getRequest)
 }
 
+const confirmTerminationUrl = "/mep/mec_app_support/v1/applications/%s/confirm_termination"
+const confirmTerminationBody = "{\"operationAction\":\"TERMINATING\"}"
+
+var confirmedTerminationStatus []byte
+
+// Confirm an in-progress termination of the app
+func TestConfirmTermination(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mp1Service{}
+	request, _ := http.NewRequest("POST", fmt.Sprintf(confirmTerminationUrl, defaultAppInstanceId),
+		bytes.NewReader([]byte(confirmTerminationBody)))
+	request.URL.RawQuery = fmt.Sprintf(appInstanceQueryFormat, defaultAppInstanceId)
+	request.Header.Set(appInstanceIdHeader, defaultAppInstanceId)
+
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 204)
+
+	patches := gomonkey.ApplyFunc(backend.GetRecordWithRevision, func(path string) ([]byte, int64, int) {
+		status := models.AppTerminationStatus{OperationAction: util.OperationActionTerminating}
+		outBytes, _ := json.Marshal(&status)
+		return outBytes, 10, 0
+	})
+	defer patches.Reset()
+	patches.ApplyFunc(backend.PutRecordIfRevision, func(path string, value []byte, revision int64) int {
+		if revision != 10 {
+			return util.RecordModifiedErr
+		}
+		confirmedTerminationStatus = value
+		return 0
+	})
+
+	// 24 is the order of the confirm termination handler in the URLPattern
+	service.URLPatterns()[24].Func(mockWriter, request)
+
+	assert.Equal(t, "204", responseHeader.Get(responseStatusHeader), responseCheckFor204)
+	status := models.AppTerminationStatus{}
+	_ = json.Unmarshal(confirmedTerminationStatus, &status)
+	assert.True(t, status.Confirmed, "Termination must be confirmed")
+	mockWriter.AssertExpectations(t)
+}
+
+// Confirm the termination when the app is not being terminated
+func TestConfirmTerminationNotInProgress(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mp1Service{}
+	request, _ := http.NewRequest("POST", fmt.Sprintf(confirmTerminationUrl, defaultAppInstanceId),
+		bytes.NewReader([]byte(confirmTerminationBody)))
+	request.URL.RawQuery = fmt.Sprintf(appInstanceQueryFormat, defaultAppInstanceId)
+	request.Header.Set(appInstanceIdHeader, defaultAppInstanceId)
+
+	mockWriter := &mockHttpWriter{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write", []byte("{\"title\":\"Termination not in progress\",\"status\":22,"+
+		"\"detail\":\"termination not in progress\"}\n")).Return(0, nil)
+	mockWriter.On("WriteHeader", 409)
+
+	patches := gomonkey.ApplyFunc(backend.GetRecordWithRevision, func(path string) ([]byte, int64, int) {
+		return nil, 0, util.SubscriptionNotFound
+	})
+	defer patches.Reset()
+
+	// 24 is the order of the confirm termination handler in the URLPattern
+	service.URLPatterns()[24].Func(mockWriter, request)
+
+	assert.Equal(t, "409", responseHeader.Get(responseStatusHeader), "Response status code must be 409")
+	mockWriter.AssertExpectations(t)
+}
+
+// Confirm the termination while the termination finishes and removes the status
+func TestConfirmTerminationFinishedMeanwhile(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mp1Service{}
+	request, _ := http.NewRequest("POST", fmt.Sprintf(confirmTerminationUrl, defaultAppInstanceId),
+		bytes.NewReader([]byte(confirmTerminationBody)))
+	request.URL.RawQuery = fmt.Sprintf(appInstanceQueryFormat, defaultAppInstanceId)
+	request.Header.Set(appInstanceIdHeader, defaultAppInstanceId)
+
+	mockWriter := &mockHttpWriter{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write", []byte("{\"title\":\"Termination not in progress\",\"status\":22,"+
+		"\"detail\":\"termination not in progress\"}\n")).Return(0, nil)
+	mockWriter.On("WriteHeader", 409)
+
+	confirmedTerminationStatus = nil
+	patches := gomonkey.ApplyFunc(backend.GetRecordWithRevision, func(path string) ([]byte, int64, int) {
+		status := models.AppTerminationStatus{OperationAction: util.OperationActionTerminating}
+		outBytes, _ := json.Marshal(&status)
+		return outBytes, 10, 0
+	})
+	defer patches.Reset()
+	// Status is removed after the read, hence the revision doesn't match anymore
+	patches.ApplyFunc(backend.PutRecordIfRevision, func(path string, value []byte, revision int64) int {
+		return util.RecordModifiedErr
+	})
+
+	// 24 is the order of the confirm termination handler in the URLPattern
+	service.URLPatterns()[24].Func(mockWriter, request)
+
+	assert.Equal(t, "409", responseHeader.Get(responseStatusHeader), "Response status code must be 409")
+	assert.Nil(t, confirmedTerminationStatus, "Removed termination status must not be written back")
+	mockWriter.AssertExpectations(t)
+}
+
+const deliveryStatusUrl = "/mep/mec_service_mgmt/v1/applications/%s/subscriptions/%s/delivery_status"
+
+var deliveryStatusSubscriptionId = uuid.NewV4().String()
+
+// Query the notification delivery status of a suspended subscription
+func TestGetAppSubscribeDeliveryStatus(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mp1Service{}
+	subscriptionId := deliveryStatusSubscriptionId
+	getRequest, _ := http.NewRequest("GET", fmt.Sprintf(deliveryStatusUrl, defaultAppInstanceId, subscriptionId),
+		nil)
+	getRequest.URL.RawQuery = fmt.Sprintf(":appInstanceId=%s&:subscriptionId=%s", defaultAppInstanceId,
+		subscriptionId)
+	getRequest.Header.Set(appInstanceIdHeader, defaultAppInstanceId)
+
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 200)
+
+	patches := gomonkey.ApplyFunc(backend.GetRecord, func(path string) ([]byte, int) {
+		if path == util.NotificationStatusPath+defaultAppInstanceId+"/"+deliveryStatusSubscriptionId {
+			status := models.NotificationDeliveryStatus{SubscriptionId: deliveryStatusSubscriptionId, State: util.SuspendedState,
+				FailedAttempts: util.NotificationMaxAttempts, LastError: "connection refused"}
+			outBytes, _ := json.Marshal(&status)
+			return outBytes, 0
+		}
+		return []byte("{}"), 0
+	})
+	defer patches.Reset()
+	patches.ApplyFunc(backend.GetRecords, func(path string) (map[string][]byte, int) {
+		return map[string][]byte{"00000000000000000001": []byte("{}"), "00000000000000000002": []byte("{}")}, 0
+	})
+
+	// 25 is the order of the delivery status handler in the URLPattern
+	service.URLPatterns()[25].Func(mockWriter, getRequest)
+
+	assert.Equal(t, "200", responseHeader.Get(responseStatusHeader), responseCheckFor200)
+	status := models.NotificationDeliveryStatus{}
+	_ = json.Unmarshal(mockWriter.response, &status)
+	assert.Equal(t, util.SuspendedState, status.State, "Subscription must be suspended")
+	assert.Equal(t, 2, status.PendingCount, "Pending count mismatch")
+	assert.Equal(t, "connection refused", status.LastError, "Last error mismatch")
+	mockWriter.AssertExpectations(t)
+}
+
+const updateAppSubscribeUrl = "/mep/mec_service_mgmt/v1/applications/%s/subscriptions/%s"
+const updateEndAppSubscribeUrl = "/mep/mec_app_support/v1/applications/%s/subscriptions/%s"
+
+var updatedSubscription []byte
+
+// Extend the expiry deadline of an app termination subscription
+func TestUpdateAppTerminationSubscribe(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mp1Service{}
+	subscriptionId := uuid.NewV4().String()
+	deadline := uint32(time.Now().Add(time.Hour).Unix())
+	updateSubscription := models.AppTerminationNotificationSubscription{
+		SubscriptionType:  "AppTerminationNotificationSubscription",
+		CallbackReference: callBackRef,
+		AppInstanceId:     defaultAppInstanceId,
+		ExpiryDeadline:    &models.TimeStamp{Seconds: deadline},
+	}
+	updateSubscriptionBytes, _ := json.Marshal(updateSubscription)
+	putRequest, _ := http.NewRequest("PUT", fmt.Sprintf(updateEndAppSubscribeUrl, defaultAppInstanceId,
+		subscriptionId), bytes.NewReader(updateSubscriptionBytes))
+	putRequest.URL.RawQuery = fmt.Sprintf(":appInstanceId=%s&:subscriptionId=%s", defaultAppInstanceId,
+		subscriptionId)
+	putRequest.Header.Set(appInstanceIdHeader, defaultAppInstanceId)
+
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 200)
+
+	patches := gomonkey.ApplyFunc(backend.GetRecord, func(path string) ([]byte, int) {
+		return []byte("{}"), 0
+	})
+	defer patches.Reset()
+	patches.ApplyFunc(backend.PutRecord, func(path string, value []byte) int {
+		updatedSubscription = value
+		return 0
+	})
+
+	// 27 is the order of the app termination subscription update handler in the URLPattern
+	service.URLPatterns()[27].Func(mockWriter, putRequest)
+
+	assert.Equal(t, "200", responseHeader.Get(responseStatusHeader), responseCheckFor200)
+	subscription := models.AppTerminationNotificationSubscription{}
+	_ = json.Unmarshal(updatedSubscription, &subscription)
+	assert.Equal(t, subscriptionId, subscription.SubscriptionId, "Subscription id mismatch")
+	assert.Equal(t, deadline, subscription.ExpiryDeadline.Seconds, "Expiry deadline not updated")
+	mockWriter.AssertExpectations(t)
+}
+
+// Expiry deadline in the past is rejected
+func TestUpdateAppSubscribeExpiredDeadline(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mp1Service{}
+	subscriptionId := uuid.NewV4().String()
+	updateSubscription := models.SerAvailabilityNotificationSubscription{
+		SubscriptionType:  "SerAvailabilityNotificationSubscription",
+		CallbackReference: callBackRef,
+		ExpiryDeadline:    &models.TimeStamp{Seconds: uint32(time.Now().Add(-time.Hour).Unix())},
+	}
+	updateSubscriptionBytes, _ := json.Marshal(updateSubscription)
+	putRequest, _ := http.NewRequest("PUT", fmt.Sprintf(updateAppSubscribeUrl, defaultAppInstanceId,
+		subscriptionId), bytes.NewReader(updateSubscriptionBytes))
+	putRequest.URL.RawQuery = fmt.Sprintf(":appInstanceId=%s&:subscriptionId=%s", defaultAppInstanceId,
+		subscriptionId)
+	putRequest.Header.Set(appInstanceIdHeader, defaultAppInstanceId)
+
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 400)
+
+	// 26 is the order of the service availability subscription update handler in the URLPattern
+	service.URLPatterns()[26].Func(mockWriter, putRequest)
+
+	assert.Equal(t, "400", responseHeader.Get(responseStatusHeader), "Response status code must be 400")
+	mockWriter.AssertExpectations(t)
+}
+
 //========================================PRODUCER SERVICE=================================================
 type serviceInfo struct {
 	//	SerInstanceId     string        `json:"serInstanceId,omitempty"`
@@ -1633,6 +2421,134 @@ type serviceInfo struct {
 	IsLocal           bool                 `json:"isLocal,omitempty"`
 }
 
+var transportCatalogue = []models.TransportInfo{{ID: "rest-http", Name: "REST", TransType: "REST_HTTP",
+	Protocol: "HTTP", Version: "2.0"}}
+
+// List the transports supported by the platform
+func TestGetTransports(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mp1Service{transports: transportCatalogue}
+	getRequest, _ := http.NewRequest("GET", "/mep/mec_service_mgmt/v1/transports", nil)
+
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 200)
+
+	// 34 is the order of the transports get handler in the URLPattern
+	service.URLPatterns()[34].Func(mockWriter, getRequest)
+
+	assert.Equal(t, "200", responseHeader.Get(responseStatusHeader), responseCheckFor200)
+	var transports []models.TransportInfo
+	_ = json.Unmarshal(mockWriter.response, &transports)
+	assert.Equal(t, transportCatalogue, transports, "Transport catalogue mismatch")
+	mockWriter.AssertExpectations(t)
+}
+
+// Registration with a transport not in the catalogue is rejected
+func TestPostServiceRegisterUnknownTransport(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mp1Service{transports: transportCatalogue}
+	serviceInf := serviceInfo{
+		SerName:     "FaceRegService5",
+		Version:     "4.5.8",
+		State:       "ACTIVE",
+		TransportID: "Rest1",
+		Serializer:  "JSON",
+	}
+	serviceInfBytes, _ := json.Marshal(serviceInf)
+	postRequest, _ := http.NewRequest("POST", fmt.Sprintf(postSubscribeUrl, defaultAppInstanceId),
+		bytes.NewReader(serviceInfBytes))
+	postRequest.URL.RawQuery = fmt.Sprintf(":appInstanceId=%s", defaultAppInstanceId)
+	postRequest.Header.Set(appInstanceIdHeader, defaultAppInstanceId)
+
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 400)
+
+	// 4 is the order of the service register handler in the URLPattern
+	service.URLPatterns()[4].Func(mockWriter, postRequest)
+
+	assert.Equal(t, "400", responseHeader.Get(responseStatusHeader), "Response status code must be 400")
+	mockWriter.AssertExpectations(t)
+}
+
+// Only the default transport is listed when no transport is configured
+func TestGetTransportsDefault(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mp1Service{transports: plans.NewTransportCatalogue(nil)}
+	getRequest, _ := http.NewRequest("GET", "/mep/mec_service_mgmt/v1/transports", nil)
+
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 200)
+
+	// 34 is the order of the transports get handler in the URLPattern
+	service.URLPatterns()[34].Func(mockWriter, getRequest)
+
+	assert.Equal(t, "200", responseHeader.Get(responseStatusHeader), responseCheckFor200)
+	var transports []models.TransportInfo
+	_ = json.Unmarshal(mockWriter.response, &transports)
+	assert.Equal(t, []models.TransportInfo{{ID: plans.DefaultTransportId, Name: "REST", Description: "REST over HTTP",
+		TransType: "REST_HTTP", Protocol: "HTTP", Version: "2.0"}}, transports, "Default transport mismatch")
+	mockWriter.AssertExpectations(t)
+}
+
+// Registration with a transport id is rejected when the catalogue is empty
+func TestPostServiceRegisterEmptyTransportCatalogue(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mp1Service{}
+	serviceInf := serviceInfo{
+		SerName:     "FaceRegService5",
+		Version:     "4.5.8",
+		State:       "ACTIVE",
+		TransportID: plans.DefaultTransportId,
+		Serializer:  "JSON",
+	}
+	serviceInfBytes, _ := json.Marshal(serviceInf)
+	postRequest, _ := http.NewRequest("POST", fmt.Sprintf(postSubscribeUrl, defaultAppInstanceId),
+		bytes.NewReader(serviceInfBytes))
+	postRequest.URL.RawQuery = fmt.Sprintf(":appInstanceId=%s", defaultAppInstanceId)
+	postRequest.Header.Set(appInstanceIdHeader, defaultAppInstanceId)
+
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 400)
+
+	// 4 is the order of the service register handler in the URLPattern
+	service.URLPatterns()[4].Func(mockWriter, postRequest)
+
+	assert.Equal(t, "400", responseHeader.Get(responseStatusHeader), "Response status code must be 400")
+	mockWriter.AssertExpectations(t)
+}
+
 // Register a service
 func TestPostServiceRegister(t *testing.T) {
 	defer func() {
@@ -1641,7 +2557,7 @@ func TestPostServiceRegister(t *testing.T) {
 		}
 	}()
 
-	service := Mp1Service{}
+	service := Mp1Service{transports: plans.NewTransportCatalogue(nil)}
 
 	svcCat := models.CategoryRef{
 		Href:    href,
@@ -1662,7 +2578,7 @@ func TestPostServiceRegister(t *testing.T) {
 		OAuth2Info: authInfo,
 	}
 	transInfo := models.TransportInfo{
-		ID:               "TransId12345",
+		ID:               plans.DefaultTransportId,
 		Name:             "REST",
 		Description:      restApi,
 		TransType:        "REST_HTTP",
@@ -1677,7 +2593,7 @@ func TestPostServiceRegister(t *testing.T) {
 		SerCategory:       svcCat,
 		Version:           "4.5.8",
 		State:             "ACTIVE",
-		TransportID:       "Rest1",
+		TransportID:       plans.DefaultTransportId,
 		TransportInfo:     transInfo,
 		Serializer:        "JSON",
 		ScopeOfLocality:   "MEC_SYSTEM",
@@ -1746,7 +2662,7 @@ func TestPostServiceRegisterJsonMarshalFail(t *testing.T) {
 		}
 	}()
 
-	service := Mp1Service{}
+	service := Mp1Service{transports: plans.NewTransportCatalogue(nil)}
 
 	svcCat := models.CategoryRef{
 		Href:    href,
@@ -1767,7 +2683,7 @@ func TestPostServiceRegisterJsonMarshalFail(t *testing.T) {
 		OAuth2Info: authInfo,
 	}
 	transInfo := models.TransportInfo{
-		ID:               "TransId12345",
+		ID:               plans.DefaultTransportId,
 		Name:             "REST",
 		Description:      restApi,
 		TransType:        "REST_HTTP",
@@ -1782,7 +2698,7 @@ func TestPostServiceRegisterJsonMarshalFail(t *testing.T) {
 		SerCategory:       svcCat,
 		Version:           "4.5.8",
 		State:             "ACTIVE",
-		TransportID:       "Rest1",
+		TransportID:       plans.DefaultTransportId,
 		TransportInfo:     transInfo,
 		Serializer:        "JSON",
 		ScopeOfLocality:   "MEC_SYSTEM",
@@ -1850,7 +2766,7 @@ func TestPostServiceRegisterFindInstanceByKeyFailed(t *testing.T) {
 		}
 	}()
 
-	service := Mp1Service{}
+	service := Mp1Service{transports: plans.NewTransportCatalogue(nil)}
 
 	svcCat := models.CategoryRef{
 		Href:    href,
@@ -1871,7 +2787,7 @@ func TestPostServiceRegisterFindInstanceByKeyFailed(t *testing.T) {
 		OAuth2Info: authInfo,
 	}
 	transInfo := models.TransportInfo{
-		ID:               "TransId12345",
+		ID:               plans.DefaultTransportId,
 		Name:             "REST",
 		Description:      restApi,
 		TransType:        "REST_HTTP",
@@ -1886,7 +2802,7 @@ func TestPostServiceRegisterFindInstanceByKeyFailed(t *testing.T) {
 		SerCategory:       svcCat,
 		Version:           "4.5.8",
 		State:             "ACTIVE",
-		TransportID:       "Rest1",
+		TransportID:       plans.DefaultTransportId,
 		TransportInfo:     transInfo,
 		Serializer:        "JSON",
 		ScopeOfLocality:   "MEC_SYSTEM",
@@ -2008,6 +2924,156 @@ func TestServiceDiscoverServiceFound(t *testing.T) {
 
 }
 
+var discoverFindRequests []*pb.FindInstancesRequest
+
+// Instances of the first service name only, the other names have no service
+func findDiscoverInstances(_ *srv.InstanceService, _ context.Context,
+	in *pb.FindInstancesRequest) (*pb.FindInstancesResponse, error) {
+	discoverFindRequests = append(discoverFindRequests, in)
+	if in.ServiceName != "FaceRegService5" {
+		return &pb.FindInstancesResponse{Response: &pb.Response{Code: scerr.ErrServiceNotExists}}, nil
+	}
+	return &pb.FindInstancesResponse{Response: &pb.Response{Code: pb.Response_SUCCESS},
+		Instances: []*pb.MicroServiceInstance{{InstanceId: defaultAppInstanceId, ServiceId: sampleServiceId,
+			Properties: map[string]string{"appInstanceId": defaultAppInstanceId, "serName": "FaceRegService5",
+				"mecState": "ACTIVE", "ConsumedLocalOnly": "false"}}}}, nil
+}
+
+// Discover/Query the services by names along with the version and the environment
+func TestServiceDiscoverServiceNamesWithVersion(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mp1Service{}
+
+	// Create http get request
+	getRequest, _ := http.NewRequest("GET",
+		fmt.Sprintf(serviceDiscoverUrlFormat, defaultAppInstanceId),
+		bytes.NewReader([]byte("")))
+	getRequest.URL.RawQuery = fmt.Sprintf(
+		":appInstanceId=%s&ser_name=%s&version=1.0.0&env=production&consumed_local_only=false", defaultAppInstanceId,
+		"FaceRegService5,FaceRegService6")
+	getRequest.Header.Set(appInstanceIdHeader, defaultAppInstanceId)
+
+	discoverFindRequests = nil
+	patches := gomonkey.ApplyMethod(reflect.TypeOf(&srv.InstanceService{}), "Find", findDiscoverInstances)
+	defer patches.Reset()
+
+	// Mock the response writer
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 200)
+
+	service.URLPatterns()[5].Func(mockWriter, getRequest)
+
+	mockWriter.AssertExpectations(t)
+	assert.Equal(t, 2, len(discoverFindRequests))
+	for _, req := range discoverFindRequests {
+		assert.Equal(t, "1.0.0", req.VersionRule)
+		assert.Equal(t, "production", req.Environment)
+	}
+	var services []*models.ServiceInfo
+	_ = json.Unmarshal(mockWriter.response, &services)
+	assert.Equal(t, 1, len(services))
+	assert.Equal(t, "FaceRegService5", services[0].SerName)
+}
+
+var discoverPageAfter string
+var discoverPageLimit int
+
+// Instances of two apps, only the instances accepted by the match are returned
+func findDiscoverInstancesPage(afterSerInstanceId string, limit int,
+	match func(*pb.MicroServiceInstance) bool) ([]*pb.MicroServiceInstance, error) {
+	discoverPageAfter, discoverPageLimit = afterSerInstanceId, limit
+	var instances []*pb.MicroServiceInstance
+	for i, appInstanceId := range []string{defaultAppInstanceId, "f7e898d1-4ad6-4dbc-9d93-5bbd1e8e2b9b",
+		defaultAppInstanceId, defaultAppInstanceId} {
+		instance := &pb.MicroServiceInstance{InstanceId: fmt.Sprintf("instance%d", i), ServiceId: sampleServiceId,
+			Properties: map[string]string{"appInstanceId": appInstanceId, "serName": "FaceRegService5",
+				"mecState": "ACTIVE", "ConsumedLocalOnly": "false"}}
+		if match(instance) && len(instances) < limit {
+			instances = append(instances, instance)
+		}
+	}
+	return instances, nil
+}
+
+// Discover/Query a page of the services, read from the data-store after the cursor
+func TestServiceDiscoverPage(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mp1Service{}
+
+	// Create http get request
+	getRequest, _ := http.NewRequest("GET",
+		fmt.Sprintf(serviceDiscoverUrlFormat, defaultAppInstanceId),
+		bytes.NewReader([]byte("")))
+	getRequest.URL.RawQuery = fmt.Sprintf(":appInstanceId=%s&limit=2&next_page=%s", defaultAppInstanceId,
+		base64.RawURLEncoding.EncodeToString([]byte(sampleServiceId+"instance")))
+	getRequest.Header.Set(appInstanceIdHeader, defaultAppInstanceId)
+
+	patches := gomonkey.ApplyFunc(util.FindInstancesPage, findDiscoverInstancesPage)
+	defer patches.Reset()
+
+	// Mock the response writer
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 200)
+
+	service.URLPatterns()[5].Func(mockWriter, getRequest)
+
+	mockWriter.AssertExpectations(t)
+	assert.Equal(t, sampleServiceId+"instance", discoverPageAfter)
+	assert.Equal(t, 3, discoverPageLimit, "one more instance than the limit is read")
+	var services []*models.ServiceInfo
+	_ = json.Unmarshal(mockWriter.response, &services)
+	assert.Equal(t, 2, len(services))
+	assert.Equal(t, sampleServiceId+"instance2", services[1].SerInstanceId)
+	assert.True(t, strings.Contains(responseHeader.Get("Link"), "next_page="+
+		base64.RawURLEncoding.EncodeToString([]byte(sampleServiceId+"instance2"))))
+}
+
+// Discover/Query a service with conflicting service selections
+func TestServiceDiscoverConflictingParams(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	service := Mp1Service{}
+
+	// Create http get request
+	getRequest, _ := http.NewRequest("GET",
+		fmt.Sprintf(serviceDiscoverUrlFormat, defaultAppInstanceId),
+		bytes.NewReader([]byte("")))
+	getRequest.URL.RawQuery = fmt.Sprintf(":appInstanceId=%s&ser_name=%s&ser_category_id=%s",
+		defaultAppInstanceId, "somename", "RNI01")
+	getRequest.Header.Set(appInstanceIdHeader, defaultAppInstanceId)
+
+	// Mock the response writer
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 400)
+
+	service.URLPatterns()[5].Func(mockWriter, getRequest)
+
+	mockWriter.AssertExpectations(t)
+}
+
 // Update a service parameter
 func TestPutServiceUpdate(t *testing.T) {
 	defer func() {
@@ -2016,7 +3082,7 @@ func TestPutServiceUpdate(t *testing.T) {
 		}
 	}()
 
-	service := Mp1Service{}
+	service := Mp1Service{transports: plans.NewTransportCatalogue(nil)}
 
 	svcCat := models.CategoryRef{
 		Href:    href,
@@ -2037,7 +3103,7 @@ func TestPutServiceUpdate(t *testing.T) {
 		OAuth2Info: authInfo,
 	}
 	transInfo := models.TransportInfo{
-		ID:               "TransId12345",
+		ID:               plans.DefaultTransportId,
 		Name:             "REST",
 		Description:      restApi,
 		TransType:        "REST_HTTP",
@@ -2052,7 +3118,7 @@ func TestPutServiceUpdate(t *testing.T) {
 		SerCategory:       svcCat,
 		Version:           "4.5.8",
 		State:             "ACTIVE",
-		TransportID:       "Rest1",
+		TransportID:       plans.DefaultTransportId,
 		TransportInfo:     transInfo,
 		Serializer:        "JSON",
 		ScopeOfLocality:   "MEC_SYSTEM",
@@ -2101,7 +3167,7 @@ func TestPutServiceUpdateFindInstanceFail(t *testing.T) {
 		}
 	}()
 
-	service := Mp1Service{}
+	service := Mp1Service{transports: plans.NewTransportCatalogue(nil)}
 
 	svcCat := models.CategoryRef{
 		Href:    href,
@@ -2122,7 +3188,7 @@ func TestPutServiceUpdateFindInstanceFail(t *testing.T) {
 		OAuth2Info: authInfo,
 	}
 	transInfo := models.TransportInfo{
-		ID:               "TransId12345",
+		ID:               plans.DefaultTransportId,
 		Name:             "REST",
 		Description:      restApi,
 		TransType:        "REST_HTTP",
@@ -2137,7 +3203,7 @@ func TestPutServiceUpdateFindInstanceFail(t *testing.T) {
 		SerCategory:       svcCat,
 		Version:           "4.5.8",
 		State:             "ACTIVE",
-		TransportID:       "Rest1",
+		TransportID:       plans.DefaultTransportId,
 		TransportInfo:     transInfo,
 		Serializer:        "JSON",
 		ScopeOfLocality:   "MEC_SYSTEM",
@@ -2486,3 +3552,92 @@ func TestHeartbeatServiceInvalidServiceId(t *testing.T) {
 		responseCheckFor400)
 	mockWriter.AssertExpectations(t)
 }
+
+//========================================TIMING=================================================
+const currentTimeUrl = "/mep/mec_app_support/v1/timing/current_time"
+const timingCapsUrl = "/mep/mec_app_support/v1/timing/timing_caps"
+
+// Time source stub reporting the configured status
+type stubTimeSource struct {
+	status string
+	err    error
+}
+
+func (s *stubTimeSource) GetTimeSourceStatus() (string, error) {
+	return s.status, s.err
+}
+
+func getCurrentTime(t *testing.T, service *Mp1Service) models.CurrentTime {
+	getRequest, _ := http.NewRequest("GET", currentTimeUrl, nil)
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 200)
+
+	// 35 is the order of the current time get handler in the URLPattern
+	service.URLPatterns()[35].Func(mockWriter, getRequest)
+
+	assert.Equal(t, "200", responseHeader.Get(responseStatusHeader), responseCheckFor200)
+	mockWriter.AssertExpectations(t)
+	currentTime := models.CurrentTime{}
+	_ = json.Unmarshal(mockWriter.response, &currentTime)
+	return currentTime
+}
+
+// Current time is traceable as reported by the time source
+func TestGetCurrentTime(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	before := time.Now().Unix()
+	currentTime := getCurrentTime(t, &Mp1Service{timeSource: &stubTimeSource{status: util.TimeSourceTraceable}})
+	assert.Equal(t, util.TimeSourceTraceable, currentTime.TimeSourceStatus, "Time source status mismatch")
+	assert.True(t, int64(currentTime.Seconds) >= before, "Current time is in the past")
+}
+
+// Current time is non traceable if the time source can not be read
+func TestGetCurrentTimeSourceFailure(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	currentTime := getCurrentTime(t, &Mp1Service{timeSource: &stubTimeSource{err: errors.New("chronyc failed")}})
+	assert.Equal(t, util.TimeSourceNonTraceable, currentTime.TimeSourceStatus, "Time source status mismatch")
+}
+
+// Timing capabilities report the configured NTP servers
+func TestGetTimingCaps(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf(panicFormatString, r)
+		}
+	}()
+
+	ntpServers := []models.NtpServer{{NtpServerAddrType: "IP_ADDRESS", NtpServerAddr: "192.0.2.1",
+		MinPollingInterval: 4, MaxPollingInterval: 10, AuthenticationOption: "NONE"}}
+	service := Mp1Service{timingCaps: models.TimingCaps{NtpServers: ntpServers}}
+	getRequest, _ := http.NewRequest("GET", timingCapsUrl, nil)
+
+	mockWriter := &mockHttpWriterWithoutWrite{}
+	responseHeader := http.Header{} // Create http response header
+	mockWriter.On("Header").Return(responseHeader)
+	mockWriter.On("Write").Return(0, nil)
+	mockWriter.On("WriteHeader", 200)
+
+	// 36 is the order of the timing caps get handler in the URLPattern
+	service.URLPatterns()[36].Func(mockWriter, getRequest)
+
+	assert.Equal(t, "200", responseHeader.Get(responseStatusHeader), responseCheckFor200)
+	timingCaps := models.TimingCaps{}
+	_ = json.Unmarshal(mockWriter.response, &timingCaps)
+	assert.Equal(t, ntpServers, timingCaps.NtpServers, "Ntp servers mismatch")
+	assert.Nil(t, timingCaps.PtpMasters, "Ptp masters must not be reported")
+	assert.NotZero(t, timingCaps.TimeStamp.Seconds, "Time stamp not set")
+	mockWriter.AssertExpectations(t)
+}
@@ -20,7 +20,6 @@ import (
 	"errors"
 	"fmt"
 	"mepserver/common/models"
-	"strconv"
 	"strings"
 
 	"github.com/apache/servicecomb-service-center/pkg/log"
@@ -72,16 +71,29 @@ func (h *InstanceEtsiEventHandler) OnEvent(evt discovery.KvEvent) {
 		return
 	}
 	domainName := domainProject[:idx]
+	instanceID := instance.ServiceId + instance.InstanceId
+	var changeType string
 	switch action {
 	case proto.EVT_INIT:
 		metrics.ReportInstances(domainName, 1)
+		serviceViews.update(instanceID, instance)
 		return
 	case proto.EVT_CREATE:
 		metrics.ReportInstances(domainName, 1)
+		serviceViews.update(instanceID, instance)
+		changeType = util2.ChangeTypeAdded
 	case proto.EVT_UPDATE:
 		metrics.ReportInstances(domainName, 0)
+		changeType = serviceViews.update(instanceID, instance)
+		if len(changeType) == 0 {
+			log.Debugf("instance [%s/%s] updated without any visible change, no notification",
+				providerID, providerInstanceID)
+			return
+		}
 	case proto.EVT_DELETE:
 		metrics.ReportInstances(domainName, -1)
+		serviceViews.remove(instanceID)
+		changeType = util2.ChangeTypeRemoved
 		if !apt.IsDefaultDomainProject(domainProject) {
 			projectName := domainProject[idx+1:]
 			svcutil.RemandInstanceQuota(util.SetDomainProject(context.Background(), domainName, projectName))
@@ -109,31 +121,21 @@ func (h *InstanceEtsiEventHandler) OnEvent(evt discovery.KvEvent) {
 		action, providerID, ms.Environment, ms.AppId, ms.ServiceName, ms.Version, providerInstanceID,
 		instance.Endpoints, domainProject)
 
-	SendRestMessageToApp(instance, string(action))
+	SendRestMessageToApp(instance, changeType)
 }
 
-// SendRestMessageToApp sendRestMessageToApp
-func SendRestMessageToApp(instance *proto.MicroServiceInstance, action string) {
-	instanceID := instance.ServiceId + instance.InstanceId
-	serName := instance.Properties["serName"]
-	isLocal := instance.Properties["isLocal"]
-	state := instance.Properties["mecState"]
-	serCategory := models.CategoryRef{
-		Href:    instance.Properties["serCategory/href"],
-		ID:      instance.Properties["serCategory/id"],
-		Name:    instance.Properties["serCategory/name"],
-		Version: instance.Properties["serCategory/version"],
-	}
-	callBackUris := getCallBackUris(instanceID, serName, isLocal, state, serCategory)
+// SendRestMessageToApp notify the subscribed apps about the kind of change of the service instance
+func SendRestMessageToApp(instance *proto.MicroServiceInstance, changeType string) {
+	callBackUris := getCallBackUris(instance)
 	if len(callBackUris) == 0 {
 		log.Info("callback uris is empty")
 		return
 	}
 
-	doSend(action, instance, callBackUris)
+	doSend(changeType, instance, callBackUris)
 }
 
-func doSend(action string, instance *proto.MicroServiceInstance, callbackUris map[string]string) {
+func doSend(changeType string, instance *proto.MicroServiceInstance, callbackUris map[string]string) {
 	var notificationInfo models.ServiceAvailabilityNotification
 	notificationInfo.ServiceReferences = make([]models.ServiceReferences, 1, 1)
 	notificationInfo.NotificationType = "ServiceAvailabilityNotification"
@@ -142,14 +144,9 @@ func doSend(action string, instance *proto.MicroServiceInstance, callbackUris ma
 	notificationInfo.ServiceReferences[0].State = instance.Properties["mecState"]
 	href := "/mec_service_mgmt/v1/services/" + instance.ServiceId + instance.InstanceId
 
-	//Currently we cant identify only state changed. So Only attributes change will be supported
-	if action == "CREATE" {
-		notificationInfo.ServiceReferences[0].ChangeType = "ADDED"
-		notificationInfo.ServiceReferences[0].Link.Href = href
-	} else if action == "DELETE" {
-		notificationInfo.ServiceReferences[0].ChangeType = "REMOVED"
-	} else if action == "UPDATE" {
-		notificationInfo.ServiceReferences[0].ChangeType = "ATTRIBUTES_CHANGED"
+	notificationInfo.ServiceReferences[0].ChangeType = changeType
+	// Removed service has no resource to link
+	if changeType != util2.ChangeTypeRemoved {
 		notificationInfo.ServiceReferences[0].Link.Href = href
 	}
 	for subscription, callBackURI := range callbackUris {
@@ -157,7 +154,7 @@ func doSend(action string, instance *proto.MicroServiceInstance, callbackUris ma
 	}
 }
 
-//SendMsg send message
+//SendMsg queue the message in the outbox of the subscription for delivery
 func SendMsg(notificationInfo models.ServiceAvailabilityNotification, callBackURI string, susbcription string) {
 	log.Infof("subscription key %s uri %s", susbcription, callBackURI)
 	app := strings.Split(susbcription, "/")
@@ -171,79 +168,22 @@ func SendMsg(notificationInfo models.ServiceAvailabilityNotification, callBackUR
 		return
 	}
 
-	err = util2.SendPostRequest(callBackURI, notificationInfoJSON, true)
-	if err != nil {
-		log.Error("failed to send notification", nil)
-	}
+	enqueueNotification(appInstID, subscriptionID, callBackURI, notificationInfoJSON)
 }
 
-func getCallBackUris(instanceID string, serName string, isLocal string, state string, serCategory models.CategoryRef) map[string]string {
+// Callback uris of the subscriptions with the filtering criteria matching the instance
+func getCallBackUris(instance *proto.MicroServiceInstance) map[string]string {
 	notifyInfos := GetAllSubscriberInfoFromDB()
 	callBackUris := make(map[string]string, len(notifyInfos))
 
 	for subKey, notifyInfo := range notifyInfos {
-		callBackURI := notifyInfo.CallbackReference
-		filter := notifyInfo.FilteringCriteria
-		if isInFilter(filter, instanceID, serName, isLocal, state, serCategory) {
-			callBackUris[subKey] = callBackURI
+		if notifyInfo.FilteringCriteria.ServiceFilter().Match(instance) {
+			callBackUris[subKey] = notifyInfo.NotificationUri()
 		}
 	}
 
 	return callBackUris
 }
-func isInFilter(filter models.FilteringCriteria, instanceID string, serName string, isLocal string, state string, serCategory models.CategoryRef) bool {
-	localFilter := false
-	stateFilter := false
-	if strconv.FormatBool(filter.IsLocal) == isLocal {
-		localFilter = true
-	}
-	state = stateConvert(state)
-	if filter.States == nil || len(filter.States) == 0 {
-		stateFilter = true
-	} else if util2.StringContains(filter.States, state) != -1 {
-		stateFilter = true
-	}
-
-	localFilter = true
-	if !localFilter || !stateFilter {
-		return false
-	}
-	if isAllFilterEmpty(filter) {
-		return true
-	}
-	return isFilterContain(filter, instanceID, serName, serCategory)
-}
-
-func stateConvert(state string) string {
-	log.Infof("state %s", state)
-	return state
-}
-func isAllFilterEmpty(filter models.FilteringCriteria) bool {
-	if len(filter.SerNames) == 0 && len(filter.SerInstanceIds) == 0 && len(filter.SerCategories) == 0 && len(filter.States) == 0 && !filter.IsLocal {
-		return true
-	}
-	return false
-}
-func isFilterContain(filter models.FilteringCriteria, instanceID string, serName string, serCategory models.CategoryRef) bool {
-	if (len(filter.SerInstanceIds) != 0 && util2.StringContains(filter.SerInstanceIds, instanceID) != -1) ||
-		(len(filter.SerNames) != 0 && util2.StringContains(filter.SerNames, serName) != -1) ||
-		(len(filter.SerCategories) != 0 && isServiceCategoryMatched(filter.SerCategories, serCategory)) {
-		return true
-	}
-	return false
-}
-
-func isServiceCategoryMatched(serCategories []models.CategoryRef, serCategory models.CategoryRef) bool {
-	for _, tempSerCat := range serCategories {
-		if tempSerCat.Href == serCategory.Href &&
-			tempSerCat.ID == serCategory.ID &&
-			tempSerCat.Name == serCategory.Name &&
-			tempSerCat.Version == serCategory.Version {
-			return true
-		}
-	}
-	return false
-}
 
 //GetAllSubscriberInfoFromDB GetAllSubscriberInfoFromDB
 func GetAllSubscriberInfoFromDB() map[string]*models.SerAvailabilityNotificationSubscription {
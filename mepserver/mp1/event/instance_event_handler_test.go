@@ -16,7 +16,9 @@
 package event
 
 import (
+	"mepserver/common/extif/backend"
 	"mepserver/common/models"
+	"mepserver/common/util"
 	"testing"
 
 	"github.com/agiledragon/gomonkey"
@@ -154,6 +156,11 @@ func TestNewInstanceEtsiEventHandler(t *testing.T) {
 		return notifyInfos
 	})
 	defer patch2.Reset()
+	// Outbox write failure falls back to a single delivery attempt
+	patch3 := gomonkey.ApplyFunc(backend.PutRecord, func(string, []byte) int {
+		return util.OperateDataWithEtcdErr
+	})
+	defer patch3.Reset()
 
 	for _, v := range cases {
 		notify.NotifyCenter().Start()
@@ -162,3 +169,36 @@ func TestNewInstanceEtsiEventHandler(t *testing.T) {
 		notify.NotifyCenter().Stop()
 	}
 }
+
+func TestGetCallBackUris(t *testing.T) {
+	patch := gomonkey.ApplyFunc(GetAllSubscriberInfoFromDB, func() map[string]*models.SerAvailabilityNotificationSubscription {
+		return map[string]*models.SerAvailabilityNotificationSubscription{
+			"categoryById": {CallbackReference: "http://hello:80/category",
+				FilteringCriteria: models.FilteringCriteria{SerCategories: []models.CategoryRef{
+					{Href: "/example/catalogue1", ID: "RNI01", Name: "RNI-OLD", Version: "1.0"}}}},
+			"nameAndState": {CallbackReference: "http://hello:80/state",
+				FilteringCriteria: models.FilteringCriteria{SerNames: []string{"faceapp01"},
+					States: []string{"INACTIVE"}}},
+			"otherName": {CallbackReference: "http://hello:80/name",
+				FilteringCriteria: models.FilteringCriteria{SerNames: []string{"faceapp02"}}},
+			"local": {CallbackReference: "http://hello:80/local",
+				FilteringCriteria: models.FilteringCriteria{IsLocal: true}},
+		}
+	})
+	defer patch.Reset()
+
+	instance := &proto.MicroServiceInstance{
+		ServiceId:  "c936bdb887337c15",
+		InstanceId: "a8612ca7603ad979",
+		Properties: map[string]string{
+			"serName":        "faceapp01",
+			"serCategory/id": "RNI01",
+			"IsLocal":        "true",
+			"mecState":       "ACTIVE",
+		},
+	}
+	callBackUris := getCallBackUris(instance)
+	if len(callBackUris) != 2 || callBackUris["categoryById"] == "" || callBackUris["local"] == "" {
+		t.Fatalf("unexpected subscriptions matched: %v", callBackUris)
+	}
+}
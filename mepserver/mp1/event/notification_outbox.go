@@ -0,0 +1,313 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/servicecomb-service-center/pkg/log"
+	"github.com/astaxie/beego/httplib"
+
+	"mepserver/common/extif/backend"
+	"mepserver/common/models"
+	meputil "mepserver/common/util"
+)
+
+// Notification waiting in the outbox of a subscription till the callback accepts it
+type outboxEntry struct {
+	CallbackUri string `json:"callbackUri"`
+	Body        []byte `json:"body"`
+	CreateTime  int64  `json:"createTime"`
+}
+
+// Subscriptions with a running delivery routine, a single routine per subscription keeps the delivery order
+type notificationOutbox struct {
+	mutex   sync.Mutex
+	active  map[string]bool
+	lastSeq int64
+}
+
+var outbox = &notificationOutbox{active: make(map[string]bool)}
+
+// Interval before the first retry of a failed delivery
+var notificationRetryInterval = time.Duration(meputil.NotificationRetryInterval) * time.Second
+
+// Sequence is zero padded in the key, so that the outbox entries are in the submission order
+func outboxEntryKey(appInstanceId, subscriptionId string, seq int64) string {
+	return fmt.Sprintf("%s%s/%s/%020d", meputil.NotificationOutboxPath, appInstanceId, subscriptionId, seq)
+}
+
+// Keep the sequence increasing even for the notifications queued within the same nanosecond
+func (o *notificationOutbox) nextSeq() int64 {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	seq := time.Now().UnixNano()
+	if seq <= o.lastSeq {
+		seq = o.lastSeq + 1
+	}
+	o.lastSeq = seq
+	return seq
+}
+
+// Persist the notification in the outbox of the subscription and trigger its delivery
+func enqueueNotification(appInstanceId, subscriptionId, callbackUri string, body []byte) {
+	entryBytes, err := json.Marshal(&outboxEntry{CallbackUri: callbackUri, Body: body, CreateTime: time.Now().Unix()})
+	if err != nil {
+		log.Errorf(nil, "can not marshal the outbox entry")
+		return
+	}
+	if errCode := backend.PutRecord(outboxEntryKey(appInstanceId, subscriptionId, outbox.nextSeq()),
+		entryBytes); errCode != 0 {
+		log.Errorf(nil, "notification outbox write failed(subscription-id: %s), delivering once", subscriptionId)
+		if err = deliverNotification(callbackUri, body); err != nil {
+			log.Errorf(err, "failed to send notification(subscription-id: %s)", subscriptionId)
+		}
+		return
+	}
+	trimOutbox(appInstanceId, subscriptionId)
+	outbox.start(appInstanceId, subscriptionId)
+}
+
+// Drop the oldest notifications of a subscription above the outbox limit
+func trimOutbox(appInstanceId, subscriptionId string) {
+	keys, _ := getOutboxEntryKeys(appInstanceId, subscriptionId)
+	if len(keys) <= meputil.NotificationOutboxLimit {
+		return
+	}
+	log.Warnf("notification outbox of subscription(%s) is full, dropping %d oldest notifications",
+		subscriptionId, len(keys)-meputil.NotificationOutboxLimit)
+	_ = backend.DeletePaths(keys[:len(keys)-meputil.NotificationOutboxLimit], true)
+}
+
+// Outbox entry keys of the subscription in the submission order
+func getOutboxEntryKeys(appInstanceId, subscriptionId string) ([]string, map[string][]byte) {
+	records, errCode := backend.GetRecordsWithCompleteKeyPath(meputil.NotificationOutboxPath + appInstanceId + "/" +
+		subscriptionId + "/")
+	if errCode != 0 {
+		log.Errorf(nil, "retrieve notification outbox from data-store failed")
+		return nil, nil
+	}
+	keys := make([]string, 0, len(records))
+	for key := range records {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, records
+}
+
+func (o *notificationOutbox) start(appInstanceId, subscriptionId string) {
+	subKey := appInstanceId + "/" + subscriptionId
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if o.active[subKey] {
+		return
+	}
+	o.active[subKey] = true
+	go o.deliver(appInstanceId, subscriptionId)
+}
+
+// Release the subscription unless a notification got queued meanwhile, checked under the lock as the enqueue
+// persists the entry before starting the delivery
+func (o *notificationOutbox) stop(appInstanceId, subscriptionId string, force bool) bool {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if !force {
+		if keys, _ := getOutboxEntryKeys(appInstanceId, subscriptionId); len(keys) != 0 {
+			return false
+		}
+	}
+	delete(o.active, appInstanceId+"/"+subscriptionId)
+	return true
+}
+
+// Deliver the outbox entries of the subscription one by one, retrying the oldest with exponential backoff
+func (o *notificationOutbox) deliver(appInstanceId, subscriptionId string) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf(nil, "Notification delivery panic: %v \n %s", r, string(debug.Stack()))
+			o.stop(appInstanceId, subscriptionId, true)
+		}
+	}()
+
+	interval := notificationRetryInterval
+	for {
+		if !subscriptionExists(appInstanceId, subscriptionId) {
+			log.Infof("Subscription(%s) removed, notification delivery stopped", subscriptionId)
+			o.stop(appInstanceId, subscriptionId, true)
+			return
+		}
+		keys, records := getOutboxEntryKeys(appInstanceId, subscriptionId)
+		if len(keys) == 0 {
+			if o.stop(appInstanceId, subscriptionId, false) {
+				return
+			}
+			continue
+		}
+		entry := &outboxEntry{}
+		if err := json.Unmarshal(records[keys[0]], entry); err != nil {
+			log.Warnf("dropping the invalid notification outbox entry(%s)", keys[0])
+			_ = backend.DeleteRecord(keys[0])
+			continue
+		}
+
+		status := GetDeliveryStatus(appInstanceId, subscriptionId)
+		status.LastAttemptTime = time.Now().Unix()
+		err := deliverNotification(entry.CallbackUri, entry.Body)
+		if err == nil {
+			_ = backend.DeleteRecord(keys[0])
+			status.State = meputil.ActiveState
+			status.DeliveredCount++
+			status.FailedAttempts = 0
+			status.LastDeliveryTime = status.LastAttemptTime
+			saveDeliveryStatus(appInstanceId, subscriptionId, status)
+			interval = notificationRetryInterval
+			continue
+		}
+
+		status.FailedAttempts++
+		status.LastError = err.Error()
+		if status.FailedAttempts >= meputil.NotificationMaxAttempts {
+			// Suspended subscription is retried on its next notification
+			log.Errorf(err, "notification delivery failed %d times, subscription(%s) suspended",
+				status.FailedAttempts, subscriptionId)
+			status.State = meputil.SuspendedState
+			saveDeliveryStatus(appInstanceId, subscriptionId, status)
+			o.stop(appInstanceId, subscriptionId, true)
+			return
+		}
+		log.Warnf("notification delivery attempt %d failed(subscription-id: %s): %s", status.FailedAttempts,
+			subscriptionId, err.Error())
+		saveDeliveryStatus(appInstanceId, subscriptionId, status)
+		time.Sleep(interval)
+		interval *= 2
+		if interval > meputil.NotificationMaxRetryInterval*time.Second {
+			interval = meputil.NotificationMaxRetryInterval * time.Second
+		}
+	}
+}
+
+// Outbox belongs to a subscription of either type, the subscription ids are unique across the types
+func subscriptionExists(appInstanceId, subscriptionId string) bool {
+	for _, keyPath := range []string{meputil.AvailAppSubKeyPath, meputil.EndAppSubKeyPath} {
+		if _, errCode := backend.GetRecord(keyPath + appInstanceId + "/" + subscriptionId); errCode == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Post the notification to the callback, any response other than 2xx is a failed delivery
+func postNotification(callbackUri string, body []byte) error {
+	req := httplib.Post(callbackUri)
+	req.Header("Content-Type", "application/json; charset=utf-8")
+	req.Body(body)
+	req.SetTimeout(meputil.NotificationTimeout*time.Second, meputil.NotificationTimeout*time.Second)
+	config, err := meputil.TLSConfig("apigw_cacert", true)
+	if err != nil {
+		log.Error("unable to read certificate", nil)
+		return err
+	}
+	req.SetTLSClientConfig(config)
+
+	resp, err := req.Response()
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("callback responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Notification is pushed over the websocket of the subscription, or posted to its callback
+func sendNotification(uri string, body []byte) error {
+	if meputil.IsWebsocketUri(uri) {
+		return meputil.PushWebsocketNotification(uri, body)
+	}
+	return postNotification(uri, body)
+}
+
+var deliverNotification = sendNotification
+
+// Delivery status of the subscription, an active status without any delivery if nothing is recorded yet
+func GetDeliveryStatus(appInstanceId, subscriptionId string) *models.NotificationDeliveryStatus {
+	status := &models.NotificationDeliveryStatus{SubscriptionId: subscriptionId, State: meputil.ActiveState}
+	statusBytes, errCode := backend.GetRecord(meputil.NotificationStatusPath + appInstanceId + "/" + subscriptionId)
+	if errCode != 0 {
+		return status
+	}
+	if err := json.Unmarshal(statusBytes, status); err != nil {
+		log.Warnf("failed to parse the notification delivery status(subscription-id: %s)", subscriptionId)
+	}
+	return status
+}
+
+func saveDeliveryStatus(appInstanceId, subscriptionId string, status *models.NotificationDeliveryStatus) {
+	statusBytes, err := json.Marshal(status)
+	if err != nil {
+		log.Errorf(nil, "can not marshal the notification delivery status")
+		return
+	}
+	if errCode := backend.PutRecord(meputil.NotificationStatusPath+appInstanceId+"/"+subscriptionId,
+		statusBytes); errCode != 0 {
+		log.Errorf(nil, "notification delivery status write failed(subscription-id: %s)", subscriptionId)
+	}
+}
+
+// Resume the delivery of the pending notifications of the subscription, once its websocket is connected
+func ResumeNotificationDelivery(appInstanceId, subscriptionId string) {
+	outbox.start(appInstanceId, subscriptionId)
+}
+
+// Resume the delivery of the notifications left in the outbox by a previous run of the mep-server, suspended
+// subscriptions wait for their next notification
+func RecoverNotificationOutbox() {
+	// Wait for the data-store to be available
+	backend.WaitForReady()
+	records, errCode := backend.GetRecordsWithCompleteKeyPath(meputil.NotificationOutboxPath)
+	if errCode != 0 {
+		log.Errorf(nil, "retrieve notification outbox from data-store failed")
+		return
+	}
+	recovered := make(map[string]bool)
+	resumed := 0
+	for key := range records {
+		// Key format is <outbox path><app instance id>/<subscription id>/<sequence>
+		parts := strings.Split(strings.TrimPrefix(key, meputil.NotificationOutboxPath), "/")
+		if len(parts) != 3 || recovered[parts[0]+"/"+parts[1]] {
+			continue
+		}
+		recovered[parts[0]+"/"+parts[1]] = true
+		if GetDeliveryStatus(parts[0], parts[1]).State == meputil.SuspendedState {
+			continue
+		}
+		outbox.start(parts[0], parts[1])
+		resumed++
+	}
+	log.Infof("Notification delivery resumed for %d subscriptions", resumed)
+}
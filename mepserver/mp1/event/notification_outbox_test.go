@@ -0,0 +1,164 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package event
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+
+	"mepserver/common/extif/backend"
+	"mepserver/common/util"
+)
+
+const (
+	outboxAppInstanceId  = "5abe4782-2c70-4e47-9a4e-0ee3a1a0fd1f"
+	outboxSubscriptionId = "83b35ec2-0afe-4563-ab25-d36f3709221d"
+	outboxCallbackUri    = "http://hello:80/state/notify"
+)
+
+var (
+	outboxStoreMutex sync.Mutex
+	outboxStore      map[string][]byte
+	deliveredBodies  []string
+	deliveryFailures int
+)
+
+func patchOutboxStore() *gomonkey.Patches {
+	outboxStore = map[string][]byte{
+		util.AvailAppSubKeyPath + outboxAppInstanceId + "/" + outboxSubscriptionId: []byte("{}"),
+	}
+	deliveredBodies = nil
+	notificationRetryInterval = time.Millisecond
+
+	patches := gomonkey.ApplyFunc(backend.PutRecord, func(path string, value []byte) int {
+		outboxStoreMutex.Lock()
+		defer outboxStoreMutex.Unlock()
+		outboxStore[path] = value
+		return 0
+	})
+	patches.ApplyFunc(backend.GetRecord, func(path string) ([]byte, int) {
+		outboxStoreMutex.Lock()
+		defer outboxStoreMutex.Unlock()
+		for key, value := range outboxStore {
+			if strings.HasPrefix(key, path) {
+				return value, 0
+			}
+		}
+		return nil, util.SubscriptionNotFound
+	})
+	patches.ApplyFunc(backend.GetRecordsWithCompleteKeyPath, func(path string) (map[string][]byte, int) {
+		outboxStoreMutex.Lock()
+		defer outboxStoreMutex.Unlock()
+		records := make(map[string][]byte)
+		for key, value := range outboxStore {
+			if strings.HasPrefix(key, path) {
+				records[key] = value
+			}
+		}
+		return records, 0
+	})
+	patches.ApplyFunc(backend.DeleteRecord, func(path string) int {
+		outboxStoreMutex.Lock()
+		defer outboxStoreMutex.Unlock()
+		delete(outboxStore, path)
+		return 0
+	})
+	return patches
+}
+
+// Failing deliveries till the failure count, then the bodies are recorded as delivered
+func deliverWithFailures(callbackUri string, body []byte) error {
+	outboxStoreMutex.Lock()
+	defer outboxStoreMutex.Unlock()
+	if deliveryFailures > 0 {
+		deliveryFailures--
+		return errors.New("connection refused")
+	}
+	deliveredBodies = append(deliveredBodies, string(body))
+	return nil
+}
+
+func waitDeliveryStopped(t *testing.T) {
+	for i := 0; i < 1000; i++ {
+		outbox.mutex.Lock()
+		active := outbox.active[outboxAppInstanceId+"/"+outboxSubscriptionId]
+		outbox.mutex.Unlock()
+		if !active {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("notification delivery did not stop")
+}
+
+func TestNotificationOutboxDeliveryOrder(t *testing.T) {
+	patches := patchOutboxStore()
+	defer patches.Reset()
+	deliverNotification = deliverWithFailures
+	defer func() { deliverNotification = postNotification }()
+	deliveryFailures = 2
+
+	enqueueNotification(outboxAppInstanceId, outboxSubscriptionId, outboxCallbackUri, []byte("first"))
+	enqueueNotification(outboxAppInstanceId, outboxSubscriptionId, outboxCallbackUri, []byte("second"))
+	waitDeliveryStopped(t)
+
+	if len(deliveredBodies) != 2 || deliveredBodies[0] != "first" || deliveredBodies[1] != "second" {
+		t.Fatalf("notifications not delivered in order: %v", deliveredBodies)
+	}
+	if keys, _ := getOutboxEntryKeys(outboxAppInstanceId, outboxSubscriptionId); len(keys) != 0 {
+		t.Fatalf("outbox must be empty after the delivery")
+	}
+	status := GetDeliveryStatus(outboxAppInstanceId, outboxSubscriptionId)
+	if status.State != util.ActiveState || status.DeliveredCount != 2 || status.FailedAttempts != 0 ||
+		status.LastError != "connection refused" {
+		t.Fatalf("unexpected delivery status: %+v", status)
+	}
+}
+
+func TestNotificationOutboxSuspend(t *testing.T) {
+	patches := patchOutboxStore()
+	defer patches.Reset()
+	deliverNotification = deliverWithFailures
+	defer func() { deliverNotification = postNotification }()
+	deliveryFailures = util.NotificationMaxAttempts
+
+	enqueueNotification(outboxAppInstanceId, outboxSubscriptionId, outboxCallbackUri, []byte("first"))
+	waitDeliveryStopped(t)
+
+	status := GetDeliveryStatus(outboxAppInstanceId, outboxSubscriptionId)
+	if status.State != util.SuspendedState || status.FailedAttempts != util.NotificationMaxAttempts {
+		t.Fatalf("subscription must be suspended: %+v", status)
+	}
+	if keys, _ := getOutboxEntryKeys(outboxAppInstanceId, outboxSubscriptionId); len(keys) != 1 {
+		t.Fatalf("undelivered notification must be kept in the outbox")
+	}
+
+	// Next notification resumes the suspended subscription
+	enqueueNotification(outboxAppInstanceId, outboxSubscriptionId, outboxCallbackUri, []byte("second"))
+	waitDeliveryStopped(t)
+	if len(deliveredBodies) != 2 || deliveredBodies[0] != "first" {
+		t.Fatalf("notifications not delivered in order after resume: %v", deliveredBodies)
+	}
+	if status = GetDeliveryStatus(outboxAppInstanceId, outboxSubscriptionId); status.State != util.ActiveState {
+		t.Fatalf("subscription must be active after the delivery: %+v", status)
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package event
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/apache/servicecomb-service-center/server/core/proto"
+
+	"mepserver/common/models"
+	meputil "mepserver/common/util"
+)
+
+// Service info of the instances as the apps see it, the service-center events carry only the new instance, hence
+// the previous view is kept to find the kind of change
+type serviceViewCache struct {
+	mutex sync.Mutex
+	views map[string]models.ServiceInfo
+}
+
+var serviceViews = &serviceViewCache{views: make(map[string]models.ServiceInfo)}
+
+// Cache the view of the instance and return the kind of change from its previous view, empty when nothing visible
+// to the apps changed. An instance without a previous view is considered to have its attributes changed.
+func (c *serviceViewCache) update(instanceID string, instance *proto.MicroServiceInstance) string {
+	view := models.ServiceInfo{}
+	view.FromServiceInstance(instance)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	prev, found := c.views[instanceID]
+	c.views[instanceID] = view
+	if !found {
+		return meputil.ChangeTypeAttributesChanged
+	}
+	return serviceChangeType(&prev, &view)
+}
+
+func (c *serviceViewCache) remove(instanceID string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.views, instanceID)
+}
+
+// STATE_CHANGED when only the state differs, ATTRIBUTES_CHANGED for any other difference
+func serviceChangeType(prev, cur *models.ServiceInfo) string {
+	if reflect.DeepEqual(prev, cur) {
+		return ""
+	}
+	prevWithCurState := *prev
+	prevWithCurState.State = cur.State
+	if reflect.DeepEqual(&prevWithCurState, cur) {
+		return meputil.ChangeTypeStateChanged
+	}
+	return meputil.ChangeTypeAttributesChanged
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package event
+
+import (
+	"testing"
+
+	"github.com/apache/servicecomb-service-center/server/core/proto"
+
+	"mepserver/common/util"
+)
+
+func newChangeInstance(serName, state, seconds string) *proto.MicroServiceInstance {
+	return &proto.MicroServiceInstance{
+		ServiceId:  "c936bdb887337c15",
+		InstanceId: "a8612ca7603ad979",
+		Version:    "1.0",
+		Properties: map[string]string{
+			"serName":           serName,
+			"mecState":          state,
+			"timestamp/seconds": seconds,
+		},
+	}
+}
+
+func TestServiceChangeType(t *testing.T) {
+	const instanceID = "c936bdb887337c15a8612ca7603ad979"
+	defer serviceViews.remove(instanceID)
+
+	cases := []struct {
+		name       string
+		instance   *proto.MicroServiceInstance
+		changeType string
+	}{
+		{"unknown previous view", newChangeInstance("faceapp01", util.ActiveState, "1"),
+			util.ChangeTypeAttributesChanged},
+		{"heartbeat only", newChangeInstance("faceapp01", util.ActiveState, "2"), ""},
+		{"state only", newChangeInstance("faceapp01", util.SuspendedState, "3"), util.ChangeTypeStateChanged},
+		{"attributes", newChangeInstance("faceapp02", util.SuspendedState, "3"), util.ChangeTypeAttributesChanged},
+		{"state and attributes", newChangeInstance("faceapp03", util.ActiveState, "3"),
+			util.ChangeTypeAttributesChanged},
+	}
+	for _, c := range cases {
+		if changeType := serviceViews.update(instanceID, c.instance); changeType != c.changeType {
+			t.Errorf("%s: expected change type %q, got %q", c.name, c.changeType, changeType)
+		}
+	}
+}
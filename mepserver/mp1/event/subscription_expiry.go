@@ -0,0 +1,131 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/apache/servicecomb-service-center/pkg/log"
+
+	"mepserver/common/extif/backend"
+	"mepserver/common/models"
+	meputil "mepserver/common/util"
+)
+
+// Fields shared by both the subscription types, needed for the expiry handling
+type expiringSubscription struct {
+	CallbackReference  string                     `json:"callbackReference"`
+	WebsockNotifConfig *models.WebsockNotifConfig `json:"websockNotifConfig"`
+	ExpiryDeadline     *models.TimeStamp          `json:"expiryDeadline"`
+}
+
+func (s *expiringSubscription) notificationUri() string {
+	sub := models.AppTerminationNotificationSubscription{CallbackReference: s.CallbackReference,
+		WebsockNotifConfig: s.WebsockNotifConfig}
+	return sub.NotificationUri()
+}
+
+// Deadline for which the expiry notification is already sent, an extended deadline is notified again
+var expiryNotified = make(map[string]models.TimeStamp)
+
+// Periodically notify the subscriptions about to expire and delete the expired ones
+func SweepExpiredSubscriptions() {
+	// Wait for the data-store to be available
+	backend.WaitForReady()
+	ticker := time.NewTicker(meputil.SubscriptionExpirySweepInterval * time.Second)
+	defer ticker.Stop()
+	for {
+		sweepSubscriptions(meputil.SerAvailabilityNotificationSubscription, time.Now())
+		sweepSubscriptions(meputil.AppTerminationNotificationSubscription, time.Now())
+		<-ticker.C
+	}
+}
+
+func sweepSubscriptions(subscribeType string, now time.Time) {
+	subscribeKeyPath := meputil.GetSubscribeKeyPath(subscribeType)
+	records, errCode := backend.GetRecordsWithCompleteKeyPath(subscribeKeyPath)
+	if errCode != 0 {
+		log.Errorf(nil, "retrieve subscriptions from data-store failed")
+		return
+	}
+
+	for key, record := range records {
+		sub := &expiringSubscription{}
+		if err := json.Unmarshal(record, sub); err != nil || sub.ExpiryDeadline == nil {
+			continue
+		}
+		// Key format is <subscribe key path><app instance id>/<subscription id>
+		ids := strings.Split(strings.TrimPrefix(key, subscribeKeyPath), "/")
+		if len(ids) != 2 {
+			continue
+		}
+
+		deadline := sub.ExpiryDeadline.Time()
+		if !now.Before(deadline) {
+			deleteExpiredSubscription(key, ids[0], ids[1])
+			delete(expiryNotified, key)
+			continue
+		}
+		if deadline.Sub(now) <= meputil.SubscriptionExpiryNoticeTime*time.Second &&
+			expiryNotified[key] != *sub.ExpiryDeadline {
+			sendExpiryNotification(subscribeType, ids[0], ids[1], sub)
+			expiryNotified[key] = *sub.ExpiryDeadline
+		}
+	}
+
+	// Forget the subscriptions deleted meanwhile
+	for key := range expiryNotified {
+		if _, found := records[key]; !found && strings.HasPrefix(key, subscribeKeyPath) {
+			delete(expiryNotified, key)
+		}
+	}
+}
+
+// Remove the subscription along with its pending notifications and the delivery status
+func deleteExpiredSubscription(key, appInstanceId, subscriptionId string) {
+	log.Infof("Subscription(%s) of the app(%s) expired", subscriptionId, appInstanceId)
+	paths := []string{key, meputil.NotificationOutboxPath + appInstanceId + "/" + subscriptionId + "/",
+		meputil.NotificationStatusPath + appInstanceId + "/" + subscriptionId}
+	if errCode := backend.DeletePaths(paths, true); errCode != 0 {
+		log.Errorf(nil, "expired subscription(%s) delete failed", subscriptionId)
+	}
+}
+
+// Notification is queued in the outbox of the subscription, so that it is retried till the callback accepts it
+func sendExpiryNotification(subscribeType, appInstanceId, subscriptionId string, sub *expiringSubscription) {
+	apiPath := meputil.MecServicePath
+	if subscribeType != meputil.SerAvailabilityNotificationSubscription {
+		apiPath = meputil.MecAppSupportPath
+	}
+	notification := models.ExpiryNotification{
+		NotificationType: meputil.ExpiryNotification,
+		Links: models.ExpiryNotificationLinks{Subscription: models.SerLinkType{
+			Href: fmt.Sprintf("%s/applications/%s/subscriptions/%s", apiPath, appInstanceId, subscriptionId)}},
+		ExpiryDeadline: *sub.ExpiryDeadline,
+	}
+	body, err := json.Marshal(&notification)
+	if err != nil {
+		log.Errorf(nil, "can not marshal the expiry notification")
+		return
+	}
+
+	log.Infof("Subscription(%s) of the app(%s) is about to expire", subscriptionId, appInstanceId)
+	enqueueNotification(appInstanceId, subscriptionId, sub.notificationUri(), body)
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package event
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"mepserver/common/models"
+	"mepserver/common/util"
+)
+
+var expiryNotifications = make(chan []byte, 10)
+
+func putTerminationSubscription(subscriptionId string, deadline time.Time) string {
+	key := util.EndAppSubKeyPath + outboxAppInstanceId + "/" + subscriptionId
+	sub := models.AppTerminationNotificationSubscription{SubscriptionId: subscriptionId,
+		SubscriptionType: util.AppTerminationNotificationSubscription, CallbackReference: outboxCallbackUri,
+		AppInstanceId:  outboxAppInstanceId,
+		ExpiryDeadline: &models.TimeStamp{Seconds: uint32(deadline.Unix())}}
+	outboxStore[key], _ = json.Marshal(&sub)
+	return key
+}
+
+func recordExpiryNotification(callbackUri string, body []byte) error {
+	expiryNotifications <- body
+	return nil
+}
+
+func TestSweepSubscriptions(t *testing.T) {
+	patches := patchOutboxStore()
+	defer patches.Reset()
+	deliverNotification = recordExpiryNotification
+	defer func() { deliverNotification = sendNotification }()
+
+	now := time.Now()
+	expiredKey := putTerminationSubscription("expired", now.Add(-time.Second))
+	expiringKey := putTerminationSubscription("expiring", now.Add(util.SubscriptionExpiryNoticeTime*time.Second/2))
+	validKey := putTerminationSubscription("valid", now.Add(2*util.SubscriptionExpiryNoticeTime*time.Second))
+
+	sweepSubscriptions(util.AppTerminationNotificationSubscription, now)
+	// Expiry notification is sent only once for a deadline
+	sweepSubscriptions(util.AppTerminationNotificationSubscription, now)
+
+	if _, found := outboxStore[expiredKey]; found {
+		t.Fatalf("expired subscription must be deleted")
+	}
+	if _, found := outboxStore[expiringKey]; !found {
+		t.Fatalf("subscription must be kept till the deadline")
+	}
+	if _, found := outboxStore[validKey]; !found {
+		t.Fatalf("valid subscription must be kept")
+	}
+
+	select {
+	case body := <-expiryNotifications:
+		notification := models.ExpiryNotification{}
+		_ = json.Unmarshal(body, &notification)
+		if notification.NotificationType != util.ExpiryNotification || notification.Links.Subscription.Href !=
+			"/mec_app_support/v1/applications/"+outboxAppInstanceId+"/subscriptions/expiring" {
+			t.Fatalf("unexpected expiry notification: %s", string(body))
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expiry notification not sent")
+	}
+	select {
+	case body := <-expiryNotifications:
+		t.Fatalf("unexpected notification: %s", string(body))
+	case <-time.After(10 * time.Millisecond):
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"golang.org/x/net/context"
 
 	"mepserver/common/arch/workspace"
+	meputil "mepserver/common/util"
 )
 
 type MepSpace struct {
@@ -41,7 +42,8 @@ type MepSpace struct {
 	DNSRuleId     string          `json:"dnsRuleId"`
 	TrafficRuleId string          `json:"trafficRuleId"`
 
-	QueryParam url.Values `json:"queryParam"`
+	QueryParam url.Values             `json:"queryParam"`
+	Filter     *meputil.ServiceFilter `json:"filter"`
 
 	CoreRequest interface{}     `json:"coreRequest"`
 	CoreRsp     interface{}     `json:"coreRsp"`
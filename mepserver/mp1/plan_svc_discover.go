@@ -30,16 +30,18 @@ import (
 	"github.com/apache/servicecomb-service-center/server/core"
 	"github.com/apache/servicecomb-service-center/server/core/proto"
 
+	"mepserver/common"
 	"mepserver/common/arch/workspace"
 	meputil "mepserver/common/util"
 )
 
 type DiscoverDecode struct {
 	workspace.TaskBase
-	R           *http.Request   `json:"r,in"`
-	Ctx         context.Context `json:"ctx,out"`
-	QueryParam  url.Values      `json:"queryParam,out"`
-	CoreRequest interface{}     `json:"coreRequest,out"`
+	R           *http.Request          `json:"r,in"`
+	Ctx         context.Context        `json:"ctx,out"`
+	QueryParam  url.Values             `json:"queryParam,out"`
+	CoreRequest interface{}            `json:"coreRequest,out"`
+	Filter      *meputil.ServiceFilter `json:"filter,out"`
 }
 
 // discover decode request
@@ -48,7 +50,7 @@ func (t *DiscoverDecode) OnRequest(data string) workspace.TaskCode {
 		meputil.GetClientIp(t.R), meputil.GetAppInstanceId(t.R), meputil.GetMethod(t.R), meputil.GetResourceInfo(t.R))
 	err := t.GetFindParam(t.R)
 	if err != nil {
-		log.Error("validate authorization error ", nil)
+		log.Error("discover request validation failed", err)
 	}
 	return workspace.TaskFinish
 }
@@ -61,11 +63,15 @@ func (t *DiscoverDecode) GetFindParam(r *http.Request) error {
 		t.SetFirstErrorCode(meputil.AuthorizationValidateErr, err.Error())
 		return err
 	}
+	filter, err := meputil.ServiceFilterFromQuery(query)
+	if err != nil {
+		t.SetFirstErrorCode(meputil.RequestParamErr, err.Error())
+		return err
+	}
 
 	req := &proto.FindInstancesRequest{
 		ConsumerServiceId: r.Header.Get("X-ConsumerId"),
 		AppId:             query.Get("instance_id"),
-		ServiceName:       query.Get("ser_name"),
 		VersionRule:       query.Get("version"),
 		Environment:       query.Get("env"),
 		Tags:              ids,
@@ -80,15 +86,26 @@ func (t *DiscoverDecode) GetFindParam(r *http.Request) error {
 	t.Ctx = util.SetTargetDomainProject(r.Context(), r.Header.Get("X-Domain-Name"), query.Get(":project"))
 	t.CoreRequest = req
 	t.QueryParam = query
+	t.Filter = filter
 	return nil
 }
 
 type DiscoverService struct {
 	workspace.TaskBase
-	Ctx         context.Context `json:"ctx,in"`
-	QueryParam  url.Values      `json:"queryParam,in"`
-	CoreRequest interface{}     `json:"coreRequest,in"`
-	CoreRsp     interface{}     `json:"coreRsp,out"`
+	R           *http.Request          `json:"r,in"`
+	Ctx         context.Context        `json:"ctx,in"`
+	QueryParam  url.Values             `json:"queryParam,in"`
+	CoreRequest interface{}            `json:"coreRequest,in"`
+	Filter      *meputil.ServiceFilter `json:"filter,in"`
+	CoreRsp     interface{}            `json:"coreRsp,out"`
+	paged       bool
+}
+
+// Read only the requested page of the instances from the data-store, the response is paged by the service
+// instance id
+func (t *DiscoverService) WithPaging() *DiscoverService {
+	t.paged = true
+	return t
 }
 
 func (t *DiscoverService) checkInstanceId(req *proto.FindInstancesRequest) bool {
@@ -133,7 +150,23 @@ func (t *DiscoverService) filterAppInstanceId() {
 	value.Instances = result
 }
 
-// service discover request
+// Keep the instances matching the MEC011 query parameters
+func (t *DiscoverService) filterInstances(filter *meputil.ServiceFilter) {
+	value, ok := t.CoreRsp.(*proto.FindInstancesResponse)
+	if !ok || filter == nil {
+		return
+	}
+
+	var result []*proto.MicroServiceInstance
+	for _, instance := range value.Instances {
+		if filter.Match(instance) {
+			result = append(result, instance)
+		}
+	}
+	value.Instances = result
+}
+
+// service discover request, the services selected by name are found along with the version and the environment
 func (t *DiscoverService) OnRequest(data string) workspace.TaskCode {
 	req, ok := t.CoreRequest.(*proto.FindInstancesRequest)
 	if !ok {
@@ -142,7 +175,11 @@ func (t *DiscoverService) OnRequest(data string) workspace.TaskCode {
 		return workspace.TaskFinish
 	}
 	log.Debugf("query request arrived to fetch all the service information with appId %s.", req.AppId)
-	if req.ServiceName == "" {
+	if limit, cursor := common.PageRange(t.R); t.paged && limit != 0 && t.Filter != nil &&
+		len(t.Filter.SerNames) == 0 && req.AppId == "default" {
+		return t.findPage(limit, cursor)
+	}
+	if t.Filter == nil || len(t.Filter.SerNames) == 0 {
 		var errFindByKey error
 		t.CoreRsp, errFindByKey = meputil.FindInstanceByKey(t.QueryParam)
 		if errFindByKey != nil {
@@ -159,22 +196,76 @@ func (t *DiscoverService) OnRequest(data string) workspace.TaskCode {
 			log.Error("instance id not found", nil)
 			t.SetFirstErrorCode(meputil.SerErrServiceNotFound, "instance id not found")
 		}
+		t.filterInstances(t.Filter)
 		t.filterAppInstanceId()
 		return workspace.TaskFinish
 	}
 
-	findInstance, err := core.InstanceAPI.Find(t.Ctx, req)
+	findInstance, err := t.findByServiceNames(req)
 	if err != nil {
 		log.Error("failed to find instance request", nil)
 		t.SetFirstErrorCode(meputil.SerErrServiceNotFound, "failed to find instance request")
 		return workspace.TaskFinish
 	}
 	t.CoreRsp = findInstance
+	// Services are already selected by the name
+	filter := *t.Filter
+	filter.SerNames = nil
+	t.filterInstances(&filter)
 	t.filterAppInstanceId()
 
 	return workspace.TaskFinish
 }
 
+// Instances of the page after the cursor, one more instance than the limit is read to know whether a next page
+// exists. Instance id and service names need all the instances, hence those are not read page by page.
+func (t *DiscoverService) findPage(limit int, cursor string) workspace.TaskCode {
+	appInstanceId := t.QueryParam.Get(":appInstanceId")
+	instances, err := meputil.FindInstancesPage(cursor, limit+1, func(instance *proto.MicroServiceInstance) bool {
+		return t.Filter.Match(instance) && (appInstanceId == "" || instance.Properties["appInstanceId"] == appInstanceId)
+	})
+	if err != nil {
+		log.Error("failed to find instance", nil)
+		t.SetFirstErrorCode(meputil.SerErrServiceNotFound, "failed to find the instance")
+		return workspace.TaskFinish
+	}
+	if len(instances) == 0 && len(cursor) == 0 {
+		log.Error("failed to find instance", nil)
+		t.SetFirstErrorCode(meputil.SerErrServiceNotFound, "failed to find the instance")
+		return workspace.TaskFinish
+	}
+	t.CoreRsp = &proto.FindInstancesResponse{Response: &proto.Response{Code: proto.Response_SUCCESS},
+		Instances: instances}
+	return workspace.TaskFinish
+}
+
+// Instances of each of the service names, a name without a service is left out unless no name has one
+func (t *DiscoverService) findByServiceNames(req *proto.FindInstancesRequest) (*proto.FindInstancesResponse, error) {
+	var found *proto.FindInstancesResponse
+	var notFound *proto.FindInstancesResponse
+	for _, serName := range t.Filter.SerNames {
+		nameReq := *req
+		nameReq.ServiceName = serName
+		findInstance, err := core.InstanceAPI.Find(t.Ctx, &nameReq)
+		if err != nil {
+			return nil, err
+		}
+		if findInstance.Response != nil && findInstance.Response.GetCode() != proto.Response_SUCCESS {
+			notFound = findInstance
+			continue
+		}
+		if found == nil {
+			found = findInstance
+			continue
+		}
+		found.Instances = append(found.Instances, findInstance.Instances...)
+	}
+	if found == nil {
+		return notFound, nil
+	}
+	return found, nil
+}
+
 type ToStrDiscover struct {
 	HttpErrInf *proto.Response `json:"httpErrInf,out"`
 	workspace.TaskBase
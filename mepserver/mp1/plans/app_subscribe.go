@@ -25,6 +25,8 @@ import (
 	"mepserver/common/models"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/apache/servicecomb-service-center/pkg/log"
 	scutil "github.com/apache/servicecomb-service-center/pkg/util"
@@ -64,6 +66,23 @@ func (t *SubscribeIst) OnRequest(data string) workspace.TaskCode {
 	if mp1SubscribeInfo == nil {
 		return workspace.TaskFinish
 	}
+	if !isValidExpiryDeadline(mp1SubscribeInfo) {
+		log.Error("expiry deadline is not in the future", nil)
+		t.SetFirstErrorCode(util.RequestParamErr, "expiry deadline must be in the future")
+		return workspace.TaskFinish
+	}
+	if !isValidFilteringCriteria(mp1SubscribeInfo) {
+		log.Error("filtering criteria has more than one service selection", nil)
+		t.SetFirstErrorCode(util.RequestParamErr, errMultipleSerSelections)
+		return workspace.TaskFinish
+	}
+
+	t.SubscribeId = uuid.NewV4().String()
+	if !assignWebsocketUri(t.R, mp1SubscribeInfo, strings.TrimSuffix(t.R.URL.Path, "/")+"/"+t.SubscribeId) {
+		log.Error("subscription has neither callback reference nor websocket", nil)
+		t.SetFirstErrorCode(util.RequestParamErr, errNoNotificationTarget)
+		return workspace.TaskFinish
+	}
 
 	subscribeJSON, err := json.Marshal(mp1SubscribeInfo)
 	if err != nil {
@@ -79,7 +98,6 @@ func (t *SubscribeIst) OnRequest(data string) workspace.TaskCode {
 		return workspace.TaskFinish
 	}
 	log.Debugf("request received for app subscription with appId %s", t.AppInstanceId)
-	t.SubscribeId = uuid.NewV4().String()
 	err = t.insertOrUpdateData(subscribeJSON)
 	if err != nil {
 		return workspace.TaskFinish
@@ -136,6 +154,61 @@ func isValidCallbackURI(reference string) bool {
 	return true
 }
 
+// Expiry deadline is optional, when present it must be in the future
+func isValidExpiryDeadline(sub interface{}) bool {
+	var deadline *models.TimeStamp
+	switch sub := sub.(type) {
+	case *models.SerAvailabilityNotificationSubscription:
+		deadline = sub.ExpiryDeadline
+	case *models.AppTerminationNotificationSubscription:
+		deadline = sub.ExpiryDeadline
+	}
+	return deadline == nil || deadline.Time().After(time.Now())
+}
+
+const errMultipleSerSelections = "serInstanceIds, serNames and serCategories are mutually exclusive"
+
+// Service instance ids, names and categories are alternatives to select the services, at most one can be given
+func isValidFilteringCriteria(sub interface{}) bool {
+	serAvl, ok := sub.(*models.SerAvailabilityNotificationSubscription)
+	if !ok {
+		return true
+	}
+	selections := 0
+	for _, count := range []int{len(serAvl.FilteringCriteria.SerInstanceIds), len(serAvl.FilteringCriteria.SerNames),
+		len(serAvl.FilteringCriteria.SerCategories)} {
+		if count != 0 {
+			selections++
+		}
+	}
+	return selections <= 1
+}
+
+const errNoNotificationTarget = "callbackReference or a websocket request is required"
+
+// Websocket uri of the subscription is assigned when the app requests the websocket delivery, false when the
+// subscription has no way to receive the notifications
+func assignWebsocketUri(r *http.Request, sub interface{}, subscriptionPath string) bool {
+	var config *models.WebsockNotifConfig
+	var callbackReference string
+	switch sub := sub.(type) {
+	case *models.SerAvailabilityNotificationSubscription:
+		config, callbackReference = sub.WebsockNotifConfig, sub.CallbackReference
+	case *models.AppTerminationNotificationSubscription:
+		config, callbackReference = sub.WebsockNotifConfig, sub.CallbackReference
+	}
+	if config == nil {
+		return len(callbackReference) != 0
+	}
+	// Websocket uri is always the one served by the mep-server
+	config.WebsocketUri = ""
+	if !config.RequestWebsocketUri {
+		return len(callbackReference) != 0
+	}
+	config.WebsocketUri = util.WebsocketUri(r, subscriptionPath)
+	return true
+}
+
 func (t *SubscribeIst) marshalError(appInstanceId string) workspace.TaskCode {
 	subKeyPath := util.GetSubscribeKeyPath(t.SubscribeType)
 	opts := []registry.PluginOp{
@@ -0,0 +1,59 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package path implements mep server api plans
+package plans
+
+import (
+	"net/http"
+
+	"github.com/apache/servicecomb-service-center/pkg/log"
+
+	"mepserver/common/arch/workspace"
+	"mepserver/common/extif/backend"
+	meputil "mepserver/common/util"
+	"mepserver/mp1/event"
+)
+
+// Query the notification delivery status of a service availability subscription along with its pending count
+type GetSubscriptionDeliveryStatus struct {
+	workspace.TaskBase
+	W             http.ResponseWriter `json:"w,in"`
+	AppInstanceId string              `json:"appInstanceId,in"`
+	SubscribeId   string              `json:"subscribeId,in"`
+	HttpRsp       interface{}         `json:"httpRsp,out"`
+}
+
+func (t *GetSubscriptionDeliveryStatus) OnRequest(data string) workspace.TaskCode {
+	if _, errCode := backend.GetRecord(meputil.AvailAppSubKeyPath + t.AppInstanceId + "/" +
+		t.SubscribeId); errCode != 0 {
+		log.Errorf(nil, "subscription doesn't exist")
+		t.SetFirstErrorCode(workspace.ErrCode(errCode), "subscription not exist")
+		return workspace.TaskFinish
+	}
+
+	status := event.GetDeliveryStatus(t.AppInstanceId, t.SubscribeId)
+	pending, errCode := backend.GetRecords(meputil.NotificationOutboxPath + t.AppInstanceId + "/" +
+		t.SubscribeId + "/")
+	if errCode != 0 {
+		log.Errorf(nil, "retrieve notification outbox from data-store failed")
+		t.SetFirstErrorCode(workspace.ErrCode(errCode), "notification outbox retrieval failed")
+		return workspace.TaskFinish
+	}
+	status.PendingCount = len(pending)
+	t.HttpRsp = status
+	return workspace.TaskFinish
+}
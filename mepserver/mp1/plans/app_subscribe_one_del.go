@@ -72,8 +72,12 @@ func (t *DelOneSubscribe) OnRequest(data string) workspace.TaskCode {
 		return workspace.TaskFinish
 	}
 
+	// Pending notifications and the delivery status are removed along with the subscription
 	opts = []registry.PluginOp{
 		registry.OpDel(registry.WithStrKey(appSubKeyPath)),
+		registry.OpDel(registry.WithStrKey(util.NotificationOutboxPath+appInstanceId+"/"+subscribeId+"/"),
+			registry.WithPrefix()),
+		registry.OpDel(registry.WithStrKey(util.NotificationStatusPath + appInstanceId + "/" + subscribeId)),
 	}
 	_, err := backend.Registry().TxnWithCmp(context.Background(), opts, nil, nil)
 	if err != nil {
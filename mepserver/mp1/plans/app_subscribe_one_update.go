@@ -0,0 +1,121 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package path implements mep server api plans
+package plans
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/apache/servicecomb-service-center/pkg/log"
+
+	"mepserver/common/arch/workspace"
+	"mepserver/common/extif/backend"
+	"mepserver/common/models"
+	"mepserver/common/util"
+)
+
+// Replace an existing subscription, the app extends the expiry deadline of its subscription with it
+type UpdateOneSubscribe struct {
+	workspace.TaskBase
+	R             *http.Request       `json:"r,in"`
+	W             http.ResponseWriter `json:"w,in"`
+	RestBody      interface{}         `json:"restBody,in"`
+	AppInstanceId string              `json:"appInstanceId,in"`
+	SubscribeId   string              `json:"subscribeId,in"`
+	HttpRsp       interface{}         `json:"httpRsp,out"`
+	SubscribeType string              `json:"subscribeType,out"`
+}
+
+// set type and return UpdateOneSubscribe
+func (t *UpdateOneSubscribe) WithType(subType string) *UpdateOneSubscribe {
+	t.SubscribeType = subType
+	return t
+}
+
+func (t *UpdateOneSubscribe) OnRequest(data string) workspace.TaskCode {
+	var callbackReference, subscriptionType string
+	apiPath := util.MecServicePath
+	switch sub := t.RestBody.(type) {
+	case *models.SerAvailabilityNotificationSubscription:
+		callbackReference, subscriptionType = sub.CallbackReference, sub.SubscriptionType
+	case *models.AppTerminationNotificationSubscription:
+		callbackReference, subscriptionType = sub.CallbackReference, sub.SubscriptionType
+		apiPath = util.MecAppSupportPath
+	default:
+		log.Error(util.ErrorRequestBodyMessage, nil)
+		t.SetFirstErrorCode(util.RequestParamErr, util.ErrorRequestBodyMessage)
+		return workspace.TaskFinish
+	}
+	if subscriptionType != t.SubscribeType {
+		log.Error("subscription type mismatch", nil)
+		t.SetFirstErrorCode(util.RequestParamErr, "subscription type mismatch")
+		return workspace.TaskFinish
+	}
+	if len(callbackReference) != 0 && !isValidCallbackURI(callbackReference) {
+		log.Error("Invalid CallbackReference uri", nil)
+		t.SetFirstErrorCode(util.RequestParamErr, "Invalid CallbackReference uri")
+		return workspace.TaskFinish
+	}
+	if !isValidExpiryDeadline(t.RestBody) {
+		log.Error("expiry deadline is not in the future", nil)
+		t.SetFirstErrorCode(util.RequestParamErr, "expiry deadline must be in the future")
+		return workspace.TaskFinish
+	}
+	if !isValidFilteringCriteria(t.RestBody) {
+		log.Error("filtering criteria has more than one service selection", nil)
+		t.SetFirstErrorCode(util.RequestParamErr, errMultipleSerSelections)
+		return workspace.TaskFinish
+	}
+
+	if !assignWebsocketUri(t.R, t.RestBody, t.R.URL.Path) {
+		log.Error("subscription has neither callback reference nor websocket", nil)
+		t.SetFirstErrorCode(util.RequestParamErr, errNoNotificationTarget)
+		return workspace.TaskFinish
+	}
+
+	subKeyPath := util.GetSubscribeKeyPath(t.SubscribeType) + t.AppInstanceId + "/" + t.SubscribeId
+	if _, errCode := backend.GetRecord(subKeyPath); errCode != 0 {
+		log.Errorf(nil, "subscription doesn't exist")
+		t.SetFirstErrorCode(workspace.ErrCode(errCode), "subscription not exist")
+		return workspace.TaskFinish
+	}
+
+	links := models.Links{Self: models.Self{Href: fmt.Sprintf("%s/applications/%s/subscriptions/%s", apiPath,
+		t.AppInstanceId, t.SubscribeId)}}
+	switch sub := t.RestBody.(type) {
+	case *models.SerAvailabilityNotificationSubscription:
+		sub.SubscriptionId, sub.Links = t.SubscribeId, links
+	case *models.AppTerminationNotificationSubscription:
+		sub.SubscriptionId, sub.Links = t.SubscribeId, links
+	}
+	subscribeJSON, err := json.Marshal(t.RestBody)
+	if err != nil {
+		log.Errorf(nil, "can not marshal subscribe info")
+		t.SetFirstErrorCode(util.ParseInfoErr, "marshal subscribe info error")
+		return workspace.TaskFinish
+	}
+	if errCode := backend.PutRecord(subKeyPath, subscribeJSON); errCode != 0 {
+		log.Errorf(nil, "subscription update failed")
+		t.SetFirstErrorCode(workspace.ErrCode(errCode), "put subscription to etcd failed")
+		return workspace.TaskFinish
+	}
+	log.Debugf("App subscription with appId %s and subscriptionId %s is updated.", t.AppInstanceId, t.SubscribeId)
+	t.HttpRsp = t.RestBody
+	return workspace.TaskFinish
+}
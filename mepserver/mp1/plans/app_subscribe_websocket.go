@@ -0,0 +1,104 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package path implements mep server api plans
+package plans
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/apache/servicecomb-service-center/pkg/log"
+
+	"mepserver/common"
+	"mepserver/common/arch/workspace"
+	"mepserver/common/extif/backend"
+	"mepserver/common/models"
+	meputil "mepserver/common/util"
+	"mepserver/mp1/event"
+)
+
+// Check the subscription requested the websocket delivery of its notifications
+type CheckSubscriptionWebsocket struct {
+	workspace.TaskBase
+	AppInstanceId string `json:"appInstanceId,in"`
+	SubscribeId   string `json:"subscribeId,in"`
+	SubscribeType string `json:"subscribeType,out"`
+}
+
+// set type and return CheckSubscriptionWebsocket
+func (t *CheckSubscriptionWebsocket) WithType(subType string) *CheckSubscriptionWebsocket {
+	t.SubscribeType = subType
+	return t
+}
+
+func (t *CheckSubscriptionWebsocket) OnRequest(data string) workspace.TaskCode {
+	subBytes, errCode := backend.GetRecord(meputil.GetSubscribeKeyPath(t.SubscribeType) + t.AppInstanceId + "/" +
+		t.SubscribeId)
+	if errCode != 0 {
+		log.Errorf(nil, "subscription doesn't exist")
+		t.SetFirstErrorCode(workspace.ErrCode(errCode), "subscription not exist")
+		return workspace.TaskFinish
+	}
+	// Websocket config is same in both the subscription types
+	sub := &models.AppTerminationNotificationSubscription{}
+	if err := json.Unmarshal(subBytes, sub); err != nil {
+		log.Errorf(nil, "subscription parse failed")
+		t.SetFirstErrorCode(meputil.ParseInfoErr, "subscription parse failed")
+		return workspace.TaskFinish
+	}
+	if !meputil.IsWebsocketUri(sub.NotificationUri()) {
+		log.Errorf(nil, "subscription(%s) didn't request a websocket", t.SubscribeId)
+		t.SetFirstErrorCode(meputil.RequestParamErr, "subscription didn't request a websocket")
+	}
+	return workspace.TaskFinish
+}
+
+// Upgrade to the notification websocket of the subscription and serve it till the app disconnects, the pending
+// service availability notifications are delivered once connected. Errors are responded as usual.
+type ServeSubscriptionWebsocket struct {
+	workspace.TaskBase
+	R             *http.Request       `json:"r,in"`
+	W             http.ResponseWriter `json:"w,in"`
+	AppInstanceId string              `json:"appInstanceId,in"`
+	SubscribeId   string              `json:"subscribeId,in"`
+	SubscribeType string
+}
+
+// set type and return ServeSubscriptionWebsocket
+func (t *ServeSubscriptionWebsocket) WithType(subType string) *ServeSubscriptionWebsocket {
+	t.SubscribeType = subType
+	return t
+}
+
+func (t *ServeSubscriptionWebsocket) OnRequest(data string) workspace.TaskCode {
+	if errInfo := t.GetSerErrInfo(); errInfo == nil || errInfo.ErrCode >= int(workspace.TaskFail) {
+		rsp := &common.SendHttpRsp{R: t.R, W: t.W}
+		rsp.SetSerErrInfo(errInfo)
+		return rsp.OnRequest(data)
+	}
+
+	err := meputil.ServeWebsocket(t.W, t.R, func() {
+		if t.SubscribeType == meputil.SerAvailabilityNotificationSubscription {
+			event.ResumeNotificationDelivery(t.AppInstanceId, t.SubscribeId)
+		}
+	})
+	if err != nil {
+		// Upgrader has already responded the failure
+		log.Errorf(err, "notification websocket upgrade failed(subscription-id: %s)", t.SubscribeId)
+	}
+	return workspace.TaskFinish
+}
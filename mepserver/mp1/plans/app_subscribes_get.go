@@ -30,6 +30,7 @@ import (
 	"github.com/apache/servicecomb-service-center/server/core/proto"
 	"github.com/apache/servicecomb-service-center/server/plugin/pkg/registry"
 
+	"mepserver/common"
 	"mepserver/common/arch/workspace"
 	"mepserver/common/util"
 )
@@ -43,6 +44,13 @@ type GetSubscribes struct {
 	AppInstanceId string              `json:"appInstanceId,in"`
 	HttpRsp       interface{}         `json:"httpRsp,out"`
 	SubscribeType string              `json:"subscribeType,out"`
+	paged         bool
+}
+
+// Read only the requested page of the subscriptions from the data-store, the response is paged by the href
+func (t *GetSubscribes) WithPaging() *GetSubscribes {
+	t.paged = true
+	return t
 }
 
 // set type and return GetSubscribes
@@ -59,17 +67,29 @@ func (t *GetSubscribes) OnRequest(data string) workspace.TaskCode {
 	appInstanceId := t.AppInstanceId
 	log.Debugf("Query request arrived to fetch all the %s information for appId %s.", t.SubscribeType, appInstanceId)
 
-	opts := []registry.PluginOp{
-		registry.OpGet(registry.WithStrKey(subscribeKeyPath+appInstanceId), registry.WithPrefix()),
+	var resp *registry.PluginResponse
+	var err error
+	limit, cursor := common.PageRange(t.R)
+	if t.paged && limit != 0 {
+		// One more subscription than the limit is read to know whether a next page exists
+		afterSubscriptionId := ""
+		if len(cursor) != 0 {
+			afterSubscriptionId = path.Base(cursor)
+		}
+		resp, err = getSubscriptionPage(subscribeKeyPath+appInstanceId+"/", afterSubscriptionId, limit+1)
+	} else {
+		opts := []registry.PluginOp{
+			registry.OpGet(registry.WithStrKey(subscribeKeyPath+appInstanceId), registry.WithPrefix()),
+		}
+		resp, err = backend.Registry().TxnWithCmp(context.Background(), opts, nil, nil)
 	}
-	resp, err := backend.Registry().TxnWithCmp(context.Background(), opts, nil, nil)
 	if err != nil {
 		log.Errorf(nil, "get subscription from etcd failed")
 		t.SetFirstErrorCode(util.OperateDataWithEtcdErr, "get subscription from etcd failed")
 		return workspace.TaskFinish
 	}
 
-	var subs []models.Subscription
+	subs := make([]models.Subscription, 0, len(resp.Kvs))
 	selfPath := t.R.URL.Path[len(util.RootPath):]
 	for _, value := range resp.Kvs {
 		u, err := url.Parse(string(value.Key))
@@ -82,7 +102,8 @@ func (t *GetSubscribes) OnRequest(data string) workspace.TaskCode {
 		href := selfPath + "/" + subId
 		subs = append(subs, models.Subscription{Href: href, Rel: t.SubscribeType})
 	}
-	if len(subs) == 0 {
+	// Page after the last subscription is empty
+	if len(subs) == 0 && (!t.paged || len(cursor) == 0) {
 		log.Errorf(nil, "get subscription failed, subscription not exist")
 		t.SetFirstErrorCode(util.SubscriptionNotFound, "get subscription failed, subscription not exist")
 		return workspace.TaskFinish
@@ -102,3 +123,14 @@ func (t *GetSubscribes) OnRequest(data string) workspace.TaskCode {
 
 	return workspace.TaskFinish
 }
+
+// Subscriptions under the key path after the given subscription id, in the key order
+func getSubscriptionPage(keyPath, afterSubscriptionId string, limit int) (*registry.PluginResponse, error) {
+	start := keyPath
+	if len(afterSubscriptionId) != 0 {
+		start = keyPath + afterSubscriptionId + "\x00"
+	}
+	return backend.Registry().Do(context.Background(), registry.GET, registry.WithStrKey(start),
+		registry.WithStrEndKey(util.PrefixRangeEnd(keyPath)), registry.WithAscendOrder(),
+		registry.WithLimit(int64(limit)))
+}
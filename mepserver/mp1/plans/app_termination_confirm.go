@@ -0,0 +1,88 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package path implements mep server api plans
+package plans
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/apache/servicecomb-service-center/pkg/log"
+
+	"mepserver/common/arch/workspace"
+	"mepserver/common/extif/backend"
+	"mepserver/common/models"
+	meputil "mepserver/common/util"
+)
+
+// Acknowledge the termination notification so that the mep proceeds with the termination before the graceful
+// timeout expires
+type ConfirmTermination struct {
+	workspace.TaskBase
+	W             http.ResponseWriter `json:"w,in"`
+	AppInstanceId string              `json:"appInstanceId,in"`
+	RestBody      interface{}         `json:"restBody,in"`
+	HttpRsp       interface{}         `json:"httpRsp,out"`
+}
+
+func (t *ConfirmTermination) OnRequest(data string) workspace.TaskCode {
+	confirmation, ok := t.RestBody.(*models.AppTerminationConfirmation)
+	if !ok {
+		t.SetFirstErrorCode(meputil.ParseInfoErr, "input body parse failed")
+		return workspace.TaskFinish
+	}
+
+	statusPath := meputil.AppTerminationKeyPath + t.AppInstanceId
+	statusEntry, revision, errCode := backend.GetRecordWithRevision(statusPath)
+	if errCode != 0 {
+		log.Errorf(nil, "no termination in progress for the app(%s)", t.AppInstanceId)
+		t.SetFirstErrorCode(meputil.TerminationNotInProgressErr, "termination not in progress")
+		return workspace.TaskFinish
+	}
+	status := &models.AppTerminationStatus{}
+	if err := json.Unmarshal(statusEntry, status); err != nil {
+		log.Errorf(nil, "failed to parse the termination status from data-store")
+		t.SetFirstErrorCode(meputil.OperateDataWithEtcdErr, "parse termination status failed")
+		return workspace.TaskFinish
+	}
+	if status.OperationAction != confirmation.OperationAction {
+		log.Errorf(nil, "operation action mismatch in the termination confirmation")
+		t.SetFirstErrorCode(meputil.RequestParamErr, "operation action mismatch")
+		return workspace.TaskFinish
+	}
+
+	status.Confirmed = true
+	statusBytes, err := json.Marshal(status)
+	if err != nil {
+		log.Errorf(nil, "can not marshal termination status")
+		t.SetFirstErrorCode(meputil.ParseInfoErr, "marshal termination status failed")
+		return workspace.TaskFinish
+	}
+	// Termination may finish and remove the status meanwhile, the status must not be written back then
+	if errCode = backend.PutRecordIfRevision(statusPath, statusBytes, revision); errCode == meputil.RecordModifiedErr {
+		log.Errorf(nil, "termination status of the app(%s) changed during the confirmation", t.AppInstanceId)
+		t.SetFirstErrorCode(meputil.TerminationNotInProgressErr, "termination not in progress")
+		return workspace.TaskFinish
+	} else if errCode != 0 {
+		log.Errorf(nil, "termination status write failed")
+		t.SetFirstErrorCode(workspace.ErrCode(errCode), "termination status write failed")
+		return workspace.TaskFinish
+	}
+	log.Infof("Termination confirmed by the app(%s)", t.AppInstanceId)
+	t.HttpRsp = ""
+	return workspace.TaskFinish
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plans
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/apache/servicecomb-service-center/pkg/log"
+	"github.com/go-playground/validator/v10"
+
+	"mepserver/common"
+	"mepserver/common/arch/workspace"
+	"mepserver/common/extif/dataplane"
+	"mepserver/common/extif/dns"
+	meputil "mepserver/common/util"
+)
+
+type DNSRuleCreate struct {
+	workspace.TaskBase
+	R             *http.Request       `json:"r,in"`
+	W             http.ResponseWriter `json:"w,in"`
+	RestBody      interface{}         `json:"restBody,in"`
+	AppInstanceId string              `json:"appInstanceId,in"`
+	HttpRsp       interface{}         `json:"httpRsp,out"`
+	dnsAgent      dns.DNSAgent
+	dnsType       string
+	dataPlane     dataplane.DataPlane
+}
+
+// Dns agent of the configured dns agent type, data-plane type has no agent
+func (t *DNSRuleCreate) WithDNSAgent(dnsAgent dns.DNSAgent, dnsType string) *DNSRuleCreate {
+	t.dnsAgent = dnsAgent
+	t.dnsType = dnsType
+	return t
+}
+
+func (t *DNSRuleCreate) WithDataPlane(dataPlane dataplane.DataPlane) *DNSRuleCreate {
+	t.dataPlane = dataPlane
+	return t
+}
+
+// Create a dns rule of the app, allowed only when its AppD config enables the rule management over mp1
+func (t *DNSRuleCreate) OnRequest(data string) workspace.TaskCode {
+	dnsConfigInPut, ok := t.RestBody.(*dataplane.DNSRule)
+	if !ok {
+		t.SetFirstErrorCode(meputil.ParseInfoErr, "input parsing failed")
+		return workspace.TaskFinish
+	}
+	if len(dnsConfigInPut.State) == 0 {
+		dnsConfigInPut.State = meputil.ActiveState
+	}
+	if err := validator.New().Struct(dnsConfigInPut); err != nil {
+		log.Errorf(err, "dns rule validation failed on create request")
+		t.SetFirstErrorCode(meputil.RequestParamErr, "dns rule validation failed")
+		return workspace.TaskFinish
+	}
+
+	appDConfig, appDConfigEntry, revision, errCode, errString := getAppDConfigForRuleMgmt(t.AppInstanceId)
+	if errCode != 0 {
+		t.SetFirstErrorCode(errCode, errString)
+		return workspace.TaskFinish
+	}
+	for _, rule := range appDConfig.AppDNSRule {
+		if rule.DNSRuleID == dnsConfigInPut.DNSRuleID {
+			log.Errorf(nil, "dns rule(%s) already exists", dnsConfigInPut.DNSRuleID)
+			t.SetFirstErrorCode(meputil.ResourceExists, "dns rule already exists")
+			return workspace.TaskFinish
+		}
+	}
+	if isDomainNameInUse(t.AppInstanceId, dnsConfigInPut.DomainName, appDConfig) {
+		log.Errorf(nil, "duplicate dns entry found in the request")
+		t.SetFirstErrorCode(meputil.DuplicateOperation, "duplicate dns entry")
+		return workspace.TaskFinish
+	}
+
+	appDConfig.AppDNSRule = append(appDConfig.AppDNSRule, *dnsConfigInPut)
+	if errCode, errString = putAppDConfigForRuleMgmt(t.AppInstanceId, appDConfig, revision); errCode != 0 {
+		t.SetFirstErrorCode(errCode, errString)
+		return workspace.TaskFinish
+	}
+
+	if dnsConfigInPut.State == meputil.ActiveState {
+		appInfo := dataplane.ApplicationInfo{
+			ApplicationId:   t.AppInstanceId,
+			ApplicationName: appDConfig.AppName,
+		}
+		if err := applyDnsRuleState(t.dnsAgent, t.dnsType, t.dataPlane, appInfo, dnsConfigInPut,
+			meputil.ActiveState); err != nil {
+			log.Errorf(err, "dns rule(app-id: %s, dns-rule-id: %s) create fail on dns server or data-plane!",
+				t.AppInstanceId, dnsConfigInPut.DNSRuleID)
+			revertAppDConfigForRuleMgmt(t.AppInstanceId, appDConfigEntry, appDConfig)
+			t.SetFirstErrorCode(meputil.RemoteServerErr, "failed to apply the dns modification")
+			return workspace.TaskFinish
+		}
+	}
+
+	common.SaveAppDConfigVersion(t.AppInstanceId, "", appDConfig)
+
+	location := fmt.Sprintf("%s/applications/%s/dns_rules/%s", meputil.MecAppSupportPath, t.AppInstanceId,
+		dnsConfigInPut.DNSRuleID)
+	t.W.Header().Set("Location", location)
+	if ruleBytes, err := json.Marshal(dnsConfigInPut); err == nil {
+		t.W.Header().Set("ETag", meputil.GenerateStrongETag(ruleBytes))
+	}
+	t.HttpRsp = dnsConfigInPut
+	return workspace.TaskFinish
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plans
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/apache/servicecomb-service-center/pkg/log"
+
+	"mepserver/common"
+	"mepserver/common/arch/workspace"
+	"mepserver/common/extif/dataplane"
+	"mepserver/common/extif/dns"
+	meputil "mepserver/common/util"
+)
+
+type DNSRuleDelete struct {
+	workspace.TaskBase
+	R             *http.Request `json:"r,in"`
+	AppInstanceId string        `json:"appInstanceId,in"`
+	DNSRuleId     string        `json:"dnsRuleId,in"`
+	HttpRsp       interface{}   `json:"httpRsp,out"`
+	dnsAgent      dns.DNSAgent
+	dnsType       string
+	dataPlane     dataplane.DataPlane
+}
+
+// Dns agent of the configured dns agent type, data-plane type has no agent
+func (t *DNSRuleDelete) WithDNSAgent(dnsAgent dns.DNSAgent, dnsType string) *DNSRuleDelete {
+	t.dnsAgent = dnsAgent
+	t.dnsType = dnsType
+	return t
+}
+
+func (t *DNSRuleDelete) WithDataPlane(dataPlane dataplane.DataPlane) *DNSRuleDelete {
+	t.dataPlane = dataPlane
+	return t
+}
+
+// Delete a dns rule of the app, allowed only when its AppD config enables the rule management over mp1
+func (t *DNSRuleDelete) OnRequest(data string) workspace.TaskCode {
+	log.Debugf("delete request arrived for dns rule %s and appId %s.", t.DNSRuleId, t.AppInstanceId)
+
+	appDConfig, appDConfigEntry, revision, errCode, errString := getAppDConfigForRuleMgmt(t.AppInstanceId)
+	if errCode != 0 {
+		t.SetFirstErrorCode(errCode, errString)
+		return workspace.TaskFinish
+	}
+
+	ruleIndex := -1
+	for i, rule := range appDConfig.AppDNSRule {
+		if rule.DNSRuleID == t.DNSRuleId {
+			ruleIndex = i
+			break
+		}
+	}
+	if ruleIndex == -1 {
+		log.Error("Requested dns rule id doesn't exists.", nil)
+		t.SetFirstErrorCode(meputil.SubscriptionNotFound, "dns rule retrieval failed")
+		return workspace.TaskFinish
+	}
+	dnsOnStore := appDConfig.AppDNSRule[ruleIndex]
+
+	dataOnStoreBytes, err := json.Marshal(&dnsOnStore)
+	if err != nil {
+		log.Errorf(err, "failed to parse the dns entry from data-store on delete request")
+		t.SetFirstErrorCode(meputil.OperateDataWithEtcdErr, "parse dns rules failed")
+		return workspace.TaskFinish
+	}
+	// Check for E-Tags precondition. More details could be found here: https://tools.ietf.org/html/rfc7232#section-2.3
+	ifMatchTag := t.R.Header.Get("If-Match")
+	if len(ifMatchTag) != 0 && ifMatchTag != meputil.GenerateStrongETag(dataOnStoreBytes) {
+		t.SetFirstErrorCode(meputil.EtagMissMatchErr, "e-tag miss-match")
+		return workspace.TaskFinish
+	}
+
+	appDConfig.AppDNSRule = append(appDConfig.AppDNSRule[:ruleIndex], appDConfig.AppDNSRule[ruleIndex+1:]...)
+	if errCode, errString = putAppDConfigForRuleMgmt(t.AppInstanceId, appDConfig, revision); errCode != 0 {
+		t.SetFirstErrorCode(errCode, errString)
+		return workspace.TaskFinish
+	}
+
+	// Rules without state are programmed as active, inactive rules are neither on the dns server nor on the data-plane
+	if dnsOnStore.State != meputil.InactiveState {
+		appInfo := dataplane.ApplicationInfo{
+			ApplicationId:   t.AppInstanceId,
+			ApplicationName: appDConfig.AppName,
+		}
+		if err = applyDnsRuleState(t.dnsAgent, t.dnsType, t.dataPlane, appInfo, &dnsOnStore,
+			meputil.InactiveState); err != nil {
+			log.Errorf(err, "dns rule(app-id: %s, dns-rule-id: %s) delete fail on dns server or data-plane!",
+				t.AppInstanceId, t.DNSRuleId)
+			revertAppDConfigForRuleMgmt(t.AppInstanceId, appDConfigEntry, appDConfig)
+			t.SetFirstErrorCode(meputil.RemoteServerErr, "failed to apply the dns modification")
+			return workspace.TaskFinish
+		}
+	}
+	common.SaveAppDConfigVersion(t.AppInstanceId, "", appDConfig)
+	t.HttpRsp = ""
+	return workspace.TaskFinish
+}
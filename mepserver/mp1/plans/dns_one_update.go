@@ -33,6 +33,7 @@ import (
 	"mepserver/common/extif/backend"
 	"mepserver/common/extif/dns"
 
+	"mepserver/common"
 	"mepserver/common/arch/workspace"
 	meputil "mepserver/common/util"
 )
@@ -158,12 +159,15 @@ type DNSRuleUpdate struct {
 	DNSRuleId     string              `json:"dnsRuleId,in"`
 	HttpRsp       interface{}         `json:"httpRsp,out"`
 	dnsAgent      dns.DNSAgent
+	dnsType       string
 	dataPlane     dataplane.DataPlane
 	AppName       string
 }
 
-func (t *DNSRuleUpdate) WithDNSAgent(dnsAgent dns.DNSAgent) *DNSRuleUpdate {
+// Dns agent of the configured dns agent type, data-plane type has no agent
+func (t *DNSRuleUpdate) WithDNSAgent(dnsAgent dns.DNSAgent, dnsType string) *DNSRuleUpdate {
 	t.dnsAgent = dnsAgent
+	t.dnsType = dnsType
 	return t
 }
 
@@ -290,43 +294,15 @@ func (t *DNSRuleUpdate) updateDnsRecordToRemoteServer(appDConfig models.AppDConf
 		return errCode, errString
 	}
 
-	rrType := meputil.RRTypeA
-	if dnsOnStore.IPAddressType == meputil.IPv6Type {
-		rrType = meputil.RRTypeAAAA
-	}
-
-	// Update the DNS server as per the new configurations
-	if dnsConfigInPut.State == meputil.ActiveState {
-		err = t.dnsAgent.SetResourceRecordTypeA(dnsOnStore.DomainName, rrType, meputil.RRClassIN,
-			[]string{dnsOnStore.IPAddress}, dnsOnStore.TTL)
-	} else {
-		err = t.dnsAgent.DeleteResourceRecordTypeA(dnsOnStore.DomainName, rrType)
-	}
-	if err != nil {
-		log.Errorf(err, "dns rule(app-id: %s, dns-rule-id: %s) update fail on dns server!",
-			t.AppInstanceId, t.DNSRuleId)
-
-		// Revert the update in the data store in failure case
-		appDConfig.AppDNSRule[ruleIndex].State = oldState
-		errCode, _ := t.updateDnsRecordOnDataStore(appDConfig)
-		if errCode != 0 {
-			log.Errorf(nil, "failed to revert dns rule(app-id: %s, dns-rule-id: %s) update on data-store, "+
-				"this might lead to inconsistency!", t.AppInstanceId, t.DNSRuleId)
-		}
-
-		return meputil.RemoteServerErr, "failed to apply the dns modification"
-	}
-
 	appInfo := dataplane.ApplicationInfo{
 		ApplicationId:   t.AppInstanceId,
 		ApplicationName: t.AppName,
 	}
-
-	err = t.updateDNSToDataPlane(dnsConfigInPut, dnsOnStore, appInfo, rrType)
-
+	err = applyDnsRuleState(t.dnsAgent, t.dnsType, t.dataPlane, appInfo, dnsOnStore, dnsConfigInPut.State)
 	if err != nil {
-		log.Errorf(err, "dns rule(app-id: %s, dns-rule-id: %s) update fail on data-plane!",
+		log.Errorf(err, "dns rule(app-id: %s, dns-rule-id: %s) update fail on dns server or data-plane!",
 			t.AppInstanceId, t.DNSRuleId)
+
 		// Revert the update in the data store in failure case
 		appDConfig.AppDNSRule[ruleIndex].State = oldState
 		errCode, _ := t.updateDnsRecordOnDataStore(appDConfig)
@@ -337,6 +313,7 @@ func (t *DNSRuleUpdate) updateDnsRecordToRemoteServer(appDConfig models.AppDConf
 
 		return meputil.RemoteServerErr, "failed to apply the dns modification"
 	}
+	common.SaveAppDConfigVersion(t.AppInstanceId, "", &appDConfig)
 
 	// State updated on dnsOnStore, so regenerate the byte array
 	dataOnStoreBytes, err = json.Marshal(dnsOnStore)
@@ -363,29 +340,54 @@ func (t *DNSRuleUpdate) updateDnsRecordOnDataStore(appDConfig models.AppDConfig)
 	return 0, ""
 }
 
-func (t *DNSRuleUpdate) updateDNSToDataPlane(dnsConfigInput *dataplane.DNSRule, dnsOnStore *dataplane.DNSRule,
-	appInfo dataplane.ApplicationInfo, rrType string) error {
+// Apply the state of the dns rule on the dns server and then on the data-plane, the dns server change is reverted
+// when the data-plane fails. Data-plane dns agent type has no dns server, the rule is only on the data-plane.
+func applyDnsRuleState(dnsAgent dns.DNSAgent, dnsType string, dataPlane dataplane.DataPlane,
+	appInfo dataplane.ApplicationInfo, dnsRule *dataplane.DNSRule, state string) error {
+	if dnsType == meputil.DnsAgentTypeDataPlane {
+		return updateDNSToDataPlane(dataPlane, appInfo, dnsRule, state)
+	}
+
+	rrType := meputil.RRTypeA
+	if dnsRule.IPAddressType == meputil.IPv6Type {
+		rrType = meputil.RRTypeAAAA
+	}
+
+	// Update the DNS server as per the new configurations
 	var err error
-	if dnsConfigInput.State == meputil.ActiveState {
-		err = t.dataPlane.AddDNSRule(appInfo, t.DNSRuleId, dnsOnStore.DomainName,
-			dnsOnStore.IPAddressType, dnsOnStore.IPAddress, dnsOnStore.TTL)
-		if err != nil {
-			if err1 := t.dnsAgent.DeleteResourceRecordTypeA(dnsOnStore.DomainName, rrType); err1 != nil {
-				log.Errorf(err1, "Failed to revert the configuration(oper: delete, app-id: %s, "+
-					"dns-rule-id: %s) from dns-server, this might lead to data inconsistency!",
-					t.AppInstanceId, t.DNSRuleId)
-			}
-		}
+	if state == meputil.ActiveState {
+		err = dnsAgent.SetResourceRecordTypeA(dnsRule.DomainName, rrType, meputil.RRClassIN,
+			[]string{dnsRule.IPAddress}, dnsRule.TTL)
 	} else {
-		err = t.dataPlane.DeleteDNSRule(appInfo, t.DNSRuleId)
-		if err != nil {
-			if err1 := t.dnsAgent.SetResourceRecordTypeA(dnsOnStore.DomainName, rrType, meputil.RRClassIN,
-				[]string{dnsOnStore.IPAddress}, dnsOnStore.TTL); err1 != nil {
-				log.Errorf(err1, "Failed to revert the configuration(oper: create, app-id: %s, "+
-					"dns-rule-id: %s) from dns-server, this might lead to data inconsistency!",
-					t.AppInstanceId, t.DNSRuleId)
-			}
-		}
+		err = dnsAgent.DeleteResourceRecordTypeA(dnsRule.DomainName, rrType)
+	}
+	if err != nil {
+		return err
+	}
+	if err = updateDNSToDataPlane(dataPlane, appInfo, dnsRule, state); err == nil {
+		return nil
+	}
+
+	// Revert the dns server to the previous state
+	var err1 error
+	if state == meputil.ActiveState {
+		err1 = dnsAgent.DeleteResourceRecordTypeA(dnsRule.DomainName, rrType)
+	} else {
+		err1 = dnsAgent.SetResourceRecordTypeA(dnsRule.DomainName, rrType, meputil.RRClassIN,
+			[]string{dnsRule.IPAddress}, dnsRule.TTL)
+	}
+	if err1 != nil {
+		log.Errorf(err1, "Failed to revert the configuration(app-id: %s, dns-rule-id: %s) on dns-server, "+
+			"this might lead to data inconsistency!", appInfo.ApplicationId, dnsRule.DNSRuleID)
 	}
 	return err
 }
+
+func updateDNSToDataPlane(dataPlane dataplane.DataPlane, appInfo dataplane.ApplicationInfo,
+	dnsRule *dataplane.DNSRule, state string) error {
+	if state == meputil.ActiveState {
+		return dataPlane.AddDNSRule(appInfo, dnsRule.DNSRuleID, dnsRule.DomainName,
+			dnsRule.IPAddressType, dnsRule.IPAddress, dnsRule.TTL)
+	}
+	return dataPlane.DeleteDNSRule(appInfo, dnsRule.DNSRuleID)
+}
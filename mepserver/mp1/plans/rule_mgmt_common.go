@@ -0,0 +1,141 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package path implements mep server api plans
+package plans
+
+import (
+	"encoding/json"
+
+	"github.com/apache/servicecomb-service-center/pkg/log"
+	"github.com/go-playground/validator/v10"
+
+	"mepserver/common/arch/workspace"
+	"mepserver/common/extif/backend"
+	"mepserver/common/models"
+	meputil "mepserver/common/util"
+)
+
+// AppD config of the app for a rule create or delete over mp1, allowed only when the AppD config enables it and no
+// mm5 operation is in progress for the app. The stored bytes are returned to revert on failure, and the revision to
+// write the modified config only if no other request modified it meanwhile.
+func getAppDConfigForRuleMgmt(appInstanceId string) (*models.AppDConfig, []byte, int64, workspace.ErrCode, string) {
+	appDConfigEntry, revision, errCode := backend.GetRecordWithRevision(meputil.AppDConfigKeyPath + appInstanceId)
+	if errCode != 0 {
+		log.Errorf(nil, "app config(appId: %s) retrieval from data-store failed", appInstanceId)
+		return nil, nil, 0, meputil.SerInstanceNotFound, "app config not found"
+	}
+	appDConfig := &models.AppDConfig{}
+	if err := json.Unmarshal(appDConfigEntry, appDConfig); err != nil {
+		log.Errorf(err, "failed to parse the appd config from data-store")
+		return nil, nil, 0, meputil.OperateDataWithEtcdErr, "parse appd config from data-store failed"
+	}
+	if !appDConfig.Mp1RuleMgmt {
+		log.Errorf(nil, "rule management over mp1 is not enabled for the app(%s)", appInstanceId)
+		return nil, nil, 0, meputil.ForbiddenOperation, "rule management over mp1 is not enabled for the app"
+	}
+	if isRuleMgmtJobInProgress(appInstanceId) {
+		log.Errorf(nil, "app instance has other operation in progress")
+		return nil, nil, 0, meputil.ForbiddenOperation, "app instance has other operation in progress"
+	}
+	return appDConfig, appDConfigEntry, revision, 0, ""
+}
+
+// Mm5 operation in progress for the app, rules are not managed over mp1 meanwhile
+func isRuleMgmtJobInProgress(appInstanceId string) bool {
+	records, errCode := backend.GetRecords(meputil.AppDLCMJobsPath + appInstanceId)
+	return errCode == 0 && len(records) != 0
+}
+
+// Store the AppD config modified by a rule create or delete, only if it is not modified since the given revision and
+// no mm5 operation started for the app meanwhile
+func putAppDConfigForRuleMgmt(appInstanceId string, appDConfig *models.AppDConfig,
+	revision int64) (workspace.ErrCode, string) {
+	if err := validator.New().Struct(appDConfig); err != nil {
+		log.Errorf(err, "appD config validation failed after the rule change")
+		return meputil.RequestParamErr, "invalid appD config after the rule change"
+	}
+	appDConfigBytes, err := json.Marshal(appDConfig)
+	if err != nil {
+		log.Errorf(err, "can not marshal appDConfig info")
+		return meputil.ParseInfoErr, "can not marshal appDConfig info"
+	}
+	errCode := backend.PutRecordIfRevisionAndAbsent(meputil.AppDConfigKeyPath+appInstanceId, appDConfigBytes,
+		revision, meputil.AppDLCMJobsPath+appInstanceId)
+	if errCode == meputil.RecordModifiedErr && isRuleMgmtJobInProgress(appInstanceId) {
+		log.Errorf(nil, "app instance has other operation in progress")
+		return meputil.ForbiddenOperation, "app instance has other operation in progress"
+	}
+	if errCode == meputil.RecordModifiedErr {
+		log.Errorf(nil, "app config(appId: %s) modified by another request", appInstanceId)
+		return meputil.RecordModifiedErr, "app config modified by another request, retry the request"
+	}
+	if errCode != 0 {
+		log.Errorf(nil, "app config(appId: %s) update on data-store failed", appInstanceId)
+		return meputil.OperateDataWithEtcdErr, "put app config to data-store failed"
+	}
+	return 0, ""
+}
+
+// Restore the AppD config when the rule change fails on the data-plane or the dns server, unless another request
+// modified the config written by the rule change meanwhile
+func revertAppDConfigForRuleMgmt(appInstanceId string, appDConfigEntry []byte, appDConfig *models.AppDConfig) {
+	appDConfigBytes, err := json.Marshal(appDConfig)
+	if err != nil {
+		log.Errorf(err, "can not marshal appDConfig info")
+		return
+	}
+	stored, revision, errCode := backend.GetRecordWithRevision(meputil.AppDConfigKeyPath + appInstanceId)
+	if errCode == 0 && string(stored) == string(appDConfigBytes) {
+		errCode = backend.PutRecordIfRevision(meputil.AppDConfigKeyPath+appInstanceId, appDConfigEntry, revision)
+	} else if errCode == 0 {
+		errCode = meputil.RecordModifiedErr
+	}
+	if errCode != 0 {
+		log.Errorf(nil, "failed to revert the app config(appId: %s) on data-store, "+
+			"this might lead to inconsistency!", appInstanceId)
+	}
+}
+
+// Domain name already used by a dns rule of any app, either stored or in an ongoing mm5 job of another app
+func isDomainNameInUse(appInstanceId string, domainName string, appDConfig *models.AppDConfig) bool {
+	for _, rule := range appDConfig.AppDNSRule {
+		if rule.DomainName == domainName {
+			return true
+		}
+	}
+	for _, path := range []string{meputil.AppDConfigKeyPath, meputil.AppDLCMJobsPath} {
+		records, errCode := backend.GetRecords(path)
+		if errCode != 0 {
+			continue
+		}
+		for appId, record := range records {
+			if appId == appInstanceId {
+				continue
+			}
+			appDInStore := &models.AppDConfig{}
+			if err := json.Unmarshal(record, appDInStore); err != nil {
+				continue
+			}
+			for _, rule := range appDInStore.AppDNSRule {
+				if rule.DomainName == domainName {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
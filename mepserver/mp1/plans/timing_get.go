@@ -0,0 +1,109 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package path implements mep server api plans
+package plans
+
+import (
+	"time"
+
+	"github.com/apache/servicecomb-service-center/pkg/log"
+
+	"mepserver/common/arch/workspace"
+	"mepserver/common/config"
+	"mepserver/common/extif/timing"
+	"mepserver/common/models"
+	meputil "mepserver/common/util"
+)
+
+// Timing capabilities as per the configuration, only the servers of the configured time source are reported
+func NewTimingCaps(timingConfig config.Timing) models.TimingCaps {
+	caps := models.TimingCaps{}
+	switch timingConfig.Source {
+	case meputil.TimeSourceNTP:
+		for _, server := range timingConfig.NtpServers {
+			caps.NtpServers = append(caps.NtpServers, models.NtpServer{
+				NtpServerAddrType:    server.AddrType,
+				NtpServerAddr:        server.Addr,
+				MinPollingInterval:   server.MinPollingInterval,
+				MaxPollingInterval:   server.MaxPollingInterval,
+				LocalPriority:        server.LocalPriority,
+				AuthenticationOption: server.AuthenticationOption,
+				AuthenticationKeyNum: server.AuthenticationKeyNum,
+			})
+		}
+	case meputil.TimeSourcePTP:
+		for _, master := range timingConfig.PtpMasters {
+			caps.PtpMasters = append(caps.PtpMasters, models.PtpMaster{
+				PtpMasterIpAddress:     master.IpAddress,
+				PtpMasterLocalPriority: master.LocalPriority,
+				DelayReqMaxRate:        master.DelayReqMaxRate,
+			})
+		}
+	}
+	return caps
+}
+
+type CurrentTimeGet struct {
+	workspace.TaskBase
+	HttpRsp    interface{} `json:"httpRsp,out"`
+	timeSource timing.TimeSource
+}
+
+func (t *CurrentTimeGet) WithTimeSource(timeSource timing.TimeSource) *CurrentTimeGet {
+	t.timeSource = timeSource
+	return t
+}
+
+// Current time of the platform, it is non traceable if the last clock status read from the time source failed
+func (t *CurrentTimeGet) OnRequest(data string) workspace.TaskCode {
+	status := meputil.TimeSourceNonTraceable
+	if t.timeSource != nil {
+		sourceStatus, err := t.timeSource.GetTimeSourceStatus()
+		if err != nil {
+			log.Errorf(err, "failed to read the clock status from the time source")
+		} else {
+			status = sourceStatus
+		}
+	}
+	now := time.Now()
+	t.HttpRsp = &models.CurrentTime{
+		Seconds:          uint32(now.Unix()),
+		NanoSeconds:      uint32(now.Nanosecond()),
+		TimeSourceStatus: status,
+	}
+	return workspace.TaskFinish
+}
+
+type TimingCapsGet struct {
+	workspace.TaskBase
+	HttpRsp    interface{} `json:"httpRsp,out"`
+	timingCaps models.TimingCaps
+}
+
+func (t *TimingCapsGet) WithTimingCaps(timingCaps models.TimingCaps) *TimingCapsGet {
+	t.timingCaps = timingCaps
+	return t
+}
+
+// Timing capabilities of the platform, time stamped at the request
+func (t *TimingCapsGet) OnRequest(data string) workspace.TaskCode {
+	now := time.Now()
+	timingCaps := t.timingCaps
+	timingCaps.TimeStamp = models.TimeStamp{Seconds: uint32(now.Unix()), Nanoseconds: uint32(now.Nanosecond())}
+	t.HttpRsp = &timingCaps
+	return workspace.TaskFinish
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plans
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/apache/servicecomb-service-center/pkg/log"
+
+	"mepserver/common"
+	"mepserver/common/arch/workspace"
+	"mepserver/common/extif/dataplane"
+	meputil "mepserver/common/util"
+)
+
+type TrafficRuleCreate struct {
+	workspace.TaskBase
+	R             *http.Request       `json:"r,in"`
+	W             http.ResponseWriter `json:"w,in"`
+	RestBody      interface{}         `json:"restBody,in"`
+	AppInstanceId string              `json:"appInstanceId,in"`
+	HttpRsp       interface{}         `json:"httpRsp,out"`
+	dataPlane     dataplane.DataPlane
+	capabilities  *dataplane.Capabilities
+}
+
+func (t *TrafficRuleCreate) WithDataPlane(dataPlane dataplane.DataPlane) *TrafficRuleCreate {
+	t.dataPlane = dataPlane
+	return t
+}
+
+func (t *TrafficRuleCreate) WithCapabilities(capabilities *dataplane.Capabilities) *TrafficRuleCreate {
+	t.capabilities = capabilities
+	return t
+}
+
+// Create a traffic rule of the app, allowed only when its AppD config enables the rule management over mp1
+func (t *TrafficRuleCreate) OnRequest(data string) workspace.TaskCode {
+	trafficInPut, ok := t.RestBody.(*dataplane.TrafficRule)
+	if !ok {
+		t.SetFirstErrorCode(meputil.ParseInfoErr, "rest-body failed")
+		return workspace.TaskFinish
+	}
+	if len(trafficInPut.State) == 0 {
+		trafficInPut.State = meputil.ActiveState
+	}
+	if err := t.capabilities.ValidateTrafficRule(trafficInPut); err != nil {
+		log.Errorf(err, "traffic rule not supported by data-plane")
+		t.SetFirstErrorCode(meputil.RequestParamErr, err.Error())
+		return workspace.TaskFinish
+	}
+
+	appDConfig, appDConfigDB, revision, errCode, errString := getAppDConfigForRuleMgmt(t.AppInstanceId)
+	if errCode != 0 {
+		t.SetFirstErrorCode(errCode, errString)
+		return workspace.TaskFinish
+	}
+	for _, rule := range appDConfig.AppTrafficRule {
+		if rule.TrafficRuleID == trafficInPut.TrafficRuleID {
+			log.Errorf(nil, "traffic rule(%s) already exists", trafficInPut.TrafficRuleID)
+			t.SetFirstErrorCode(meputil.ResourceExists, "traffic rule already exists")
+			return workspace.TaskFinish
+		}
+	}
+
+	appDConfig.AppTrafficRule = append(appDConfig.AppTrafficRule, *trafficInPut)
+	if errCode, errString = putAppDConfigForRuleMgmt(t.AppInstanceId, appDConfig, revision); errCode != 0 {
+		t.SetFirstErrorCode(errCode, errString)
+		return workspace.TaskFinish
+	}
+
+	appInfo := dataplane.ApplicationInfo{
+		ApplicationId:   t.AppInstanceId,
+		ApplicationName: appDConfig.AppName,
+	}
+	if err := applyTrafficRuleState(t.dataPlane, appInfo, trafficInPut, meputil.InactiveState); err != nil {
+		log.Errorf(err, "Traffic rule(appId: %s, trafficRuleId: %s) create fail on data-plane!",
+			t.AppInstanceId, trafficInPut.TrafficRuleID)
+		revertAppDConfigForRuleMgmt(t.AppInstanceId, appDConfigDB, appDConfig)
+		t.SetFirstErrorCode(meputil.RemoteServerErr, "failed to apply configuration on data-plane")
+		return workspace.TaskFinish
+	}
+
+	common.SaveAppDConfigVersion(t.AppInstanceId, "", appDConfig)
+
+	location := fmt.Sprintf("%s/applications/%s/traffic_rules/%s", meputil.MecAppSupportPath, t.AppInstanceId,
+		trafficInPut.TrafficRuleID)
+	t.W.Header().Set("Location", location)
+	if ruleBytes, err := json.Marshal(trafficInPut); err == nil {
+		t.W.Header().Set("ETag", meputil.GenerateStrongETag(ruleBytes))
+	}
+	t.HttpRsp = trafficInPut
+	return workspace.TaskFinish
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plans
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/apache/servicecomb-service-center/pkg/log"
+
+	"mepserver/common"
+	"mepserver/common/arch/workspace"
+	"mepserver/common/extif/dataplane"
+	meputil "mepserver/common/util"
+)
+
+type TrafficRuleDelete struct {
+	workspace.TaskBase
+	R             *http.Request `json:"r,in"`
+	AppInstanceId string        `json:"appInstanceId,in"`
+	TrafficRuleId string        `json:"trafficRuleId,in"`
+	HttpRsp       interface{}   `json:"httpRsp,out"`
+	dataPlane     dataplane.DataPlane
+}
+
+func (t *TrafficRuleDelete) WithDataPlane(dataPlane dataplane.DataPlane) *TrafficRuleDelete {
+	t.dataPlane = dataPlane
+	return t
+}
+
+// Delete a traffic rule of the app, allowed only when its AppD config enables the rule management over mp1
+func (t *TrafficRuleDelete) OnRequest(data string) workspace.TaskCode {
+	appDConfig, appDConfigDB, revision, errCode, errString := getAppDConfigForRuleMgmt(t.AppInstanceId)
+	if errCode != 0 {
+		t.SetFirstErrorCode(errCode, errString)
+		return workspace.TaskFinish
+	}
+
+	ruleIndex := -1
+	for i, rule := range appDConfig.AppTrafficRule {
+		if rule.TrafficRuleID == t.TrafficRuleId {
+			ruleIndex = i
+			break
+		}
+	}
+	if ruleIndex == -1 {
+		log.Error("Requested traffic rule id doesn't exists.", nil)
+		t.SetFirstErrorCode(meputil.SubscriptionNotFound, "traffic rule does not exist")
+		return workspace.TaskFinish
+	}
+	trafficRule := appDConfig.AppTrafficRule[ruleIndex]
+
+	dataStoreEntryBytes, err := json.Marshal(&trafficRule)
+	if err != nil {
+		log.Errorf(err, "Traffic rule parse failed")
+		t.SetFirstErrorCode(meputil.ParseInfoErr, "internal error on data parsing")
+		return workspace.TaskFinish
+	}
+	// Check for E-Tags precondition. More details could be found here: https://tools.ietf.org/html/rfc7232#section-2.3
+	ifMatchTag := t.R.Header.Get("If-Match")
+	if len(ifMatchTag) != 0 && ifMatchTag != meputil.GenerateStrongETag(dataStoreEntryBytes) {
+		log.Warn("E-Tag miss-match.")
+		t.SetFirstErrorCode(meputil.EtagMissMatchErr, "e-tag miss-match")
+		return workspace.TaskFinish
+	}
+
+	appDConfig.AppTrafficRule = append(appDConfig.AppTrafficRule[:ruleIndex],
+		appDConfig.AppTrafficRule[ruleIndex+1:]...)
+	if errCode, errString = putAppDConfigForRuleMgmt(t.AppInstanceId, appDConfig, revision); errCode != 0 {
+		t.SetFirstErrorCode(errCode, errString)
+		return workspace.TaskFinish
+	}
+
+	// Rules without state are programmed as active, inactive rules are not on the data-plane
+	if trafficRule.State != meputil.InactiveState {
+		appInfo := dataplane.ApplicationInfo{
+			ApplicationId:   t.AppInstanceId,
+			ApplicationName: appDConfig.AppName,
+		}
+		trafficRule.State = meputil.InactiveState
+		if err = applyTrafficRuleState(t.dataPlane, appInfo, &trafficRule, meputil.ActiveState); err != nil {
+			log.Errorf(err, "Traffic rule(appId: %s, trafficRuleId: %s) delete fail on data-plane!",
+				t.AppInstanceId, t.TrafficRuleId)
+			revertAppDConfigForRuleMgmt(t.AppInstanceId, appDConfigDB, appDConfig)
+			t.SetFirstErrorCode(meputil.RemoteServerErr, "failed to apply configuration on data-plane")
+			return workspace.TaskFinish
+		}
+	}
+	common.SaveAppDConfigVersion(t.AppInstanceId, "", appDConfig)
+	t.HttpRsp = ""
+	return workspace.TaskFinish
+}
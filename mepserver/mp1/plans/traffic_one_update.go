@@ -29,6 +29,7 @@ import (
 
 	"github.com/apache/servicecomb-service-center/pkg/log"
 
+	"mepserver/common"
 	"mepserver/common/arch/workspace"
 )
 
@@ -41,6 +42,7 @@ type TrafficRuleUpdate struct {
 	TrafficRuleId string              `json:"trafficRuleId,in"`
 	HttpRsp       interface{}         `json:"httpRsp,out"`
 	dataPlane     dataplane.DataPlane
+	capabilities  *dataplane.Capabilities
 }
 
 func (t *TrafficRuleUpdate) WithDataPlane(dataPlane dataplane.DataPlane) *TrafficRuleUpdate {
@@ -48,6 +50,11 @@ func (t *TrafficRuleUpdate) WithDataPlane(dataPlane dataplane.DataPlane) *Traffi
 	return t
 }
 
+func (t *TrafficRuleUpdate) WithCapabilities(capabilities *dataplane.Capabilities) *TrafficRuleUpdate {
+	t.capabilities = capabilities
+	return t
+}
+
 func (t *TrafficRuleUpdate) OnRequest(data string) workspace.TaskCode {
 
 	trafficInPut, ok := t.RestBody.(*dataplane.TrafficRule)
@@ -62,6 +69,21 @@ func (t *TrafficRuleUpdate) OnRequest(data string) workspace.TaskCode {
 		return workspace.TaskFinish
 	}
 
+	if len(trafficInPut.State) == 0 {
+		trafficInPut.State = meputil.ActiveState
+	}
+	if trafficInPut.State != meputil.ActiveState && trafficInPut.State != meputil.InactiveState {
+		log.Errorf(nil, "invalid traffic state input on update request")
+		t.SetFirstErrorCode(meputil.ParseInfoErr, "invalid traffic state input")
+		return workspace.TaskFinish
+	}
+
+	if err := t.capabilities.ValidateTrafficRule(trafficInPut); err != nil {
+		log.Errorf(err, "traffic rule not supported by data-plane")
+		t.SetFirstErrorCode(meputil.RequestParamErr, err.Error())
+		return workspace.TaskFinish
+	}
+
 	appDConfigDB, errCode := backend.GetRecord(meputil.AppDConfigKeyPath + t.AppInstanceId)
 	if errCode != 0 {
 		log.Errorf(nil, "Update traffic rules failed")
@@ -119,6 +141,11 @@ func (t *TrafficRuleUpdate) OnRequest(data string) workspace.TaskCode {
 		return workspace.TaskFinish
 	}
 
+	if len(trafficRule.State) == 0 {
+		// Rules without state are programmed as active
+		trafficRule.State = meputil.ActiveState
+	}
+
 	errCode, errString := t.applyTrafficRule(trafficRule, appDConfig, ruleIndex, appDConfigDB)
 	if errCode != 0 {
 		t.SetFirstErrorCode(workspace.ErrCode(errCode), errString)
@@ -152,18 +179,7 @@ func (t *TrafficRuleUpdate) applyTrafficRule(trafficRule *dataplane.TrafficRule,
 		ApplicationId:   t.AppInstanceId,
 		ApplicationName: appDConfig.AppName,
 	}
-	if trafficInPut.State != trafficRule.State {
-		if trafficInPut.State == "ACTIVE" {
-			err = t.dataPlane.AddTrafficRule(appInfo, t.TrafficRuleId, trafficInPut.FilterType,
-				trafficInPut.Action, trafficInPut.Priority, trafficInPut.TrafficFilter)
-		} else {
-			err = t.dataPlane.DeleteTrafficRule(appInfo, t.TrafficRuleId)
-		}
-	} else if trafficInPut.State == "ACTIVE" {
-		err = t.dataPlane.SetTrafficRule(appInfo, t.TrafficRuleId, trafficInPut.FilterType,
-			trafficInPut.Action, trafficInPut.Priority, trafficInPut.TrafficFilter)
-	}
-
+	err = applyTrafficRuleState(t.dataPlane, appInfo, trafficInPut, trafficRule.State)
 	if err != nil {
 		log.Errorf(err, "Traffic rule(appId: %s, dnsRuleId: %s) update fail on server: %s!",
 			t.AppInstanceId, t.TrafficRuleId, err.Error())
@@ -177,8 +193,25 @@ func (t *TrafficRuleUpdate) applyTrafficRule(trafficRule *dataplane.TrafficRule,
 		}
 		return 0, ""
 	}
+	common.SaveAppDConfigVersion(t.AppInstanceId, "", &appDConfig)
 
 	t.W.Header().Set("ETag", meputil.GenerateStrongETag(updateJSON))
 	t.HttpRsp = trafficInPut
 	return 0, ""
 }
+
+// Apply the traffic rule on the data-plane as per its change from the old state, inactive rules are not programmed
+func applyTrafficRuleState(dataPlane dataplane.DataPlane, appInfo dataplane.ApplicationInfo,
+	trafficRule *dataplane.TrafficRule, oldState string) error {
+	if trafficRule.State != oldState {
+		if trafficRule.State == meputil.ActiveState {
+			return dataPlane.AddTrafficRule(appInfo, trafficRule.TrafficRuleID, trafficRule.FilterType,
+				trafficRule.Action, trafficRule.Priority, trafficRule.TrafficFilter)
+		}
+		return dataPlane.DeleteTrafficRule(appInfo, trafficRule.TrafficRuleID)
+	} else if trafficRule.State == meputil.ActiveState {
+		return dataPlane.SetTrafficRule(appInfo, trafficRule.TrafficRuleID, trafficRule.FilterType,
+			trafficRule.Action, trafficRule.Priority, trafficRule.TrafficFilter)
+	}
+	return nil
+}
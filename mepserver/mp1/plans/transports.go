@@ -0,0 +1,130 @@
+/*
+ * Copyright 2020 Huawei Technologies Co., Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package path implements mep server api plans
+package plans
+
+import (
+	"github.com/apache/servicecomb-service-center/pkg/log"
+
+	"mepserver/common/arch/workspace"
+	"mepserver/common/config"
+	"mepserver/common/models"
+	meputil "mepserver/common/util"
+)
+
+// Id of the transport offered when no transport is configured
+const DefaultTransportId = "rest-http"
+
+// Transport offered when no transport is configured, REST over HTTP as used by the mp1 apis themselves
+var defaultTransport = config.Transport{
+	ID:          DefaultTransportId,
+	Name:        "REST",
+	Description: "REST over HTTP",
+	Type:        "REST_HTTP",
+	Protocol:    "HTTP",
+	Version:     "2.0",
+}
+
+// Transport catalogue of the platform as per the configuration, the endpoint is specific to each service and is
+// not part of the catalogue. Only the default transport is in the catalogue if none is configured.
+func NewTransportCatalogue(transports []config.Transport) []models.TransportInfo {
+	if len(transports) == 0 {
+		transports = []config.Transport{defaultTransport}
+	}
+	catalogue := make([]models.TransportInfo, 0, len(transports))
+	for _, transport := range transports {
+		catalogue = append(catalogue, models.TransportInfo{
+			ID:          transport.ID,
+			Name:        transport.Name,
+			Description: transport.Description,
+			TransType:   models.TransportTypes(transport.Type),
+			Protocol:    transport.Protocol,
+			Version:     transport.Version,
+			Security: models.SecurityInfo{OAuth2Info: models.SecurityInfoOAuth2Info{
+				GrantTypes:    transport.Security.GrantTypes,
+				TokenEndpoint: transport.Security.TokenEndpoint,
+			}},
+		})
+	}
+	return catalogue
+}
+
+type TransportsGet struct {
+	workspace.TaskBase
+	HttpRsp    interface{} `json:"httpRsp,out"`
+	transports []models.TransportInfo
+}
+
+func (t *TransportsGet) WithTransports(transports []models.TransportInfo) *TransportsGet {
+	t.transports = transports
+	return t
+}
+
+// List the transports supported by the platform
+func (t *TransportsGet) OnRequest(data string) workspace.TaskCode {
+	transports := t.transports
+	if transports == nil {
+		transports = []models.TransportInfo{}
+	}
+	t.HttpRsp = transports
+	return workspace.TaskFinish
+}
+
+type SelectTransport struct {
+	workspace.TaskBase
+	RestBody   interface{} `json:"restBody,in"`
+	transports []models.TransportInfo
+}
+
+func (t *SelectTransport) WithTransports(transports []models.TransportInfo) *SelectTransport {
+	t.transports = transports
+	return t
+}
+
+// Fill the transport info of the service from the catalogue, a transport id not in the catalogue is rejected
+func (t *SelectTransport) OnRequest(data string) workspace.TaskCode {
+	serviceInfo, ok := t.RestBody.(*models.ServiceInfo)
+	if !ok {
+		log.Error(meputil.ErrorRequestBodyMessage, nil)
+		t.SetFirstErrorCode(meputil.ParseInfoErr, meputil.ErrorRequestBodyMessage)
+		return workspace.TaskFinish
+	}
+	if len(serviceInfo.TransportID) == 0 {
+		return workspace.TaskFinish
+	}
+	if len(serviceInfo.TransportInfo.ID) != 0 && serviceInfo.TransportInfo.ID != serviceInfo.TransportID {
+		log.Errorf(nil, "transport id(%s) mismatch with the transport info", serviceInfo.TransportID)
+		t.SetFirstErrorCode(meputil.RequestParamErr, "transport id mismatch with the transport info")
+		return workspace.TaskFinish
+	}
+
+	for _, transport := range t.transports {
+		if transport.ID != serviceInfo.TransportID {
+			continue
+		}
+		// Endpoint and the implementation specific info are of the service
+		transport.Endpoint = serviceInfo.TransportInfo.Endpoint
+		if serviceInfo.TransportInfo.ImplSpecificInfo != nil {
+			transport.ImplSpecificInfo = serviceInfo.TransportInfo.ImplSpecificInfo
+		}
+		serviceInfo.TransportInfo = transport
+		return workspace.TaskFinish
+	}
+	log.Errorf(nil, "transport(%s) is not supported by the platform", serviceInfo.TransportID)
+	t.SetFirstErrorCode(meputil.RequestParamErr, "transport not found")
+	return workspace.TaskFinish
+}